@@ -2,56 +2,239 @@ package main
 
 import (
 	"CommandPost/goInternal/pkg/db"
+	"CommandPost/goInternal/pkg/filesync"
 	"CommandPost/goInternal/pkg/generator"
 	pkg "CommandPost/goInternal/pkg/inAppExec"
+	"CommandPost/goInternal/pkg/jwt"
 	"CommandPost/goInternal/pkg/oauth"
+	"CommandPost/goInternal/pkg/secrets"
+	"CommandPost/goInternal/pkg/spec"
+	"CommandPost/goInternal/pkg/variables"
+	"CommandPost/goInternal/pkg/workspace"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"net/url"
+	"net/http"
 	"os"
-
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 
 	_ "modernc.org/sqlite"
 )
 
+var errNoWorkspace = errors.New("no workspace is open")
+
 // App struct
 type App struct {
-	ctx    context.Context
-	db     *sql.DB
-	dbChan chan db.DbQuery
+	ctx     context.Context
+	dataDir string
+	tokenMu sync.Mutex
+
+	wsMu    sync.RWMutex
+	ws      *openWorkspace
+	closing sync.WaitGroup
+
+	oauthMu     sync.Mutex
+	oauthCancel context.CancelFunc
+	oauthFlow   int
+
+	specMu      sync.Mutex
+	specHeaders http.Header
 }
 
 // NewApp creates a new App application struct
-func NewApp() *App {
-	return &App{}
+func NewApp(dataDir string) *App {
+	return &App{dataDir: dataDir}
 }
 
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
-	database, err := sql.Open("sqlite", "./commandpost.db")
-	if err != nil {
+	if err := a.switchWorkspace(workspace.LoadCurrent(a.dataDir)); err != nil {
 		log.Fatal(err)
 	}
-	a.db = database
+}
+
+func (a *App) shutdown(ctx context.Context) {
+	a.wsMu.Lock()
+	ws := a.ws
+	a.ws = nil
+	a.wsMu.Unlock()
+	if ws != nil {
+		ws.close()
+	}
+	a.closing.Wait()
+}
+
+// openWorkspace is an open workspace database with its writer and vault.
+// Bindings hold it from use to release, so a workspace switch never closes
+// the database under a call that is still using it.
+type openWorkspace struct {
+	name    string
+	db      *sql.DB
+	dbChan  chan db.DbQuery
+	dbDone  chan struct{}
+	vault   *secrets.Vault
+	keyFile string
+	users   sync.WaitGroup
+}
+
+// use returns the current workspace, which the caller must release. It
+// fails when no workspace is open, before startup or after shutdown.
+func (a *App) use() (*openWorkspace, error) {
+	a.wsMu.RLock()
+	defer a.wsMu.RUnlock()
+	if a.ws == nil {
+		return nil, errNoWorkspace
+	}
+	a.ws.users.Add(1)
+	return a.ws, nil
+}
+
+func (ws *openWorkspace) release() {
+	ws.users.Done()
+}
+
+// close waits for the calls using the workspace, then lets the writer drain
+// its queue before the database closes.
+func (ws *openWorkspace) close() {
+	ws.users.Wait()
+	close(ws.dbChan)
+	<-ws.dbDone
+	ws.db.Close()
+}
+
+// switchWorkspace opens (creating if needed) the named workspace database
+// and swaps it in for the current one, which closes once it is unused.
+func (a *App) switchWorkspace(name string) error {
+	paths, err := workspace.Resolve(a.dataDir, name)
+	if err != nil {
+		return err
+	}
+	database, err := sql.Open("sqlite", paths.DB)
+	if err != nil {
+		return err
+	}
+
+	database.Exec("PRAGMA journal_mode=WAL;")
+	database.Exec("PRAGMA busy_timeout=5000;")
+	database.SetMaxOpenConns(1)
 
-	a.db.Exec("PRAGMA journal_mode=WAL;")
-	a.db.Exec("PRAGMA busy_timeout=5000;")
-	a.db.SetMaxOpenConns(1)
+	if err := db.CreateCollectionsTable(database); err != nil {
+		database.Close()
+		return fmt.Errorf("failed to open workspace %s: %w", name, err)
+	}
+	db.CreateHistoryTable(database)
+	db.CreateEnvironmentsTable(database)
+	db.CreateVaultTable(database)
+	db.CreateSyncStateTable(database)
+
+	ws := &openWorkspace{
+		name:    name,
+		db:      database,
+		dbChan:  make(chan db.DbQuery, 100),
+		dbDone:  make(chan struct{}),
+		vault:   secrets.NewVault(),
+		keyFile: paths.KeyFile,
+	}
+	go func() {
+		db.DbWorker(ws.db, ws.dbChan)
+		close(ws.dbDone)
+	}()
+	if err := ws.initVault(); err != nil {
+		log.Println("Failed to unlock vault:", err)
+	}
 
-	a.dbChan = make(chan db.DbQuery, 100)
-	go db.DbWorker(a.db, a.dbChan)
-	db.CreateCollectionsTable(a.db)
-	db.CreateHistoryTable(a.db)
-	db.CreateEnvironmentsTable(a.db)
+	a.wsMu.Lock()
+	old := a.ws
+	a.ws = ws
+	a.wsMu.Unlock()
+	if old != nil {
+		a.closing.Add(1)
+		go func() {
+			old.close()
+			a.closing.Done()
+		}()
+	}
+	return workspace.SaveCurrent(a.dataDir, name)
+}
+
+// initVault sets up key-file encryption on first run and unlocks the vault
+// straight away unless it is protected by a passphrase.
+func (ws *openWorkspace) initVault() error {
+	cfg, ok, err := db.LoadVaultConfig(ws.db)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		cfg, c, err := secrets.NewVaultConfig(secrets.ModeKeyFile, ws.keyFile, "")
+		if err != nil {
+			return err
+		}
+		ws.vault.Unlock(c)
+		if err := db.ReencryptEnvironments(ws.db, ws.dbChan, ws.vault, ws.vault, cfg); err != nil {
+			ws.vault.Lock()
+			return err
+		}
+		return nil
+	}
+	if cfg.Mode == secrets.ModeKeyFile {
+		return ws.vault.UnlockWith(cfg, ws.keyFile, "")
+	}
+	return nil
+}
+
+func (a *App) ListWorkspaces() ([]string, error) {
+	return workspace.List(a.dataDir)
+}
+
+func (a *App) GetCurrentWorkspace() string {
+	ws, err := a.use()
+	if err != nil {
+		return ""
+	}
+	defer ws.release()
+	return ws.name
+}
+
+func (a *App) GetDataDirectory() string {
+	return a.dataDir
+}
+
+func (a *App) CreateWorkspace(name string) error {
+	if err := workspace.ValidateName(name); err != nil {
+		return err
+	}
+	if workspace.Exists(a.dataDir, name) {
+		return fmt.Errorf("workspace %s already exists", name)
+	}
+	return a.switchWorkspace(name)
+}
+
+func (a *App) SwitchWorkspace(name string) error {
+	if name == a.GetCurrentWorkspace() {
+		return nil
+	}
+	if !workspace.Exists(a.dataDir, name) {
+		return fmt.Errorf("workspace %s does not exist", name)
+	}
+	return a.switchWorkspace(name)
+}
+
+func (a *App) SyncWorkspace(dir string) (filesync.Report, error) {
+	ws, err := a.use()
+	if err != nil {
+		return filesync.Report{}, err
+	}
+	defer ws.release()
+	return filesync.Sync(ws.db, ws.dbChan, ws.vault, dir)
 }
 
 func (a *App) SelectDirectory() (string, error) {
@@ -74,31 +257,36 @@ func (a *App) SaveFileDialog(title string, defaultFilename string, filters []run
 	})
 }
 
-func (a *App) ValidateSpec(path string) (bool, error) {
-	loader := openapi3.NewLoader()
-	var err error
-	if isURL(path) {
-		u, _ := url.Parse(path)
-		_, err = loader.LoadFromURI(u)
-	} else {
-		_, err = loader.LoadFromFile(path)
-	}
+// SetSpecHeaders sets the headers ("Name: value") sent when fetching specs
+// from a URL, for specs that need authentication.
+func (a *App) SetSpecHeaders(headers []string) error {
+	parsed, err := spec.ParseHeaders(headers)
 	if err != nil {
+		return err
+	}
+	a.specMu.Lock()
+	a.specHeaders = parsed
+	a.specMu.Unlock()
+	return nil
+}
+
+func (a *App) specLoader() *spec.Loader {
+	a.specMu.Lock()
+	defer a.specMu.Unlock()
+	loader := spec.NewLoader()
+	loader.Headers = a.specHeaders
+	return loader
+}
+
+func (a *App) ValidateSpec(path string) (bool, error) {
+	if _, err := a.specLoader().Load(path); err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
 func (a *App) GetAuthInfo(path string) ([]generator.AuthScheme, error) {
-	loader := openapi3.NewLoader()
-	var doc *openapi3.T
-	var err error
-	if isURL(path) {
-		u, _ := url.Parse(path)
-		doc, err = loader.LoadFromURI(u)
-	} else {
-		doc, err = loader.LoadFromFile(path)
-	}
+	doc, err := a.specLoader().Load(path)
 	if err != nil {
 		return nil, err
 	}
@@ -111,8 +299,16 @@ func (a *App) GetAuthInfo(path string) ([]generator.AuthScheme, error) {
 	return schemes, nil
 }
 
-func (a *App) Generate(specPath, outputDir, moduleName string) error {
+func (a *App) Generate(specPath, outputDir, moduleName, configPath string) error {
 	gen := generator.NewGenerator()
+	gen.Loader = a.specLoader()
+	if configPath != "" {
+		cfg, err := generator.LoadConfig(configPath)
+		if err != nil {
+			return err
+		}
+		gen.Config = cfg
+	}
 
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
@@ -121,13 +317,8 @@ func (a *App) Generate(specPath, outputDir, moduleName string) error {
 	return gen.Generate(specPath, outputDir, moduleName)
 }
 
-func isURL(s string) bool {
-	u, err := url.Parse(s)
-	return err == nil && u.Scheme != "" && u.Host != ""
-}
-
 func (a *App) ParseSpecDetails(path string) (pkg.SpecDetails, error) {
-	specs, err := pkg.ParseSpec(path)
+	specs, err := pkg.ParseSpec(a.specLoader(), path)
 	if err != nil {
 		return pkg.SpecDetails{}, err
 	}
@@ -135,6 +326,26 @@ func (a *App) ParseSpecDetails(path string) (pkg.SpecDetails, error) {
 }
 
 func (a *App) ExecuteRequest(req pkg.RequestData) (pkg.ResponseData, error) {
+	ws, err := a.use()
+	if err != nil {
+		return pkg.ResponseData{}, err
+	}
+	defer ws.release()
+	layers, err := db.VariableLayers(ws.db, ws.vault, req.Environment, req.Collection)
+	if err != nil {
+		return pkg.ResponseData{}, err
+	}
+	layers = append([]variables.Layer{{Source: "request", Values: req.Variables}}, layers...)
+	if req, err = pkg.ApplyVariables(req, variables.Resolve(layers...)); err != nil {
+		return pkg.ResponseData{}, err
+	}
+
+	if req.Environment != "" {
+		if req, err = a.authorizeRequest(ws, req); err != nil {
+			return pkg.ResponseData{}, err
+		}
+	}
+
 	response, err := pkg.ExecuteHTTP(req)
 	if err != nil {
 		return pkg.ResponseData{}, err
@@ -143,38 +354,87 @@ func (a *App) ExecuteRequest(req pkg.RequestData) (pkg.ResponseData, error) {
 }
 
 func (a *App) ImportCollections(path string) (*db.PostmanCollection, error) {
-	return db.ImportCollections(a.dbChan, path)
+	ws, err := a.use()
+	if err != nil {
+		return nil, err
+	}
+	defer ws.release()
+	return db.ImportCollections(ws.dbChan, path)
 }
 func (a *App) ExportCollection(name string, path string) error {
-	return db.ExportCollection(a.db, name, path)
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	return db.ExportCollection(ws.db, name, path)
 }
 
 func (a *App) SaveCollection(name string, requests []pkg.RequestData) error {
-	return db.SaveCollection(a.dbChan, name, requests)
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	return db.SaveCollection(ws.dbChan, name, requests)
 }
 
 func (a *App) LoadCollection() ([]db.Collection, error) {
-	collections, err := db.LoadCollections(a.db)
+	ws, err := a.use()
+	if err != nil {
+		return nil, err
+	}
+	defer ws.release()
+	collections, err := db.LoadCollections(ws.db)
 	if err != nil {
 		return nil, err
 	}
 	return collections, nil
 }
 
+func (a *App) SaveCollectionVariables(name string, vars map[string]string) error {
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	return db.SaveCollectionVariables(ws.dbChan, name, vars)
+}
+
 func (a *App) DeleteCollection(name string) error {
-	return db.DeleteCollection(a.dbChan, name)
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	return db.DeleteCollection(ws.dbChan, name)
 }
 
 func (a *App) SaveHistory(req pkg.RequestData, res pkg.ResponseData) error {
-	return db.SaveHistory(a.dbChan, req, res)
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	return db.SaveHistory(ws.dbChan, req, res)
 }
 
 func (a *App) DeleteHistoryItem(id int) error {
-	return db.DeleteHistoryItem(a.dbChan, id)
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	return db.DeleteHistoryItem(ws.dbChan, id)
 }
 
 func (a *App) LoadHistory() ([]db.HistoryRecord, error) {
-	history, err := db.LoadHistory(a.db)
+	ws, err := a.use()
+	if err != nil {
+		return nil, err
+	}
+	defer ws.release()
+	history, err := db.LoadHistory(ws.db)
 	if err != nil {
 		return nil, err
 	}
@@ -182,7 +442,12 @@ func (a *App) LoadHistory() ([]db.HistoryRecord, error) {
 }
 
 func (a *App) GetEnvironments() ([]db.Environment, error) {
-	environments, err := db.GetEnvironments(a.db)
+	ws, err := a.use()
+	if err != nil {
+		return nil, err
+	}
+	defer ws.release()
+	environments, err := db.GetEnvironments(ws.db, ws.vault)
 	if err != nil {
 		return nil, err
 	}
@@ -190,11 +455,185 @@ func (a *App) GetEnvironments() ([]db.Environment, error) {
 }
 
 func (a *App) SaveEnvironment(env db.Environment) error {
-	return db.SaveEnvironment(a.dbChan, env)
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	return db.SaveEnvironment(ws.dbChan, ws.vault, env)
+}
+
+// ResolveVariables lists the effective variables for envName (and
+// optionally a collection) together with the layer each value comes from.
+func (a *App) ResolveVariables(envName string, collectionName string) ([]variables.Resolved, error) {
+	ws, err := a.use()
+	if err != nil {
+		return nil, err
+	}
+	defer ws.release()
+	layers, err := db.VariableLayers(ws.db, ws.vault, envName, collectionName)
+	if err != nil {
+		return nil, err
+	}
+	return variables.Resolve(layers...), nil
+}
+
+// ImportEnvironment merges an environment file (Postman, .env or CommandPost
+// JSON; format "" detects it) into the environment of the same name.
+func (a *App) ImportEnvironment(path string, format string, name string) (db.Environment, error) {
+	ws, err := a.use()
+	if err != nil {
+		return db.Environment{}, err
+	}
+	defer ws.release()
+	return db.ImportEnvironment(ws.db, ws.dbChan, ws.vault, path, format, name)
+}
+
+// ExportEnvironment writes an environment to path. secretMode is one of
+// "exclude", "mask" or "include".
+func (a *App) ExportEnvironment(name string, path string, format string, secretMode string) error {
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	return db.ExportEnvironment(ws.db, ws.vault, name, path, format, secretMode)
+}
+
+// DecodeJWT decodes a token without verifying it.
+func (a *App) DecodeJWT(raw string) (jwt.Info, error) {
+	return jwt.Inspect(raw)
+}
+
+// FindJWTs decodes every token that appears in text, such as a request or
+// response.
+func (a *App) FindJWTs(text string) []jwt.Info {
+	return jwt.Find(text)
+}
+
+// VerifyJWT checks raw against a shared secret, a PEM key or a JWKS URL
+// (keyType "secret", "pem" or "jwks").
+func (a *App) VerifyJWT(raw string, keyType string, key string) jwt.VerifyResult {
+	return jwt.VerifyWith(a.ctx, raw, keyType, key)
+}
+
+// MintJWT signs a test token with the key held in the keyVariable of
+// envName. When saveAs is set the token is stored as that environment
+// variable, so requests can use it as {{saveAs}}.
+func (a *App) MintJWT(envName string, keyVariable string, opts jwt.MintOptions, saveAs string) (string, error) {
+	ws, err := a.use()
+	if err != nil {
+		return "", err
+	}
+	defer ws.release()
+	layers, err := db.VariableLayers(ws.db, ws.vault, envName, "")
+	if err != nil {
+		return "", err
+	}
+	key := variables.Values(variables.Resolve(layers...))[keyVariable]
+	if key == "" {
+		if ws.vault.Locked() {
+			return "", secrets.ErrLocked
+		}
+		return "", fmt.Errorf("variable %s is empty or not set in environment %s", keyVariable, envName)
+	}
+	token, err := jwt.Mint(opts, key)
+	if err != nil {
+		return "", err
+	}
+	if saveAs == "" {
+		return token, nil
+	}
+
+	env, err := a.unlockedEnvironment(ws, envName)
+	if err != nil {
+		return "", err
+	}
+	if env.Variables == nil {
+		env.Variables = map[string]string{}
+	}
+	env.Variables[saveAs] = token
+	if err := db.SaveEnvironment(ws.dbChan, ws.vault, env); err != nil {
+		return "", err
+	}
+	return token, nil
 }
 
 func (a *App) DeleteEnvironment(name string) error {
-	return db.DeleteEnvironment(a.dbChan, name)
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	return db.DeleteEnvironment(ws.dbChan, name)
+}
+
+func (a *App) GetVaultStatus() (secrets.VaultStatus, error) {
+	ws, err := a.use()
+	if err != nil {
+		return secrets.VaultStatus{}, err
+	}
+	defer ws.release()
+	cfg, _, err := db.LoadVaultConfig(ws.db)
+	if err != nil {
+		return secrets.VaultStatus{}, err
+	}
+	return secrets.VaultStatus{Mode: cfg.Mode, Locked: ws.vault.Locked()}, nil
+}
+
+func (a *App) UnlockVault(passphrase string) error {
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	cfg, ok, err := db.LoadVaultConfig(ws.db)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("vault is not initialised")
+	}
+	return ws.vault.UnlockWith(cfg, ws.keyFile, passphrase)
+}
+
+func (a *App) LockVault() error {
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	ws.vault.Lock()
+	return nil
+}
+
+// SetVaultPassphrase re-encrypts every environment under a key derived from
+// passphrase. An empty passphrase switches back to the local key file.
+func (a *App) SetVaultPassphrase(passphrase string) error {
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	if ws.vault.Locked() {
+		return secrets.ErrLocked
+	}
+	mode := secrets.ModePassphrase
+	if passphrase == "" {
+		mode = secrets.ModeKeyFile
+	}
+	cfg, c, err := secrets.NewVaultConfig(mode, ws.keyFile, passphrase)
+	if err != nil {
+		return err
+	}
+
+	next := secrets.NewVault()
+	next.Unlock(c)
+	if err := db.ReencryptEnvironments(ws.db, ws.dbChan, ws.vault, next, cfg); err != nil {
+		return fmt.Errorf("failed to re-encrypt environments: %w", err)
+	}
+	ws.vault.Unlock(c)
+	return nil
 }
 
 func (a *App) UploadFile(path string) ([]byte, error) {
@@ -202,57 +641,338 @@ func (a *App) UploadFile(path string) ([]byte, error) {
 }
 
 func (a *App) DeleteHistory() error {
-	return db.DeleteHistory(a.dbChan)
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	return db.DeleteHistory(ws.dbChan)
 }
 
 func (a *App) ExportHistory(path string) error {
-	return db.ExportHistory(a.db, path)
+	ws, err := a.use()
+	if err != nil {
+		return err
+	}
+	defer ws.release()
+	return db.ExportHistory(ws.db, path)
 }
 
-func (a *App) PerformOAuthFlow(env db.Environment) (db.Environment, error) {
-	// Use environment variables or direct config
-	scope := env.Variables["scope"]
-	if scope == "" {
-		scope = "openid profile email" // Default scopes
+func (a *App) PerformOAuthFlow(env db.Environment, profile string) (db.Environment, error) {
+	ws, err := a.use()
+	if err != nil {
+		return db.Environment{}, err
+	}
+	defer ws.release()
+	view, err := env.WithProfile(profile)
+	if err != nil {
+		return env, err
+	}
+	cfg := oauthConfig(view)
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.oauthMu.Lock()
+	if a.oauthCancel != nil {
+		a.oauthCancel()
 	}
+	a.oauthCancel = cancel
+	a.oauthFlow++
+	flow := a.oauthFlow
+	a.oauthMu.Unlock()
+	defer func() {
+		a.oauthMu.Lock()
+		if a.oauthFlow == flow {
+			a.oauthCancel = nil
+		}
+		a.oauthMu.Unlock()
+		cancel()
+	}()
 
-	accessToken, refreshToken, expiresIn, err := oauth.PerformOAuthFlow(
-		env.ClientID,
-		env.TokenURL,
-		env.AuthURL,
-		env.RedirectURI,
-		scope,
+	token, err := oauth.PerformOAuthFlow(
+		ctx,
+		cfg,
 		func(url string) {
 			runtime.BrowserOpenURL(a.ctx, url)
 		},
+		func(device oauth.DeviceAuthorization) {
+			runtime.EventsEmit(a.ctx, "oauth:device-code", device)
+			if device.VerificationURIComplete != "" {
+				runtime.BrowserOpenURL(a.ctx, device.VerificationURIComplete)
+			} else if device.VerificationURI != "" {
+				runtime.BrowserOpenURL(a.ctx, device.VerificationURI)
+			}
+		},
 	)
-
 	if err != nil {
 		return env, err
 	}
 
+	if token.IDToken != "" && cfg.Issuer != "" {
+		metadata, err := oauth.Discover(ctx, cfg.Issuer)
+		if err != nil {
+			return env, err
+		}
+		if _, err := oauth.ValidateIDToken(ctx, metadata, cfg.ClientID, token.Nonce, token.IDToken); err != nil {
+			return env, fmt.Errorf("id_token rejected: %w", err)
+		}
+	}
+
 	updatedEnv := env
-	updatedEnv.AccessToken = accessToken
-	updatedEnv.RefreshToken = refreshToken
+	updatedEnv.SetProfile(profile, withToken(view, token))
 
-	if expiresIn > 0 {
-		expiry := time.Now().Add(time.Duration(expiresIn) * time.Second)
-		updatedEnv.ExpiresAt = expiry.Format(time.RFC3339)
+	if err := db.SaveEnvironment(ws.dbChan, ws.vault, updatedEnv); err != nil {
+		return updatedEnv, fmt.Errorf("failed to save tokens: %w", err)
 	}
 
-	if updatedEnv.OAuth2Config != "" {
+	return updatedEnv, nil
+}
+
+// CancelOAuthFlow abandons the login started by PerformOAuthFlow, which
+// then returns oauth.ErrFlowCanceled.
+func (a *App) CancelOAuthFlow() {
+	a.oauthMu.Lock()
+	defer a.oauthMu.Unlock()
+	if a.oauthCancel != nil {
+		a.oauthCancel()
+		a.oauthCancel = nil
+	}
+}
+
+// DiscoverOIDC fetches the OpenID Connect discovery document for issuer so
+// the endpoints can be filled in.
+func (a *App) DiscoverOIDC(issuer string) (oauth.ProviderMetadata, error) {
+	return oauth.Discover(a.ctx, issuer)
+}
+
+// GetIdentity returns the decoded and validated id_token and the userinfo
+// response for the user signed in to a credential profile of envName ("" is
+// the default profile).
+func (a *App) GetIdentity(envName string, profile string) (oauth.Identity, error) {
+	ws, err := a.use()
+	if err != nil {
+		return oauth.Identity{}, err
+	}
+	defer ws.release()
+	env, err := a.profileView(ws, envName, profile)
+	if err != nil {
+		return oauth.Identity{}, err
+	}
+	return oauth.Inspect(a.ctx, oauthConfig(env), env.IDToken, env.AccessToken), nil
+}
+
+// InspectToken introspects the access token (or, with which set to
+// "refresh", the refresh token) of a credential profile of envName at the
+// authorization server.
+func (a *App) InspectToken(envName string, profile string, which string) (map[string]any, error) {
+	ws, err := a.use()
+	if err != nil {
+		return nil, err
+	}
+	defer ws.release()
+	env, err := a.profileView(ws, envName, profile)
+	if err != nil {
+		return nil, err
+	}
+	token, hint := env.AccessToken, oauth.TokenHintAccess
+	if which == "refresh" {
+		token, hint = env.RefreshToken, oauth.TokenHintRefresh
+	}
+	if token == "" {
+		return nil, fmt.Errorf("environment %s has no %s", envName, hint)
+	}
+	return oauth.Introspect(a.ctx, oauthConfig(env), token, hint)
+}
+
+// Logout clears the tokens of a credential profile of envName and revokes them at the authorization
+// server when it has a revocation endpoint. The tokens are cleared even if
+// revocation fails, and that failure is returned.
+func (a *App) Logout(envName string, profile string) (db.Environment, error) {
+	ws, err := a.use()
+	if err != nil {
+		return db.Environment{}, err
+	}
+	defer ws.release()
+	env, err := a.unlockedEnvironment(ws, envName)
+	if err != nil {
+		return env, err
+	}
+	view, err := env.WithProfile(profile)
+	if err != nil {
+		return env, err
+	}
+	cfg := oauthConfig(view)
+
+	var revokeErr error
+	if cfg.RevokeURL != "" || cfg.Issuer != "" {
+		if view.RefreshToken != "" {
+			revokeErr = oauth.Revoke(a.ctx, cfg, view.RefreshToken, oauth.TokenHintRefresh)
+		}
+		if view.AccessToken != "" {
+			if err := oauth.Revoke(a.ctx, cfg, view.AccessToken, oauth.TokenHintAccess); err != nil && revokeErr == nil {
+				revokeErr = err
+			}
+		}
+	}
+
+	view = withToken(view, oauth.Token{})
+	view.RefreshToken = ""
+	view.IDToken = ""
+	env.SetProfile(profile, view)
+	if err := db.SaveEnvironment(ws.dbChan, ws.vault, env); err != nil {
+		return env, fmt.Errorf("failed to clear tokens: %w", err)
+	}
+	if revokeErr != nil {
+		return env, fmt.Errorf("tokens were cleared locally but revocation failed: %w", revokeErr)
+	}
+	return env, nil
+}
+
+// profileView loads envName with the tokens of profile in place of the
+// default ones.
+func (a *App) profileView(ws *openWorkspace, envName string, profile string) (db.Environment, error) {
+	env, err := a.unlockedEnvironment(ws, envName)
+	if err != nil {
+		return env, err
+	}
+	return env.WithProfile(profile)
+}
+
+func (a *App) unlockedEnvironment(ws *openWorkspace, name string) (db.Environment, error) {
+	env, ok, err := db.GetEnvironment(ws.db, ws.vault, name)
+	if err != nil {
+		return env, err
+	}
+	if !ok {
+		return env, fmt.Errorf("environment %s not found", name)
+	}
+	if env.Locked {
+		return env, secrets.ErrLocked
+	}
+	return env, nil
+}
+
+// withToken stores a freshly issued token on env, keeping the previous
+// refresh token when the provider did not rotate it.
+func withToken(env db.Environment, token oauth.Token) db.Environment {
+	env.AccessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		env.RefreshToken = token.RefreshToken
+	}
+	if token.IDToken != "" {
+		env.IDToken = token.IDToken
+	}
+	env.ExpiresAt = ""
+	if token.ExpiresIn > 0 {
+		env.ExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second).Format(time.RFC3339)
+	}
+
+	if env.OAuth2Config != "" {
 		var config map[string]any
-		if err := json.Unmarshal([]byte(updatedEnv.OAuth2Config), &config); err == nil {
-			config["accessToken"] = accessToken
+		if err := json.Unmarshal([]byte(env.OAuth2Config), &config); err == nil {
+			config["accessToken"] = token.AccessToken
 			if data, err := json.Marshal(config); err == nil {
-				updatedEnv.OAuth2Config = string(data)
+				env.OAuth2Config = string(data)
 			}
 		}
 	}
+	return env
+}
 
-	if err := a.SaveEnvironment(updatedEnv); err != nil {
-		return updatedEnv, fmt.Errorf("failed to save tokens: %w", err)
+// authorizeRequest adds the environment's access token as the
+// Authorization header, unless the request already sets one, refreshing
+// the token first when it has expired or is about to.
+func (a *App) authorizeRequest(ws *openWorkspace, req pkg.RequestData) (pkg.RequestData, error) {
+	for k := range req.Headers {
+		if strings.EqualFold(k, "Authorization") {
+			return req, nil
+		}
 	}
 
-	return updatedEnv, nil
+	env, err := a.freshEnvironment(ws, req.Environment, req.Profile)
+	if err != nil || env.AccessToken == "" {
+		return req, err
+	}
+
+	prefix := oauthConfig(env).HeaderPrefix
+	if prefix == "" {
+		prefix = "Bearer"
+	}
+	headers := make(map[string]string, len(req.Headers)+1)
+	for k, v := range req.Headers {
+		headers[k] = v
+	}
+	headers["Authorization"] = prefix + " " + env.AccessToken
+	req.Headers = headers
+	return req, nil
+}
+
+// freshEnvironment loads the named environment, viewed through a credential
+// profile, and renews that profile's access token if it expires within the
+// next 30 seconds. A failed refresh falls back to
+// re-running non-interactive grants; interactive ones report that the user
+// has to sign in again.
+func (a *App) freshEnvironment(ws *openWorkspace, name string, profile string) (db.Environment, error) {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
+
+	env, ok, err := db.GetEnvironment(ws.db, ws.vault, name)
+	if err != nil || !ok || env.Locked {
+		return env, err
+	}
+	view, err := env.WithProfile(profile)
+	if err != nil || view.AccessToken == "" {
+		return view, err
+	}
+	cfg := oauthConfig(view)
+	if !oauth.Expired(view.ExpiresAt, 30*time.Second) || (cfg.AutoRefresh != nil && !*cfg.AutoRefresh) {
+		return view, nil
+	}
+
+	label := fmt.Sprintf("environment %q", name)
+	if profile != "" {
+		label += fmt.Sprintf(" (profile %q)", profile)
+	}
+	token, err := oauth.RefreshToken(a.ctx, cfg, view.RefreshToken)
+	if err != nil {
+		if cfg.Interactive() {
+			return view, fmt.Errorf("access token for %s has expired and could not be refreshed (%v); sign in again from the environment settings", label, err)
+		}
+		var flowErr error
+		if token, flowErr = oauth.PerformOAuthFlow(a.ctx, cfg, nil, nil); flowErr != nil {
+			return view, fmt.Errorf("access token for %s has expired: refresh failed (%v) and requesting a new token failed: %w", label, err, flowErr)
+		}
+	}
+
+	view = withToken(view, token)
+	env.SetProfile(profile, view)
+	if err := db.SaveEnvironment(ws.dbChan, ws.vault, env); err != nil {
+		return view, fmt.Errorf("failed to save refreshed tokens: %w", err)
+	}
+	return view, nil
+}
+
+// oauthConfig reads the OAuth2 settings saved from the auth panel and falls
+// back to the environment's top-level fields for anything left empty.
+func oauthConfig(env db.Environment) oauth.Config {
+	var cfg oauth.Config
+	if env.OAuth2Config != "" {
+		_ = json.Unmarshal([]byte(env.OAuth2Config), &cfg)
+	}
+
+	fallback := func(v *string, def string) {
+		if *v == "" {
+			*v = def
+		}
+	}
+	fallback(&cfg.ClientID, env.ClientID)
+	fallback(&cfg.ClientSecret, env.ClientSecret)
+	fallback(&cfg.AuthURL, env.AuthURL)
+	fallback(&cfg.TokenURL, env.TokenURL)
+	fallback(&cfg.RedirectURI, env.RedirectURI)
+	fallback(&cfg.Scope, env.Scope)
+	fallback(&cfg.Scope, env.Variables["scope"])
+	fallback(&cfg.Scope, "openid profile email")
+	fallback(&cfg.GrantType, oauth.GrantAuthorizationCode)
+	fallback(&cfg.ClientAuth, oauth.ClientAuthBasic)
+	return cfg
 }
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	pkg "CommandPost/goInternal/pkg/inAppExec"
+)
+
+// TestSwitchWorkspaceWhileInUse switches workspaces while other bindings
+// write to them; no call may hit a closed database or writer.
+func TestSwitchWorkspaceWhileInUse(t *testing.T) {
+	a := NewApp(t.TempDir())
+	a.startup(context.Background())
+	defer a.shutdown(context.Background())
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err := a.SaveHistory(pkg.RequestData{Method: "GET", URL: "http://example.com"}, pkg.ResponseData{StatusCode: 200}); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := a.LoadHistory(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		if err := a.CreateWorkspace(fmt.Sprintf("ws%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if got := a.GetCurrentWorkspace(); got != "ws9" {
+		t.Errorf("current workspace = %s, want ws9", got)
+	}
+}
+
+func TestBindingsWithoutWorkspace(t *testing.T) {
+	a := NewApp(t.TempDir())
+	if _, err := a.LoadHistory(); !errors.Is(err, errNoWorkspace) {
+		t.Errorf("LoadHistory() before startup = %v, want errNoWorkspace", err)
+	}
+	if got := a.GetCurrentWorkspace(); got != "" {
+		t.Errorf("GetCurrentWorkspace() before startup = %q", got)
+	}
+
+	a.startup(context.Background())
+	a.shutdown(context.Background())
+	if err := a.SaveHistory(pkg.RequestData{}, pkg.ResponseData{}); !errors.Is(err, errNoWorkspace) {
+		t.Errorf("SaveHistory() after shutdown = %v, want errNoWorkspace", err)
+	}
+}
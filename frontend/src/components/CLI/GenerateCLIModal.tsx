@@ -4,14 +4,16 @@ import { X, FolderOpen, Terminal, CheckCircle2 } from "lucide-react";
 interface GenerateCLIModalProps {
     isOpen: boolean;
     onClose: () => void;
-    onGenerate: (moduleName: string, outputDir: string) => Promise<void>;
+    onGenerate: (moduleName: string, outputDir: string, configPath: string) => Promise<void>;
     onSelectDir: () => Promise<string | null>;
+    onSelectFile: () => Promise<string | null>;
     specPath: string;
 }
 
-export function GenerateCLIModal({ isOpen, onClose, onGenerate, onSelectDir, specPath }: GenerateCLIModalProps) {
+export function GenerateCLIModal({ isOpen, onClose, onGenerate, onSelectDir, onSelectFile, specPath }: GenerateCLIModalProps) {
     const [moduleName, setModuleName] = useState("my-cli");
     const [outputDir, setOutputDir] = useState("");
+    const [configPath, setConfigPath] = useState("");
     const [loading, setLoading] = useState(false);
     const [success, setSuccess] = useState(false);
     const [error, setError] = useState<string | null>(null);
@@ -25,6 +27,13 @@ export function GenerateCLIModal({ isOpen, onClose, onGenerate, onSelectDir, spe
         }
     };
 
+    const handleBrowseConfig = async () => {
+        const file = await onSelectFile();
+        if (file) {
+            setConfigPath(file);
+        }
+    };
+
     const handleGenerate = async () => {
         if (!moduleName || !outputDir) {
             setError("Module name and output directory are required");
@@ -34,7 +43,7 @@ export function GenerateCLIModal({ isOpen, onClose, onGenerate, onSelectDir, spe
         setLoading(true);
         setError(null);
         try {
-            await onGenerate(moduleName, outputDir);
+            await onGenerate(moduleName, outputDir, configPath);
             setSuccess(true);
             setTimeout(() => {
                 setSuccess(false);
@@ -101,6 +110,23 @@ export function GenerateCLIModal({ isOpen, onClose, onGenerate, onSelectDir, spe
                             </div>
                         </div>
 
+                        <div className="form-group">
+                            <label>Generator Config (optional)</label>
+                            <div className="input-with-action">
+                                <input
+                                    type="text"
+                                    value={configPath}
+                                    onChange={(e) => setConfigPath(e.target.value)}
+                                    placeholder="generator.yaml"
+                                />
+                                <button className="btn-secondary" onClick={handleBrowseConfig}>
+                                    <FolderOpen size={16} />
+                                    Browse
+                                </button>
+                            </div>
+                            <span className="input-hint">Overrides command names, aliases and visibility per operationId or "METHOD /path"</span>
+                        </div>
+
                         {error && <div className="error-message">{error}</div>}
 
                         <div className="modal-actions">
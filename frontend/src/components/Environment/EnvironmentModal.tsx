@@ -1,8 +1,11 @@
 import { useState, useEffect } from 'react';
 import { X, Save, Plus, Trash2, Key, Info, Globe } from 'lucide-react';
-import { Environment } from '../../types';
-import { PerformOAuthFlow } from '../../../wailsjs/go/main/App';
+import { AuthConfig, CredentialProfile, Environment } from '../../types';
+import { oauth } from '../../../wailsjs/go/models';
+import { CancelOAuthFlow, DiscoverOIDC, GetIdentity, InspectToken, Logout, PerformOAuthFlow } from '../../../wailsjs/go/main/App';
+import { EventsOn } from '../../../wailsjs/runtime/runtime';
 import { OAuth2Panel } from '../Request/OAuth2Panel';
+import { CodeHighlighter } from '../Response/CodeHighlighter';
 
 interface EnvironmentModalProps {
     isOpen: boolean;
@@ -16,6 +19,15 @@ export function EnvironmentModal({ isOpen, onClose, environments, onSave, onDele
     const [selectedEnv, setSelectedEnv] = useState<Environment | null>(null);
     const [editEnv, setEditEnv] = useState<Environment | null>(null);
     const [activeTab, setActiveTab] = useState<'general' | 'auth' | 'variables'>('general');
+    const [authPending, setAuthPending] = useState(false);
+    const [profile, setProfile] = useState('');
+    const [identity, setIdentity] = useState<oauth.Identity | null>(null);
+    const [introspection, setIntrospection] = useState<Record<string, any> | null>(null);
+    const [deviceCode, setDeviceCode] = useState<{ user_code: string; verification_uri: string } | null>(null);
+
+    useEffect(() => {
+        return EventsOn('oauth:device-code', (device) => setDeviceCode(device));
+    }, []);
 
     useEffect(() => {
         if (isOpen && environments.length > 0 && !selectedEnv) {
@@ -41,6 +53,7 @@ export function EnvironmentModal({ isOpen, onClose, environments, onSave, onDele
             base_url: "",
             access_token: "",
             refresh_token: "",
+            id_token: "",
             expires_at: "",
             auth_url: "",
             token_url: "",
@@ -51,49 +64,112 @@ export function EnvironmentModal({ isOpen, onClose, environments, onSave, onDele
             variables: {},
             created_at: new Date().toISOString(),
             last_used: new Date().toISOString(),
-            oauth2_config: ""
+            oauth2_config: "",
+            parent: "",
+            secret_variables: [],
+            profiles: [],
+            locked: false
         };
         setSelectedEnv(null);
+        setProfile('');
         setEditEnv(newEnv);
     };
 
     const handleSelect = (env: Environment) => {
         setSelectedEnv(env);
+        setIdentity(null);
+        setIntrospection(null);
+        setProfile('');
         setEditEnv(parseEnv(env));
     };
 
+    const profileConfig = (p?: CredentialProfile): AuthConfig['oauth2Config'] => {
+        if (p?.oauth2_config) {
+            try {
+                return JSON.parse(p.oauth2_config);
+            } catch (e) {
+                console.error("Failed to parse profile oauth2_config", e);
+            }
+        }
+        return undefined;
+    };
+
+    const findProfile = (env: Environment | null) => (env?.profiles || []).find(p => p.name === profile);
+
+    // The token state shown for the selected profile; the default profile
+    // lives on the environment itself.
+    const savedCredentials = profile ? findProfile(selectedEnv) : selectedEnv;
+    const activeConfig = profile ? profileConfig(findProfile(editEnv)) : editEnv?.oauth2Config;
+
+    const handleSelectProfile = (name: string) => {
+        setProfile(name);
+        setIdentity(null);
+        setIntrospection(null);
+    };
+
+    const handleAddProfile = () => {
+        if (!editEnv) return;
+        const name = prompt("Profile name:", "admin")?.trim();
+        if (!name) return;
+        if ((editEnv.profiles || []).some(p => p.name === name)) {
+            alert(`Profile ${name} already exists.`);
+            return;
+        }
+        const created: CredentialProfile = { name, oauth2_config: "", access_token: "", refresh_token: "", id_token: "", expires_at: "" };
+        setEditEnv({ ...editEnv, profiles: [...(editEnv.profiles || []), created] });
+        handleSelectProfile(name);
+    };
+
+    const handleRemoveProfile = () => {
+        if (!editEnv || !profile) return;
+        if (!confirm(`Remove profile ${profile}? Its tokens are discarded when the environment is saved.`)) return;
+        setEditEnv({ ...editEnv, profiles: (editEnv.profiles || []).filter(p => p.name !== profile) });
+        handleSelectProfile('');
+    };
+
+    const updateProfileConfig = (config: NonNullable<AuthConfig['oauth2Config']>) => {
+        if (!editEnv) return;
+        setEditEnv({
+            ...editEnv,
+            profiles: (editEnv.profiles || []).map(p => p.name === profile
+                ? { ...p, oauth2_config: JSON.stringify(config), access_token: config.accessToken || '' }
+                : p)
+        });
+    };
+
+    const serializeEnv = (env: Environment): Environment => {
+        const envToSave = { ...env };
+        if (envToSave.oauth2Config) {
+            envToSave.oauth2_config = JSON.stringify(envToSave.oauth2Config);
+            // Also sync top-level fields for backward compatibility/backend logic
+            envToSave.access_token = envToSave.oauth2Config.accessToken || '';
+            envToSave.auth_url = envToSave.oauth2Config.authUrl || '';
+            envToSave.token_url = envToSave.oauth2Config.accessTokenUrl || '';
+            envToSave.client_id = envToSave.oauth2Config.clientId || '';
+            envToSave.client_secret = envToSave.oauth2Config.clientSecret || '';
+            envToSave.redirect_uri = envToSave.oauth2Config.callbackUrl || '';
+            envToSave.scope = envToSave.oauth2Config.scope || '';
+        }
+        return envToSave;
+    };
+
     const handleSave = async () => {
         if (editEnv) {
-            const envToSave = { ...editEnv };
-            if (envToSave.oauth2Config) {
-                envToSave.oauth2_config = JSON.stringify(envToSave.oauth2Config);
-                // Also sync top-level fields for backward compatibility/backend logic
-                envToSave.access_token = envToSave.oauth2Config.accessToken || '';
-                envToSave.auth_url = envToSave.oauth2Config.authUrl || '';
-                envToSave.token_url = envToSave.oauth2Config.accessTokenUrl || '';
-                envToSave.client_id = envToSave.oauth2Config.clientId || '';
-                envToSave.client_secret = envToSave.oauth2Config.clientSecret || '';
-                envToSave.redirect_uri = envToSave.oauth2Config.callbackUrl || '';
-                envToSave.scope = envToSave.oauth2Config.scope || '';
-            }
+            const envToSave = serializeEnv(editEnv);
             await onSave(envToSave);
             setSelectedEnv(envToSave);
         }
     };
 
     const handleDiscover = async () => {
-        if (!editEnv?.base_url) {
-            alert("Please provide a Base URL first.");
+        const issuer = editEnv?.oauth2Config?.issuer || editEnv?.base_url;
+        if (!editEnv || !issuer) {
+            alert("Please provide an Issuer URL or Base URL first.");
             return;
         }
 
         try {
-            const baseUrl = editEnv.base_url.replace(/\/$/, "");
-            const discoveryUrl = `${baseUrl}/.well-known/openid-configuration`;
-            const response = await fetch(discoveryUrl);
-            if (!response.ok) throw new Error("Discovery document not found");
-
-            const config = await response.json();
+            const metadata = await DiscoverOIDC(issuer);
             const oauth2Config = editEnv.oauth2Config || {
                 headerPrefix: 'Bearer',
                 autoRefreshToken: true,
@@ -105,36 +181,109 @@ export function EnvironmentModal({ isOpen, onClose, environments, onSave, onDele
 
             setEditEnv({
                 ...editEnv,
-                auth_url: config.authorization_endpoint || "",
-                token_url: config.token_endpoint || "",
+                auth_url: metadata.authorization_endpoint || "",
+                token_url: metadata.token_endpoint || "",
                 oauth2Config: {
                     ...oauth2Config,
-                    authUrl: config.authorization_endpoint || "",
-                    accessTokenUrl: config.token_endpoint || ""
+                    issuer: metadata.issuer,
+                    authUrl: metadata.authorization_endpoint || "",
+                    accessTokenUrl: metadata.token_endpoint || "",
+                    deviceAuthUrl: metadata.device_authorization_endpoint || oauth2Config.deviceAuthUrl
                 }
             });
-            alert("Discovery successful! Auth and Token URLs updated.");
+            alert("Discovery successful! Endpoints updated.");
         } catch (err) {
             console.error("Discovery error:", err);
-            alert("Could not discover OAuth2 configuration automatically. Please fill fields manually.");
+            alert("Could not discover OpenID Connect configuration: " + err);
+        }
+    };
+
+    const handleInspectToken = async () => {
+        if (!selectedEnv) return;
+        try {
+            setIntrospection(await InspectToken(selectedEnv.name, profile, "access"));
+        } catch (err) {
+            alert("Could not inspect token: " + err);
         }
     };
 
+    const handleLogout = async () => {
+        if (!selectedEnv) return;
+        const target = profile ? `${selectedEnv.name} (${profile})` : selectedEnv.name;
+        if (!confirm(`Log out of ${target} and revoke its tokens?`)) return;
+        try {
+            const result = await Logout(selectedEnv.name, profile);
+            setSelectedEnv(result);
+            setEditEnv(parseEnv(result));
+            setIdentity(null);
+            setIntrospection(null);
+        } catch (err) {
+            // Tokens are cleared locally even when revocation fails.
+            alert(String(err));
+            if (profile) {
+                const clearProfile = (env: Environment): Environment => ({
+                    ...env,
+                    profiles: (env.profiles || []).map(p => p.name === profile
+                        ? { ...p, access_token: "", refresh_token: "", id_token: "", expires_at: "" }
+                        : p)
+                });
+                setSelectedEnv(clearProfile(selectedEnv));
+                setEditEnv(editEnv && clearProfile(editEnv));
+                return;
+            }
+            const cleared = { ...selectedEnv, access_token: "", refresh_token: "", id_token: "", expires_at: "" };
+            setSelectedEnv(cleared);
+            setEditEnv(editEnv && {
+                ...editEnv,
+                ...cleared,
+                oauth2Config: editEnv.oauth2Config && { ...editEnv.oauth2Config, accessToken: "" },
+            });
+        }
+    };
+
+    const handleIdentity = async () => {
+        if (!selectedEnv) return;
+        try {
+            setIdentity(await GetIdentity(selectedEnv.name, profile));
+        } catch (err) {
+            alert("Could not load identity: " + err);
+        }
+    };
+
+
     const handleAuth = async () => {
         if (!editEnv) return;
 
-        if (editEnv.access_token && !isExpired(editEnv.expires_at)) {
+        const current = profile ? findProfile(editEnv) : editEnv;
+        if (current?.access_token && !isExpired(current.expires_at)) {
             if (!confirm("Token is still valid. Do you want to refresh it?")) return;
         }
 
-        if (!editEnv.auth_url || !editEnv.token_url || !editEnv.client_id) {
-            alert("Please provide Authorization URL, Token URL, and Client ID first.");
+        // Profiles fall back to the environment's client settings.
+        const grantType = activeConfig?.grantType || 'authorization_code';
+        const deviceAuthUrl = activeConfig?.deviceAuthUrl;
+        const tokenUrl = activeConfig?.accessTokenUrl || editEnv.token_url;
+        const clientId = activeConfig?.clientId || editEnv.client_id;
+        const authUrl = activeConfig?.authUrl || editEnv.auth_url;
+        if (!tokenUrl || !clientId) {
+            alert("Please provide Token URL and Client ID first.");
+            setActiveTab("auth");
+            return;
+        }
+        if (grantType === 'authorization_code' && !authUrl) {
+            alert("Please provide the Authorization URL first.");
+            setActiveTab("auth");
+            return;
+        }
+        if (grantType === 'device_code' && !deviceAuthUrl) {
+            alert("Please provide the Device Authorization URL first.");
             setActiveTab("auth");
             return;
         }
 
+        setAuthPending(true);
         try {
-            const result = await PerformOAuthFlow(editEnv);
+            const result = await PerformOAuthFlow(serializeEnv(editEnv), profile);
             // Sync new token back to oauth2Config
             const parsedResult = parseEnv(result);
             if (parsedResult.oauth2Config) {
@@ -160,9 +309,20 @@ export function EnvironmentModal({ isOpen, onClose, environments, onSave, onDele
         } catch (err) {
             console.error("Auth error:", err);
             alert("Authentication failed: " + err);
+        } finally {
+            setAuthPending(false);
+            setDeviceCode(null);
         }
     };
 
+    const isSecret = (key: string) => (editEnv?.secret_variables || []).includes(key);
+
+    const toggleSecret = (key: string, secret: boolean) => {
+        if (!editEnv) return;
+        const others = (editEnv.secret_variables || []).filter(k => k !== key);
+        setEditEnv({ ...editEnv, secret_variables: secret ? [...others, key] : others });
+    };
+
     const isExpired = (expiresAt: string) => {
         if (!expiresAt) return true;
         const expires = new Date(expiresAt);
@@ -236,6 +396,18 @@ export function EnvironmentModal({ isOpen, onClose, environments, onSave, onDele
                                                     placeholder="https://api.example.com"
                                                 />
                                             </div>
+                                            <div className="form-item">
+                                                <label>Parent Environment</label>
+                                                <select
+                                                    value={editEnv.parent || ""}
+                                                    onChange={e => setEditEnv({ ...editEnv, parent: e.target.value })}
+                                                >
+                                                    <option value="">None</option>
+                                                    {environments.filter(env => env.name !== editEnv.name).map(env => (
+                                                        <option key={env.name} value={env.name}>{env.name}</option>
+                                                    ))}
+                                                </select>
+                                            </div>
                                         </div>
                                     )}
 
@@ -243,44 +415,129 @@ export function EnvironmentModal({ isOpen, onClose, environments, onSave, onDele
                                         <div className="auth-section">
                                             <div className="info-box mb-4">
                                                 <Info size={16} />
-                                                <span>Configure OAuth2 authentication for this environment.</span>
+                                                <span>Configure OAuth2 authentication for this environment. Extra profiles keep separate tokens (e.g. admin and read-only users) and use the default client settings for any field left empty.</span>
+                                            </div>
+
+                                            <div className="form-group mb-4">
+                                                <label>Credential Profile</label>
+                                                <div className="auth-actions">
+                                                    <select
+                                                        className="input-select"
+                                                        value={profile}
+                                                        onChange={(e) => handleSelectProfile(e.target.value)}
+                                                    >
+                                                        <option value="">Default</option>
+                                                        {(editEnv.profiles || []).map(p => (
+                                                            <option key={p.name} value={p.name}>{p.name}</option>
+                                                        ))}
+                                                    </select>
+                                                    <button className="btn btn-secondary" onClick={handleAddProfile}>
+                                                        <Plus size={14} /> Add Profile
+                                                    </button>
+                                                    {profile && (
+                                                        <button className="btn btn-secondary" onClick={handleRemoveProfile}>
+                                                            <Trash2 size={14} /> Remove
+                                                        </button>
+                                                    )}
+                                                </div>
                                             </div>
 
                                             <div className="auth-actions mb-4">
                                                 <button className="btn btn-secondary" onClick={handleDiscover}>
                                                     <Globe size={14} /> Discover Config
                                                 </button>
+                                                {savedCredentials?.access_token && (
+                                                    <>
+                                                        <button className="btn btn-secondary" onClick={handleInspectToken}>
+                                                            <Info size={14} /> Inspect Token
+                                                        </button>
+                                                        <button className="btn btn-secondary" onClick={handleLogout}>
+                                                            <X size={14} /> Log Out
+                                                        </button>
+                                                    </>
+                                                )}
+                                                {savedCredentials?.id_token && (
+                                                    <button className="btn btn-secondary" onClick={handleIdentity}>
+                                                        <Key size={14} /> View Identity
+                                                    </button>
+                                                )}
                                             </div>
 
-                                            <OAuth2Panel
-                                                config={editEnv.oauth2Config || {
-                                                    headerPrefix: 'Bearer',
-                                                    autoRefreshToken: true,
-                                                    shareToken: false,
-                                                    grantType: 'authorization_code',
-                                                    callbackUrl: editEnv.redirect_uri || 'http://your-application.com/registered/callback',
-                                                    authUrl: editEnv.auth_url || '',
-                                                    accessTokenUrl: editEnv.token_url || '',
-                                                    clientId: editEnv.client_id || '',
-                                                    clientSecret: editEnv.client_secret || '',
-                                                    scope: editEnv.scope || '',
-                                                    clientAuth: 'basic'
-                                                }}
-                                                onChange={(config) => {
-                                                    setEditEnv({
-                                                        ...editEnv,
-                                                        oauth2Config: config,
-                                                        // Sync back to top-level for immediate use in handleAuth
-                                                        auth_url: config.authUrl || '',
-                                                        token_url: config.accessTokenUrl || '',
-                                                        client_id: config.clientId || '',
-                                                        client_secret: config.clientSecret || '',
-                                                        redirect_uri: config.callbackUrl || '',
-                                                        scope: config.scope || ''
-                                                    });
-                                                }}
-                                                onGetToken={handleAuth}
-                                            />
+                                            {introspection && (
+                                                <div className="form-group mb-4">
+                                                    <label>Token {introspection.active ? 'is active' : 'is not active'}</label>
+                                                    <CodeHighlighter code={JSON.stringify(introspection, null, 2)} />
+                                                </div>
+                                            )}
+
+                                            {identity && (
+                                                <div className="form-group mb-4">
+                                                    <label>
+                                                        ID Token {identity.verified ? '(verified)' : `(not verified${identity.verifyError ? ': ' + identity.verifyError : ''})`}
+                                                    </label>
+                                                    <CodeHighlighter code={JSON.stringify(identity.idToken?.claims ?? {}, null, 2)} />
+                                                    <label>UserInfo {identity.userInfoError ? `(${identity.userInfoError})` : ''}</label>
+                                                    <CodeHighlighter code={JSON.stringify(identity.userInfo ?? {}, null, 2)} />
+                                                </div>
+                                            )}
+
+                                            {deviceCode && (
+                                                <div className="info-box mb-4">
+                                                    <Info size={16} />
+                                                    <span>
+                                                        Enter code <strong>{deviceCode.user_code}</strong> at {deviceCode.verification_uri} to finish signing in.
+                                                    </span>
+                                                </div>
+                                            )}
+
+                                            {profile ? (
+                                                <OAuth2Panel
+                                                    key={profile}
+                                                    config={activeConfig || {
+                                                        headerPrefix: 'Bearer',
+                                                        autoRefreshToken: true,
+                                                        shareToken: false,
+                                                        grantType: 'authorization_code',
+                                                        clientAuth: 'basic'
+                                                    }}
+                                                    onChange={updateProfileConfig}
+                                                    onGetToken={handleAuth}
+                                                    onCancel={() => CancelOAuthFlow()}
+                                                    pending={authPending}
+                                                />
+                                            ) : (
+                                                <OAuth2Panel
+                                                    config={editEnv.oauth2Config || {
+                                                        headerPrefix: 'Bearer',
+                                                        autoRefreshToken: true,
+                                                        shareToken: false,
+                                                        grantType: 'authorization_code',
+                                                        callbackUrl: editEnv.redirect_uri || 'http://your-application.com/registered/callback',
+                                                        authUrl: editEnv.auth_url || '',
+                                                        accessTokenUrl: editEnv.token_url || '',
+                                                        clientId: editEnv.client_id || '',
+                                                        clientSecret: editEnv.client_secret || '',
+                                                        scope: editEnv.scope || '',
+                                                        clientAuth: 'basic'
+                                                    }}
+                                                    onChange={(config) => {
+                                                        setEditEnv({
+                                                            ...editEnv,
+                                                            oauth2Config: config,
+                                                            // Sync back to top-level for immediate use in handleAuth
+                                                            auth_url: config.authUrl || '',
+                                                            token_url: config.accessTokenUrl || '',
+                                                            client_id: config.clientId || '',
+                                                            client_secret: config.clientSecret || '',
+                                                            redirect_uri: config.callbackUrl || '',
+                                                            scope: config.scope || ''
+                                                        });
+                                                    }}
+                                                    onGetToken={handleAuth}
+                                                    onCancel={() => CancelOAuthFlow()}
+                                                    pending={authPending}
+                                                />
+                                            )}
                                         </div>
                                     )}
 
@@ -290,9 +547,17 @@ export function EnvironmentModal({ isOpen, onClose, environments, onSave, onDele
                                             <div className="form-group-stack">
                                                 {Object.entries(editEnv.variables).map(([key, value]) => (
                                                     <div className="form-item" key={key}>
-                                                        <label>{key}</label>
+                                                        <label>
+                                                            {key}
+                                                            <input
+                                                                type="checkbox"
+                                                                title="Secret"
+                                                                checked={isSecret(key)}
+                                                                onChange={e => toggleSecret(key, e.target.checked)}
+                                                            />
+                                                        </label>
                                                         <input
-                                                            type="text"
+                                                            type={isSecret(key) ? "password" : "text"}
                                                             value={value}
                                                             onChange={e => {
                                                                 const newVariables = { ...editEnv.variables };
@@ -0,0 +1,179 @@
+import { useEffect, useState } from "react";
+import { X, KeyRound } from "lucide-react";
+import { DecodeJWT, FindJWTs, MintJWT, VerifyJWT } from "../../../wailsjs/go/main/App";
+import { jwt } from "../../../wailsjs/go/models";
+import { CodeHighlighter } from "../Response/CodeHighlighter";
+import { Environment } from "../../types";
+
+interface JwtModalProps {
+    isOpen: boolean;
+    onClose: () => void;
+    source: string;
+    activeEnv: Environment | null;
+    onMinted: () => void;
+}
+
+const formatExpiry = (info: jwt.Info) => {
+    if (!info.hasExpiry) return "No expiry";
+    if (info.expired) return `Expired at ${info.expiresAt}`;
+    const s = info.expiresIn;
+    return `Expires in ${Math.floor(s / 3600)}h ${Math.floor((s % 3600) / 60)}m ${s % 60}s (${info.expiresAt})`;
+};
+
+export function JwtModal({ isOpen, onClose, source, activeEnv, onMinted }: JwtModalProps) {
+    const [raw, setRaw] = useState("");
+    const [found, setFound] = useState<jwt.Info[]>([]);
+    const [info, setInfo] = useState<jwt.Info | null>(null);
+    const [error, setError] = useState<string | null>(null);
+
+    const [keyType, setKeyType] = useState("secret");
+    const [key, setKey] = useState("");
+    const [verifyResult, setVerifyResult] = useState<jwt.VerifyResult | null>(null);
+
+    const [alg, setAlg] = useState("HS256");
+    const [keyVariable, setKeyVariable] = useState("");
+    const [claims, setClaims] = useState('{\n  "sub": "test-user"\n}');
+    const [expiresIn, setExpiresIn] = useState(3600);
+    const [saveAs, setSaveAs] = useState("jwt");
+
+    useEffect(() => {
+        if (!isOpen) return;
+        FindJWTs(source).then((tokens) => {
+            setFound(tokens || []);
+            if (tokens && tokens.length > 0) {
+                setRaw(tokens[0].raw);
+                setInfo(tokens[0]);
+            }
+        });
+    }, [isOpen, source]);
+
+    useEffect(() => {
+        if (!info?.hasExpiry || info.expired) return;
+        const timer = setInterval(() => {
+            setInfo((current) => current && {
+                ...current,
+                expiresIn: current.expiresIn - 1,
+                expired: current.expiresIn - 1 <= 0,
+            } as jwt.Info);
+        }, 1000);
+        return () => clearInterval(timer);
+    }, [info?.raw]);
+
+    if (!isOpen) return null;
+
+    const handleDecode = async (value: string) => {
+        setRaw(value);
+        setVerifyResult(null);
+        if (!value.trim()) {
+            setInfo(null);
+            setError(null);
+            return;
+        }
+        try {
+            setInfo(await DecodeJWT(value));
+            setError(null);
+        } catch (err: any) {
+            setInfo(null);
+            setError(err.toString());
+        }
+    };
+
+    const handleVerify = async () => {
+        setVerifyResult(await VerifyJWT(raw, keyType, key));
+    };
+
+    const handleMint = async () => {
+        if (!activeEnv) {
+            setError("Select an environment that holds the signing key first.");
+            return;
+        }
+        try {
+            const token = await MintJWT(activeEnv.name, keyVariable, jwt.MintOptions.createFrom({
+                alg,
+                claims: JSON.parse(claims),
+                expiresIn,
+            }), saveAs);
+            await handleDecode(token);
+            if (saveAs) onMinted();
+        } catch (err: any) {
+            setError(err.toString());
+        }
+    };
+
+    return (
+        <div className="modal-overlay">
+            <div className="modal-content">
+                <div className="modal-header">
+                    <div className="title-group">
+                        <KeyRound size={20} className="icon" />
+                        <h3>JWT Tool</h3>
+                    </div>
+                    <button className="close-btn" onClick={onClose}>
+                        <X size={20} />
+                    </button>
+                </div>
+
+                <div className="modal-body">
+                    {found.length > 1 && (
+                        <div className="form-group">
+                            <label>Tokens found in the last request and response</label>
+                            <select className="input-select" value={raw} onChange={(e) => handleDecode(e.target.value)}>
+                                {found.map((t) => (
+                                    <option key={t.raw} value={t.raw}>{t.raw.slice(0, 40)}…</option>
+                                ))}
+                            </select>
+                        </div>
+                    )}
+
+                    <div className="form-group">
+                        <label>Token</label>
+                        <textarea rows={4} value={raw} onChange={(e) => handleDecode(e.target.value)} placeholder="Paste a JWT" />
+                    </div>
+
+                    {error && <div className="error-message">{error}</div>}
+
+                    {info && (
+                        <>
+                            <div className="form-group">
+                                <label>Header</label>
+                                <CodeHighlighter code={JSON.stringify(info.header, null, 2)} />
+                            </div>
+                            <div className="form-group">
+                                <label>Claims — {formatExpiry(info)}</label>
+                                <CodeHighlighter code={JSON.stringify(info.claims, null, 2)} />
+                            </div>
+
+                            <div className="form-group">
+                                <label>Verify with</label>
+                                <select className="input-select" value={keyType} onChange={(e) => setKeyType(e.target.value)}>
+                                    <option value="secret">Shared secret</option>
+                                    <option value="pem">PEM key or certificate</option>
+                                    <option value="jwks">JWKS URL</option>
+                                </select>
+                                <textarea rows={keyType === 'pem' ? 6 : 1} value={key} onChange={(e) => setKey(e.target.value)} />
+                                <button className="btn btn-secondary mt-4" onClick={handleVerify}>Verify</button>
+                                {verifyResult && (
+                                    <p>{verifyResult.valid ? "Signature and claims are valid." : `Invalid: ${verifyResult.error}`}</p>
+                                )}
+                            </div>
+                        </>
+                    )}
+
+                    <div className="form-group">
+                        <label>Mint a test token {activeEnv ? `with a key from ${activeEnv.name}` : ''}</label>
+                        <select className="input-select" value={alg} onChange={(e) => setAlg(e.target.value)}>
+                            <option value="HS256">HS256</option>
+                            <option value="RS256">RS256</option>
+                            <option value="ES256">ES256</option>
+                        </select>
+                        <input type="text" placeholder="Variable holding the secret or PEM private key" value={keyVariable} onChange={(e) => setKeyVariable(e.target.value)} />
+                        <textarea rows={4} value={claims} onChange={(e) => setClaims(e.target.value)} />
+                        <input type="number" placeholder="Expires in (seconds)" value={expiresIn} onChange={(e) => setExpiresIn(parseInt(e.target.value) || 0)} />
+                        <input type="text" placeholder="Save as variable (optional)" value={saveAs} onChange={(e) => setSaveAs(e.target.value)} />
+                        <button className="btn btn-primary mt-4 w-full" onClick={handleMint}>Mint Token</button>
+                    </div>
+                </div>
+            </div>
+        </div>
+    );
+}
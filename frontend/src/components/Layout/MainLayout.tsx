@@ -1,13 +1,15 @@
 import { useState, useEffect } from "react";
 import { Sidebar } from "../Sidebar/Sidebar";
 import { EndpointDef, RequestData, ResponseData, SpecDetails } from "../../types";
-import { Archive } from "lucide-react";
+import { Archive, KeyRound, Braces } from "lucide-react";
 import { RequestPanel } from "../Request/RequestPanel";
 import { ResponsePanel } from "../Response/ResponsePanel";
 import { GenerateCLIModal } from "../CLI/GenerateCLIModal";
 import { EnvironmentSwitcher } from "../Environment/EnvironmentSwitcher";
+import { JwtModal } from "../JWT/JwtModal";
+import { VariablesModal } from "../Variables/VariablesModal";
 import {
-    ParseSpecDetails, ExecuteRequest, LoadCollection, SaveHistory, SaveCollection, DeleteCollection, Generate,
+    ParseSpecDetails, SetSpecHeaders, ExecuteRequest, LoadCollection, SaveHistory, SaveCollection, DeleteCollection, Generate,
     SelectDirectory, LoadHistory, GetEnvironments, SaveEnvironment, DeleteEnvironment, DeleteHistoryItem, DeleteHistory,
     ImportCollections, SelectFile, ExportHistory, ExportCollection, SaveFileDialog
 } from "../../../wailsjs/go/main/App"
@@ -33,6 +35,9 @@ export function MainLayout() {
     const [activeEnv, setActiveEnv] = useState<Environment | null>(null);
     const [currentSpecPath, setCurrentSpecPath] = useState<string>("https://petstore3.swagger.io/api/v3/openapi.json");
     const [isGenerateModalOpen, setIsGenerateModalOpen] = useState(false);
+    const [isJwtModalOpen, setIsJwtModalOpen] = useState(false);
+    const [variablesCollection, setVariablesCollection] = useState<string | null>(null);
+    const [lastRequest, setLastRequest] = useState<RequestData | null>(null);
 
     useEffect(() => {
         ParseSpecDetails(currentSpecPath)
@@ -123,9 +128,10 @@ export function MainLayout() {
                 const cleanPath = req.url.startsWith('/') ? req.url : '/' + req.url;
                 req.url = cleanBase + cleanPath;
             }
-            const backendReq = { ...req }
+            const backendReq = { ...req, environment: activeEnv?.name || "" }
             const res = await ExecuteRequest(backendReq as any);
             setResponse(res);
+            setLastRequest(req);
 
             await SaveHistory(backendReq as any, res);
             await loadHistoryFromDB();
@@ -144,6 +150,7 @@ export function MainLayout() {
 
             const formattedRequests = requests.map(r => ({
                 ...r,
+                collection: collectionName,
                 body: typeof r.body === 'string' ? r.body : "{}"
             }));
 
@@ -182,8 +189,9 @@ export function MainLayout() {
         }
     };
 
-    const loadSpec = async (path: string) => {
-        ParseSpecDetails(path)
+    const loadSpec = async (path: string, authHeader: string = "") => {
+        SetSpecHeaders(authHeader ? [authHeader] : [])
+            .then(() => ParseSpecDetails(path))
             .then((data: any) => {
                 setEndpoints(data.endpoints);
                 setBaseUrl(data.baseUrl);
@@ -192,8 +200,8 @@ export function MainLayout() {
             .catch(console.error);
     };
 
-    const handleGenerateCLI = async (moduleName: string, outputDir: string) => {
-        return Generate(currentSpecPath, outputDir, moduleName);
+    const handleGenerateCLI = async (moduleName: string, outputDir: string, configPath: string) => {
+        return Generate(currentSpecPath, outputDir, moduleName, configPath);
     };
 
     const handleSelectDir = async () => {
@@ -274,6 +282,7 @@ export function MainLayout() {
                 onDeleteCollection={handleDeleteCollection}
                 onImportCollection={handleImportCollection}
                 onExportCollection={handleExportCollection}
+                onEditCollectionVariables={setVariablesCollection}
                 onExportHistory={handleExportHistory}
                 onDeleteHistory={handleDeleteHistory}
                 onDeleteAllHistory={handleDeleteAllHistory}
@@ -290,13 +299,21 @@ export function MainLayout() {
                     <div className="workspace">
                         <div className="workspace-header">
                             <h2>{activeEndpoint.summary}</h2>
-                            <EnvironmentSwitcher
-                                environments={environments}
-                                activeEnv={activeEnv}
-                                onSelect={setActiveEnv}
-                                onSave={handleSaveEnvironment}
-                                onDelete={handleDeleteEnvironment}
-                            />
+                            <div className="header-actions">
+                                <button className="btn btn-secondary" onClick={() => setVariablesCollection(activeEndpoint.collection || "")}>
+                                    <Braces size={14} /> Variables
+                                </button>
+                                <button className="btn btn-secondary" onClick={() => setIsJwtModalOpen(true)}>
+                                    <KeyRound size={14} /> JWT
+                                </button>
+                                <EnvironmentSwitcher
+                                    environments={environments}
+                                    activeEnv={activeEnv}
+                                    onSelect={setActiveEnv}
+                                    onSave={handleSaveEnvironment}
+                                    onDelete={handleDeleteEnvironment}
+                                />
+                            </div>
                         </div>
                         <div className="api-path">
                             <span className={`method-pill ${activeEndpoint.method.toLowerCase()}`}>{activeEndpoint.method}</span>
@@ -319,11 +336,28 @@ export function MainLayout() {
                 )}
             </div>
 
+            <JwtModal
+                isOpen={isJwtModalOpen}
+                onClose={() => setIsJwtModalOpen(false)}
+                source={JSON.stringify({ request: lastRequest, response })}
+                activeEnv={activeEnv}
+                onMinted={loadEnvironmentsFromDB}
+            />
+
+            <VariablesModal
+                isOpen={variablesCollection !== null}
+                onClose={() => setVariablesCollection(null)}
+                environment={activeEnv?.name || ""}
+                collection={collections.find(c => c.name === variablesCollection) || null}
+                onSaved={loadCollectionsFromDB}
+            />
+
             <GenerateCLIModal
                 isOpen={isGenerateModalOpen}
                 onClose={() => setIsGenerateModalOpen(false)}
                 onGenerate={handleGenerateCLI}
                 onSelectDir={handleSelectDir}
+                onSelectFile={SelectFile}
                 specPath={currentSpecPath}
             />
         </div>
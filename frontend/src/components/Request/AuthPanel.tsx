@@ -1,8 +1,11 @@
 import { useState } from "react";
 import { Eye, EyeOff } from "lucide-react";
-import { AuthConfig } from "../../types";
+import { AuthConfig, SigningType } from "../../types";
 
 import { OAuth2Panel } from "./OAuth2Panel";
+import { SigningPanel } from "./SigningPanel";
+
+const signingTypes: SigningType[] = ['aws_sigv4', 'hmac', 'digest', 'hawk'];
 
 interface AuthPanelProps {
     auth: AuthConfig;
@@ -22,7 +25,13 @@ export function AuthPanel({ auth, onChange }: AuthPanelProps) {
                 <label>Type</label>
                 <select
                     value={auth.type}
-                    onChange={(e) => onChange({ ...auth, type: e.target.value as any })}
+                    onChange={(e) => {
+                        const type = e.target.value as AuthConfig['type'];
+                        const signing = signingTypes.includes(type as SigningType)
+                            ? { ...auth.signing, type: type as SigningType }
+                            : auth.signing;
+                        onChange({ ...auth, type, signing });
+                    }}
                     className="input-select"
                 >
                     <option value="none">No Auth</option>
@@ -30,6 +39,11 @@ export function AuthPanel({ auth, onChange }: AuthPanelProps) {
                     <option value="basic">Basic Auth</option>
                     <option value="api_key">API Key</option>
                     <option value="oauth2">OAuth 2.0</option>
+                    <option value="profile">Environment Credential Profile</option>
+                    <option value="aws_sigv4">AWS Signature v4</option>
+                    <option value="hmac">HMAC Signature</option>
+                    <option value="digest">Digest Auth</option>
+                    <option value="hawk">Hawk Authentication</option>
                 </select>
             </div>
 
@@ -140,6 +154,25 @@ export function AuthPanel({ auth, onChange }: AuthPanelProps) {
                 />
             )}
 
+            {auth.type === 'profile' && (
+                <div className="form-group">
+                    <label>Profile</label>
+                    <input
+                        type="text"
+                        placeholder="Leave empty for the environment's default credentials"
+                        value={auth.profile || ''}
+                        onChange={(e) => updateAuth('profile', e.target.value)}
+                    />
+                </div>
+            )}
+
+            {auth.signing && auth.signing.type === auth.type && (
+                <SigningPanel
+                    config={auth.signing}
+                    onChange={(signing) => onChange({ ...auth, signing })}
+                />
+            )}
+
             {auth.type === 'none' && (
                 <div className="info-text">
                     This request does not use any authentication.
@@ -4,13 +4,17 @@ interface OAuth2PanelProps {
     config: NonNullable<AuthConfig['oauth2Config']>;
     onChange: (config: NonNullable<AuthConfig['oauth2Config']>) => void;
     onGetToken?: () => void;
+    onCancel?: () => void;
+    pending?: boolean;
 }
 
-export function OAuth2Panel({ config, onChange, onGetToken }: OAuth2PanelProps) {
+export function OAuth2Panel({ config, onChange, onGetToken, onCancel, pending }: OAuth2PanelProps) {
     const updateConfig = (updates: Partial<NonNullable<AuthConfig['oauth2Config']>>) => {
         onChange({ ...config, ...updates });
     };
 
+    const grantType = config.grantType || 'authorization_code';
+
     return (
         <div className="oauth2-panel">
             <div className="auth-section">
@@ -83,44 +87,114 @@ export function OAuth2Panel({ config, onChange, onGetToken }: OAuth2PanelProps)
                 <div className="form-group">
                     <label>Grant type</label>
                     <select
-                        value={config.grantType}
+                        value={grantType}
                         onChange={(e) => updateConfig({ grantType: e.target.value })}
                         className="input-select"
                     >
                         <option value="authorization_code">Authorization Code</option>
-                        <option value="implicit">Implicit</option>
                         <option value="password">Password Credentials</option>
                         <option value="client_credentials">Client Credentials</option>
+                        <option value="device_code">Device Code</option>
                     </select>
                 </div>
 
                 <div className="form-group">
-                    <label>Callback URL</label>
+                    <label>Issuer URL</label>
                     <input
                         type="text"
-                        placeholder="http://your-application.com/registered/callback"
-                        value={config.callbackUrl || ''}
-                        onChange={(e) => updateConfig({ callbackUrl: e.target.value })}
+                        placeholder="https://accounts.example.com"
+                        value={config.issuer || ''}
+                        onChange={(e) => updateConfig({ issuer: e.target.value })}
                     />
                 </div>
 
+                {grantType === 'authorization_code' && (
+                    <>
+                        <div className="form-group">
+                            <label>Callback URL</label>
+                            <input
+                                type="text"
+                                placeholder="http://your-application.com/registered/callback"
+                                value={config.callbackUrl || ''}
+                                onChange={(e) => updateConfig({ callbackUrl: e.target.value })}
+                            />
+                        </div>
+
+                        <div className="form-group">
+                            <label>Auth URL</label>
+                            <input
+                                type="text"
+                                placeholder="https://example.com/login/oauth/authorize"
+                                value={config.authUrl || ''}
+                                onChange={(e) => updateConfig({ authUrl: e.target.value })}
+                            />
+                        </div>
+                    </>
+                )}
+
+                {grantType === 'device_code' && (
+                    <div className="form-group">
+                        <label>Device Authorization URL</label>
+                        <input
+                            type="text"
+                            placeholder="https://example.com/oauth/device/code"
+                            value={config.deviceAuthUrl || ''}
+                            onChange={(e) => updateConfig({ deviceAuthUrl: e.target.value })}
+                        />
+                    </div>
+                )}
+
+                {grantType === 'password' && (
+                    <>
+                        <div className="form-group">
+                            <label>Username</label>
+                            <input
+                                type="text"
+                                placeholder="Username"
+                                value={config.username || ''}
+                                onChange={(e) => updateConfig({ username: e.target.value })}
+                            />
+                        </div>
+
+                        <div className="form-group">
+                            <label>Password</label>
+                            <input
+                                type="password"
+                                placeholder="Password"
+                                value={config.password || ''}
+                                onChange={(e) => updateConfig({ password: e.target.value })}
+                            />
+                        </div>
+                    </>
+                )}
+
                 <div className="form-group">
-                    <label>Auth URL</label>
+                    <label>Access Token URL</label>
                     <input
                         type="text"
-                        placeholder="https://example.com/login/oauth/authorize"
-                        value={config.authUrl || ''}
-                        onChange={(e) => updateConfig({ authUrl: e.target.value })}
+                        placeholder="https://example.com/login/oauth/access_token"
+                        value={config.accessTokenUrl || ''}
+                        onChange={(e) => updateConfig({ accessTokenUrl: e.target.value })}
                     />
                 </div>
 
                 <div className="form-group">
-                    <label>Access Token URL</label>
+                    <label>Introspection URL</label>
                     <input
                         type="text"
-                        placeholder="https://example.com/login/oauth/access_token"
-                        value={config.accessTokenUrl || ''}
-                        onChange={(e) => updateConfig({ accessTokenUrl: e.target.value })}
+                        placeholder="https://example.com/oauth/introspect"
+                        value={config.introspectionUrl || ''}
+                        onChange={(e) => updateConfig({ introspectionUrl: e.target.value })}
+                    />
+                </div>
+
+                <div className="form-group">
+                    <label>Revocation URL</label>
+                    <input
+                        type="text"
+                        placeholder="https://example.com/oauth/revoke"
+                        value={config.revocationUrl || ''}
+                        onChange={(e) => updateConfig({ revocationUrl: e.target.value })}
                     />
                 </div>
 
@@ -155,19 +229,20 @@ export function OAuth2Panel({ config, onChange, onGetToken }: OAuth2PanelProps)
                 </div>
 
                 <div className="form-group">
-                    <label>State</label>
+                    <label>Login Timeout (seconds)</label>
                     <input
-                        type="text"
-                        placeholder="State"
-                        value={config.state || ''}
-                        onChange={(e) => updateConfig({ state: e.target.value })}
+                        type="number"
+                        min={1}
+                        placeholder="300"
+                        value={config.flowTimeout || ''}
+                        onChange={(e) => updateConfig({ flowTimeout: parseInt(e.target.value) || undefined })}
                     />
                 </div>
 
                 <div className="form-group">
                     <label>Client Authentication</label>
                     <select
-                        value={config.clientAuth}
+                        value={config.clientAuth || 'basic'}
                         onChange={(e) => updateConfig({ clientAuth: e.target.value as any })}
                         className="input-select"
                     >
@@ -176,9 +251,15 @@ export function OAuth2Panel({ config, onChange, onGetToken }: OAuth2PanelProps)
                     </select>
                 </div>
 
-                <button className="btn btn-primary mt-4 w-full" onClick={onGetToken}>
-                    Get New Access Token
-                </button>
+                {pending ? (
+                    <button className="btn btn-secondary mt-4 w-full" onClick={onCancel}>
+                        Cancel Login
+                    </button>
+                ) : (
+                    <button className="btn btn-primary mt-4 w-full" onClick={onGetToken}>
+                        Get New Access Token
+                    </button>
+                )}
             </div>
         </div>
     );
@@ -39,9 +39,12 @@ export function RequestPanel({ endpoint, onSend, onSave, onShowGenerateModal }:
     useEffect(() => {
         setUrl(endpoint.path);
         setMethod(endpoint.method);
-        // Reset state
-        setParams([{ id: crypto.randomUUID(), key: '', value: '', enabled: true }]);
-        setHeaders([{ id: crypto.randomUUID(), key: 'Content-Type', value: 'application/json', enabled: true }, { id: crypto.randomUUID(), key: '', value: '', enabled: true }]);
+        // Reset state, listing the spec's query and header parameters
+        const declared = (location: string) => (endpoint.parameters || [])
+            .filter(p => p.in === location)
+            .map(p => ({ id: crypto.randomUUID(), key: p.name, value: '', description: p.description, enabled: p.required }));
+        setParams([...declared('query'), { id: crypto.randomUUID(), key: '', value: '', enabled: true }]);
+        setHeaders([{ id: crypto.randomUUID(), key: 'Content-Type', value: 'application/json', enabled: true }, ...declared('header'), { id: crypto.randomUUID(), key: '', value: '', enabled: true }]);
         setAuth({ type: 'none' });
         setBody("{}");
     }, [endpoint]);
@@ -108,6 +111,10 @@ export function RequestPanel({ endpoint, onSend, onSave, onShowGenerateModal }:
             }
         }
 
+        const signing = auth.signing && auth.signing.type === auth.type ? auth.signing : undefined;
+        const profile = auth.type === 'profile' ? auth.profile : undefined;
+        const collection = endpoint.collection;
+
         let requestBody = "";
         let formData: Record<string, FormDataPart> = {};
         if (bodyType === "form-data") {
@@ -123,7 +130,10 @@ export function RequestPanel({ endpoint, onSend, onSave, onShowGenerateModal }:
                 headers: headerRecord,
                 body: requestBody,
                 formData: formDataRecord,
-                timeout: 5000
+                timeout: 5000,
+                signing,
+                profile,
+                collection
             };
         } else if (bodyType === "x-www-form-urlencoded") {
             const bodyParts = urlEncodedItems
@@ -138,7 +148,10 @@ export function RequestPanel({ endpoint, onSend, onSave, onShowGenerateModal }:
                 headers: headerRecord,
                 body: requestBody,
                 formData,
-                timeout: 5000
+                timeout: 5000,
+                signing,
+                profile,
+                collection
             };
         } else if (bodyType === "raw") {
             return {
@@ -147,7 +160,10 @@ export function RequestPanel({ endpoint, onSend, onSave, onShowGenerateModal }:
                 headers: headerRecord,
                 body: body,
                 formData,
-                timeout: 5000
+                timeout: 5000,
+                signing,
+                profile,
+                collection
             };
         } else {
             return {
@@ -156,7 +172,10 @@ export function RequestPanel({ endpoint, onSend, onSave, onShowGenerateModal }:
                 headers: headerRecord,
                 body: requestBody,
                 formData,
-                timeout: 5000
+                timeout: 5000,
+                signing,
+                profile,
+                collection
             };
         }
     };
@@ -180,6 +199,9 @@ export function RequestPanel({ endpoint, onSend, onSave, onShowGenerateModal }:
                     <option value="PUT">PUT</option>
                     <option value="DELETE">DELETE</option>
                     <option value="PATCH">PATCH</option>
+                    <option value="HEAD">HEAD</option>
+                    <option value="OPTIONS">OPTIONS</option>
+                    <option value="TRACE">TRACE</option>
                 </select>
                 <input
                     className="url-input"
@@ -0,0 +1,103 @@
+import { SigningConfig } from "../../types";
+
+interface SigningPanelProps {
+    config: SigningConfig;
+    onChange: (config: SigningConfig) => void;
+}
+
+interface FieldProps {
+    label: string;
+    value?: string;
+    placeholder?: string;
+    secret?: boolean;
+    onChange: (value: string) => void;
+}
+
+function Field({ label, value, placeholder, secret, onChange }: FieldProps) {
+    return (
+        <div className="form-group">
+            <label>{label}</label>
+            <input
+                type={secret ? "password" : "text"}
+                placeholder={placeholder || (secret ? "{{variable}}" : label)}
+                value={value || ''}
+                onChange={(e) => onChange(e.target.value)}
+            />
+        </div>
+    );
+}
+
+export function SigningPanel({ config, onChange }: SigningPanelProps) {
+    const update = (updates: Partial<SigningConfig>) => onChange({ ...config, ...updates });
+
+    return (
+        <div className="auth-grid">
+            <p className="text-muted">
+                Secrets are not saved with the request. Put them in an environment secret variable and enter its {"{{name}}"} here.
+            </p>
+            {config.type === 'aws_sigv4' && (
+                <>
+                    <Field label="Access Key" value={config.accessKey} onChange={(v) => update({ accessKey: v })} />
+                    <Field label="Secret Key" value={config.secretKey} secret onChange={(v) => update({ secretKey: v })} />
+                    <Field label="Session Token" value={config.sessionToken} secret placeholder="Optional, {{variable}}" onChange={(v) => update({ sessionToken: v })} />
+                    <Field label="Region" value={config.region} placeholder="us-east-1" onChange={(v) => update({ region: v })} />
+                    <Field label="Service" value={config.service} placeholder="execute-api" onChange={(v) => update({ service: v })} />
+                </>
+            )}
+
+            {config.type === 'hmac' && (
+                <>
+                    <Field label="Key ID" value={config.keyId} onChange={(v) => update({ keyId: v })} />
+                    <Field label="Secret" value={config.secret} secret onChange={(v) => update({ secret: v })} />
+                    <div className="form-group">
+                        <label>Algorithm</label>
+                        <select className="input-select" value={config.algorithm || 'sha256'} onChange={(e) => update({ algorithm: e.target.value })}>
+                            <option value="sha256">HMAC-SHA256</option>
+                            <option value="sha512">HMAC-SHA512</option>
+                            <option value="sha1">HMAC-SHA1</option>
+                        </select>
+                    </div>
+                    <Field
+                        label="String to Sign"
+                        value={config.stringToSign}
+                        placeholder="{method}\n{path}\n{timestamp}\n{body_sha256}"
+                        onChange={(v) => update({ stringToSign: v })}
+                    />
+                    <Field label="Signature Header" value={config.signatureHeader} placeholder="Authorization" onChange={(v) => update({ signatureHeader: v })} />
+                    <Field label="Signature Format" value={config.signatureFormat} placeholder="HMAC {key_id}:{signature}" onChange={(v) => update({ signatureFormat: v })} />
+                    <div className="form-group">
+                        <label>Encoding</label>
+                        <select className="input-select" value={config.encoding || 'base64'} onChange={(e) => update({ encoding: e.target.value as any })}>
+                            <option value="base64">Base64</option>
+                            <option value="hex">Hex</option>
+                        </select>
+                    </div>
+                    <Field label="Timestamp Header" value={config.timestampHeader} placeholder="Optional, e.g. X-Timestamp" onChange={(v) => update({ timestampHeader: v })} />
+                    <Field label="Nonce Header" value={config.nonceHeader} placeholder="Optional, e.g. X-Nonce" onChange={(v) => update({ nonceHeader: v })} />
+                </>
+            )}
+
+            {config.type === 'digest' && (
+                <>
+                    <Field label="Username" value={config.username} onChange={(v) => update({ username: v })} />
+                    <Field label="Password" value={config.password} secret onChange={(v) => update({ password: v })} />
+                </>
+            )}
+
+            {config.type === 'hawk' && (
+                <>
+                    <Field label="Hawk ID" value={config.hawkId} onChange={(v) => update({ hawkId: v })} />
+                    <Field label="Hawk Key" value={config.hawkKey} secret onChange={(v) => update({ hawkKey: v })} />
+                    <div className="form-group">
+                        <label>Algorithm</label>
+                        <select className="input-select" value={config.algorithm || 'sha256'} onChange={(e) => update({ algorithm: e.target.value })}>
+                            <option value="sha256">sha256</option>
+                            <option value="sha1">sha1</option>
+                        </select>
+                    </div>
+                    <Field label="Ext" value={config.ext} placeholder="Optional" onChange={(v) => update({ ext: v })} />
+                </>
+            )}
+        </div>
+    );
+}
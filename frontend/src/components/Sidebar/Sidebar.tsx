@@ -1,5 +1,5 @@
 import { EndpointDef, Collection } from "../../types";
-import { Folder, FileText, ChevronRight, ChevronDown, History, Clock, Trash2, Database, Globe, FileUp, Download } from "lucide-react";
+import { Folder, FileText, ChevronRight, ChevronDown, History, Clock, Trash2, Database, Globe, FileUp, Download, Braces } from "lucide-react";
 import { useState } from "react";
 
 interface SidebarProps {
@@ -8,10 +8,11 @@ interface SidebarProps {
     historyItems?: any[];
     onSelect: (endpoint: EndpointDef) => void;
     activeEndpoint: EndpointDef | null;
-    onLoadSpec: (path: string) => void;
+    onLoadSpec: (path: string, authHeader: string) => void;
     onDeleteCollection?: (name: string) => void;
     onImportCollection?: () => void;
     onExportCollection?: (name: string) => void;
+    onEditCollectionVariables?: (name: string) => void;
     onExportHistory?: () => void;
     onDeleteHistory?: (id: number) => void;
     onDeleteAllHistory?: () => void;
@@ -26,6 +27,7 @@ export function Sidebar({
     onDeleteCollection,
     onImportCollection,
     onExportCollection,
+    onEditCollectionVariables,
     onExportHistory,
     onDeleteHistory,
     onDeleteAllHistory,
@@ -36,10 +38,12 @@ export function Sidebar({
     const [activeTab, setActiveTab] = useState<'collections' | 'history'>('collections');
     const [expandedTags, setExpandedTags] = useState<Record<string, boolean>>({});
     const [searchTerm, setSearchTerm] = useState("");
+    const [specPath, setSpecPath] = useState("");
+    const [specAuthHeader, setSpecAuthHeader] = useState("");
 
     const handleKeyDown = (e: React.KeyboardEvent<HTMLInputElement>) => {
         if (e.key === 'Enter') {
-            onLoadSpec((e.target as HTMLInputElement).value);
+            onLoadSpec(specPath, specAuthHeader);
         }
     };
 
@@ -72,9 +76,21 @@ export function Sidebar({
                 <input
                     type="text"
                     className="sidebar-input"
-                    placeholder="Path to OpenAPI Spec (Enter)"
+                    placeholder="Path or URL of OpenAPI Spec (Enter)"
+                    value={specPath}
+                    onChange={(e) => setSpecPath(e.target.value)}
                     onKeyDown={handleKeyDown}
                 />
+                {/^https?:\/\//i.test(specPath) && (
+                    <input
+                        type="text"
+                        className="sidebar-input"
+                        placeholder="Auth header for spec URL, e.g. Authorization: Bearer ..."
+                        value={specAuthHeader}
+                        onChange={(e) => setSpecAuthHeader(e.target.value)}
+                        onKeyDown={handleKeyDown}
+                    />
+                )}
                 <div className="search-input-wrapper">
                     <input
                         type="text"
@@ -144,6 +160,18 @@ export function Sidebar({
                                             </div>
                                             <div className="collection-actions">
                                                 <span className="count-badge">{col.requests.length}</span>
+                                                {onEditCollectionVariables && (
+                                                    <button
+                                                        className="btn-icon sm"
+                                                        onClick={(e) => {
+                                                            e.stopPropagation();
+                                                            onEditCollectionVariables(col.name);
+                                                        }}
+                                                        title="Collection Variables"
+                                                    >
+                                                        <Braces size={12} />
+                                                    </button>
+                                                )}
                                                 {onExportCollection && (
                                                     <button
                                                         className="btn-icon sm"
@@ -181,7 +209,8 @@ export function Sidebar({
                                                             path: req.url,
                                                             summary: `Saved Request ${i + 1}`,
                                                             description: '',
-                                                            tags: [col.name]
+                                                            tags: [col.name],
+                                                            collection: col.name
                                                         });
                                                     }}>
                                                         <span className={`method-badge ${req.method.toLowerCase()}`}>{req.method}</span>
@@ -0,0 +1,119 @@
+import { useEffect, useState } from "react";
+import { X, Braces, Save } from "lucide-react";
+import { ResolveVariables, SaveCollectionVariables } from "../../../wailsjs/go/main/App";
+import { variables } from "../../../wailsjs/go/models";
+import { KeyValueEditor } from "../Request/KeyValueEditor";
+import { Collection, KeyValueItem } from "../../types";
+
+interface VariablesModalProps {
+    isOpen: boolean;
+    onClose: () => void;
+    environment: string;
+    collection: Collection | null;
+    onSaved: () => void;
+}
+
+const toItems = (vars?: Record<string, string>): KeyValueItem[] => [
+    ...Object.entries(vars || {}).map(([key, value]) => ({ id: crypto.randomUUID(), key, value, enabled: true })),
+    { id: crypto.randomUUID(), key: '', value: '', enabled: true },
+];
+
+const displayValue = (v: variables.Resolved) => {
+    if (v.locked) return "locked — unlock the vault to use it";
+    if (v.secret) return "••••••••";
+    return v.value;
+};
+
+export function VariablesModal({ isOpen, onClose, environment, collection, onSaved }: VariablesModalProps) {
+    const [items, setItems] = useState<KeyValueItem[]>([]);
+    const [resolved, setResolved] = useState<variables.Resolved[]>([]);
+    const [error, setError] = useState<string | null>(null);
+
+    const resolve = async () => {
+        try {
+            setResolved((await ResolveVariables(environment, collection?.name || "")) || []);
+            setError(null);
+        } catch (err: any) {
+            setResolved([]);
+            setError(err.toString());
+        }
+    };
+
+    useEffect(() => {
+        if (!isOpen) return;
+        setItems(toItems(collection?.variables));
+        resolve();
+    }, [isOpen, environment, collection]);
+
+    if (!isOpen) return null;
+
+    const handleSave = async () => {
+        if (!collection) return;
+        const vars: Record<string, string> = {};
+        items.forEach(i => {
+            if (i.enabled && i.key) vars[i.key] = i.value;
+        });
+        try {
+            await SaveCollectionVariables(collection.name, vars);
+            onSaved();
+            await resolve();
+        } catch (err: any) {
+            setError(err.toString());
+        }
+    };
+
+    return (
+        <div className="modal-overlay">
+            <div className="modal-content">
+                <div className="modal-header">
+                    <div className="title-group">
+                        <Braces size={20} className="icon" />
+                        <h3>Variables</h3>
+                    </div>
+                    <button className="close-btn" onClick={onClose}>
+                        <X size={20} />
+                    </button>
+                </div>
+
+                <div className="modal-body">
+                    {collection && (
+                        <div className="form-group">
+                            <label>Collection variables of {collection.name}</label>
+                            <KeyValueEditor items={items} onChange={setItems} />
+                            <button className="btn btn-primary mt-4" onClick={handleSave}>
+                                <Save size={14} /> Save Variables
+                            </button>
+                        </div>
+                    )}
+
+                    {error && <div className="error-message">{error}</div>}
+
+                    <div className="form-group">
+                        <label>
+                            Resolved for {environment ? `environment ${environment}` : "no environment"}
+                            {collection ? ` and collection ${collection.name}` : ""}
+                        </label>
+                        {resolved.length === 0 ? (
+                            <p className="text-muted">No variables defined</p>
+                        ) : (
+                            <div className="resolved-table">
+                                <div className="resolved-row header">
+                                    <span>Name</span>
+                                    <span>Value</span>
+                                    <span>Source</span>
+                                </div>
+                                {resolved.map(v => (
+                                    <div key={v.name} className={`resolved-row ${v.locked ? 'locked' : ''}`}>
+                                        <span>{`{{${v.name}}}`}</span>
+                                        <span>{displayValue(v)}</span>
+                                        <span className="text-muted">{v.source}</span>
+                                    </div>
+                                ))}
+                            </div>
+                        )}
+                    </div>
+                </div>
+            </div>
+        </div>
+    );
+}
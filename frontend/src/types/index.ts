@@ -4,6 +4,15 @@ export interface EndpointDef {
     summary: string;
     description: string;
     tags: string[];
+    parameters?: ParameterDef[]; // path-level and operation parameters
+    collection?: string; // set for requests saved in a collection
+}
+
+export interface ParameterDef {
+    name: string;
+    in: 'path' | 'query' | 'header' | 'cookie';
+    description?: string;
+    required: boolean;
 }
 
 export interface SpecDetails {
@@ -17,12 +26,19 @@ export interface FormDataPart {
 }
 
 export interface RequestData {
+    name?: string;
+    folder?: string;
     method: string;
     url: string;
     headers: Record<string, string>;
     body: string;
     formData?: Record<string, FormDataPart>;
     timeout: number;
+    variables?: Record<string, string>;
+    environment?: string;
+    collection?: string;
+    signing?: SigningConfig;
+    profile?: string;
 }
 
 export interface ResponseData {
@@ -36,6 +52,7 @@ export interface ResponseData {
 export interface Collection {
     name: string;
     requests: RequestData[];
+    variables?: Record<string, string>;
 }
 
 export interface KeyValueItem {
@@ -47,8 +64,33 @@ export interface KeyValueItem {
     isFile?: boolean;
 }
 
+export type SigningType = 'aws_sigv4' | 'hmac' | 'digest' | 'hawk';
+
+export interface SigningConfig {
+    type: SigningType;
+    accessKey?: string;
+    secretKey?: string;
+    sessionToken?: string;
+    region?: string;
+    service?: string;
+    keyId?: string;
+    secret?: string;
+    algorithm?: string;
+    stringToSign?: string;
+    signatureHeader?: string;
+    signatureFormat?: string;
+    encoding?: 'base64' | 'hex';
+    timestampHeader?: string;
+    nonceHeader?: string;
+    username?: string;
+    password?: string;
+    hawkId?: string;
+    hawkKey?: string;
+    ext?: string;
+}
+
 export interface AuthConfig {
-    type: 'none' | 'bearer' | 'basic' | 'api_key' | 'oauth' | 'oauth2';
+    type: 'none' | 'bearer' | 'basic' | 'api_key' | 'oauth' | 'oauth2' | 'profile' | SigningType;
     bearerToken?: string;
     basicUsername?: string;
     basicPassword?: string;
@@ -57,6 +99,8 @@ export interface AuthConfig {
     apiKeyIn?: 'header' | 'query';
     oauthToken?: string;
     oauth2Token?: string; // Legacy
+    signing?: SigningConfig;
+    profile?: string;
 
     // Comprehensive OAuth2 fields
     oauth2Config?: {
@@ -67,22 +111,38 @@ export interface AuthConfig {
 
         tokenName?: string;
         grantType?: string;
+        issuer?: string;
         callbackUrl?: string;
         authUrl?: string;
         accessTokenUrl?: string;
+        deviceAuthUrl?: string;
+        introspectionUrl?: string;
+        revocationUrl?: string;
         clientId?: string;
         clientSecret?: string;
         scope?: string;
-        state?: string;
+        flowTimeout?: number;
+        username?: string;
+        password?: string;
         clientAuth?: 'basic' | 'body';
     }
 }
 
+export interface CredentialProfile {
+    name: string;
+    oauth2_config: string;
+    access_token: string;
+    refresh_token: string;
+    id_token: string;
+    expires_at: string;
+}
+
 export interface Environment {
     name: string;
     base_url: string;
     access_token: string;
     refresh_token: string;
+    id_token: string;
     expires_at: string;
     auth_url: string;
     token_url: string;
@@ -95,4 +155,8 @@ export interface Environment {
     last_used: string;
     oauth2Config?: AuthConfig['oauth2Config'];
     oauth2_config: string;
+    parent: string;
+    secret_variables: string[];
+    profiles: CredentialProfile[];
+    locked: boolean;
 }
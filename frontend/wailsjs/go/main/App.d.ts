@@ -1,9 +1,20 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {jwt} from '../models';
+import {oauth} from '../models';
 import {pkg} from '../models';
 import {generator} from '../models';
 import {db} from '../models';
+import {secrets} from '../models';
+import {variables} from '../models';
 import {frontend} from '../models';
+import {filesync} from '../models';
+
+export function CancelOAuthFlow():Promise<void>;
+
+export function CreateWorkspace(arg1:string):Promise<void>;
+
+export function DecodeJWT(arg1:string):Promise<jwt.Info>;
 
 export function DeleteCollection(arg1:string):Promise<void>;
 
@@ -13,30 +24,60 @@ export function DeleteHistory():Promise<void>;
 
 export function DeleteHistoryItem(arg1:number):Promise<void>;
 
+export function DiscoverOIDC(arg1:string):Promise<oauth.ProviderMetadata>;
+
 export function ExecuteRequest(arg1:pkg.RequestData):Promise<pkg.ResponseData>;
 
 export function ExportCollection(arg1:string,arg2:string):Promise<void>;
 
+export function ExportEnvironment(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
 export function ExportHistory(arg1:string):Promise<void>;
 
-export function Generate(arg1:string,arg2:string,arg3:string):Promise<void>;
+export function FindJWTs(arg1:string):Promise<Array<jwt.Info>>;
+
+export function Generate(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
 
 export function GetAuthInfo(arg1:string):Promise<Array<generator.AuthScheme>>;
 
+export function GetCurrentWorkspace():Promise<string>;
+
+export function GetDataDirectory():Promise<string>;
+
 export function GetEnvironments():Promise<Array<db.Environment>>;
 
+export function GetIdentity(arg1:string,arg2:string):Promise<oauth.Identity>;
+
+export function GetVaultStatus():Promise<secrets.VaultStatus>;
+
 export function ImportCollections(arg1:string):Promise<db.PostmanCollection>;
 
+export function ImportEnvironment(arg1:string,arg2:string,arg3:string):Promise<db.Environment>;
+
+export function InspectToken(arg1:string,arg2:string,arg3:string):Promise<Record<string, any>>;
+
+export function ListWorkspaces():Promise<Array<string>>;
+
 export function LoadCollection():Promise<Array<db.Collection>>;
 
 export function LoadHistory():Promise<Array<db.HistoryRecord>>;
 
+export function LockVault():Promise<void>;
+
+export function Logout(arg1:string,arg2:string):Promise<db.Environment>;
+
+export function MintJWT(arg1:string,arg2:string,arg3:jwt.MintOptions,arg4:string):Promise<string>;
+
 export function ParseSpecDetails(arg1:string):Promise<pkg.SpecDetails>;
 
-export function PerformOAuthFlow(arg1:db.Environment):Promise<db.Environment>;
+export function PerformOAuthFlow(arg1:db.Environment,arg2:string):Promise<db.Environment>;
+
+export function ResolveVariables(arg1:string,arg2:string):Promise<Array<variables.Resolved>>;
 
 export function SaveCollection(arg1:string,arg2:Array<pkg.RequestData>):Promise<void>;
 
+export function SaveCollectionVariables(arg1:string,arg2:Record<string, string>):Promise<void>;
+
 export function SaveEnvironment(arg1:db.Environment):Promise<void>;
 
 export function SaveFileDialog(arg1:string,arg2:string,arg3:Array<frontend.FileFilter>):Promise<string>;
@@ -47,6 +88,18 @@ export function SelectDirectory():Promise<string>;
 
 export function SelectFile():Promise<string>;
 
+export function SetSpecHeaders(arg1:Array<string>):Promise<void>;
+
+export function SetVaultPassphrase(arg1:string):Promise<void>;
+
+export function SwitchWorkspace(arg1:string):Promise<void>;
+
+export function SyncWorkspace(arg1:string):Promise<filesync.Report>;
+
+export function UnlockVault(arg1:string):Promise<void>;
+
 export function UploadFile(arg1:string):Promise<Array<number>>;
 
 export function ValidateSpec(arg1:string):Promise<boolean>;
+
+export function VerifyJWT(arg1:string,arg2:string,arg3:string):Promise<jwt.VerifyResult>;
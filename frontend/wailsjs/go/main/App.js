@@ -2,6 +2,18 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CancelOAuthFlow() {
+  return window['go']['main']['App']['CancelOAuthFlow']();
+}
+
+export function CreateWorkspace(arg1) {
+  return window['go']['main']['App']['CreateWorkspace'](arg1);
+}
+
+export function DecodeJWT(arg1) {
+  return window['go']['main']['App']['DecodeJWT'](arg1);
+}
+
 export function DeleteCollection(arg1) {
   return window['go']['main']['App']['DeleteCollection'](arg1);
 }
@@ -18,6 +30,10 @@ export function DeleteHistoryItem(arg1) {
   return window['go']['main']['App']['DeleteHistoryItem'](arg1);
 }
 
+export function DiscoverOIDC(arg1) {
+  return window['go']['main']['App']['DiscoverOIDC'](arg1);
+}
+
 export function ExecuteRequest(arg1) {
   return window['go']['main']['App']['ExecuteRequest'](arg1);
 }
@@ -26,26 +42,62 @@ export function ExportCollection(arg1, arg2) {
   return window['go']['main']['App']['ExportCollection'](arg1, arg2);
 }
 
+export function ExportEnvironment(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportEnvironment'](arg1, arg2, arg3, arg4);
+}
+
 export function ExportHistory(arg1) {
   return window['go']['main']['App']['ExportHistory'](arg1);
 }
 
-export function Generate(arg1, arg2, arg3) {
-  return window['go']['main']['App']['Generate'](arg1, arg2, arg3);
+export function FindJWTs(arg1) {
+  return window['go']['main']['App']['FindJWTs'](arg1);
+}
+
+export function Generate(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['Generate'](arg1, arg2, arg3, arg4);
 }
 
 export function GetAuthInfo(arg1) {
   return window['go']['main']['App']['GetAuthInfo'](arg1);
 }
 
+export function GetCurrentWorkspace() {
+  return window['go']['main']['App']['GetCurrentWorkspace']();
+}
+
+export function GetDataDirectory() {
+  return window['go']['main']['App']['GetDataDirectory']();
+}
+
 export function GetEnvironments() {
   return window['go']['main']['App']['GetEnvironments']();
 }
 
+export function GetIdentity(arg1, arg2) {
+  return window['go']['main']['App']['GetIdentity'](arg1, arg2);
+}
+
+export function GetVaultStatus() {
+  return window['go']['main']['App']['GetVaultStatus']();
+}
+
 export function ImportCollections(arg1) {
   return window['go']['main']['App']['ImportCollections'](arg1);
 }
 
+export function ImportEnvironment(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ImportEnvironment'](arg1, arg2, arg3);
+}
+
+export function InspectToken(arg1, arg2, arg3) {
+  return window['go']['main']['App']['InspectToken'](arg1, arg2, arg3);
+}
+
+export function ListWorkspaces() {
+  return window['go']['main']['App']['ListWorkspaces']();
+}
+
 export function LoadCollection() {
   return window['go']['main']['App']['LoadCollection']();
 }
@@ -54,18 +106,38 @@ export function LoadHistory() {
   return window['go']['main']['App']['LoadHistory']();
 }
 
+export function LockVault() {
+  return window['go']['main']['App']['LockVault']();
+}
+
+export function Logout(arg1, arg2) {
+  return window['go']['main']['App']['Logout'](arg1, arg2);
+}
+
+export function MintJWT(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['MintJWT'](arg1, arg2, arg3, arg4);
+}
+
 export function ParseSpecDetails(arg1) {
   return window['go']['main']['App']['ParseSpecDetails'](arg1);
 }
 
-export function PerformOAuthFlow(arg1) {
-  return window['go']['main']['App']['PerformOAuthFlow'](arg1);
+export function PerformOAuthFlow(arg1, arg2) {
+  return window['go']['main']['App']['PerformOAuthFlow'](arg1, arg2);
+}
+
+export function ResolveVariables(arg1, arg2) {
+  return window['go']['main']['App']['ResolveVariables'](arg1, arg2);
 }
 
 export function SaveCollection(arg1, arg2) {
   return window['go']['main']['App']['SaveCollection'](arg1, arg2);
 }
 
+export function SaveCollectionVariables(arg1, arg2) {
+  return window['go']['main']['App']['SaveCollectionVariables'](arg1, arg2);
+}
+
 export function SaveEnvironment(arg1) {
   return window['go']['main']['App']['SaveEnvironment'](arg1);
 }
@@ -86,6 +158,26 @@ export function SelectFile() {
   return window['go']['main']['App']['SelectFile']();
 }
 
+export function SetSpecHeaders(arg1) {
+  return window['go']['main']['App']['SetSpecHeaders'](arg1);
+}
+
+export function SetVaultPassphrase(arg1) {
+  return window['go']['main']['App']['SetVaultPassphrase'](arg1);
+}
+
+export function SwitchWorkspace(arg1) {
+  return window['go']['main']['App']['SwitchWorkspace'](arg1);
+}
+
+export function SyncWorkspace(arg1) {
+  return window['go']['main']['App']['SyncWorkspace'](arg1);
+}
+
+export function UnlockVault(arg1) {
+  return window['go']['main']['App']['UnlockVault'](arg1);
+}
+
 export function UploadFile(arg1) {
   return window['go']['main']['App']['UploadFile'](arg1);
 }
@@ -93,3 +185,7 @@ export function UploadFile(arg1) {
 export function ValidateSpec(arg1) {
   return window['go']['main']['App']['ValidateSpec'](arg1);
 }
+
+export function VerifyJWT(arg1, arg2, arg3) {
+  return window['go']['main']['App']['VerifyJWT'](arg1, arg2, arg3);
+}
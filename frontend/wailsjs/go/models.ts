@@ -17,6 +17,7 @@ export namespace db {
 	export class Collection {
 	    name: string;
 	    requests: pkg.RequestData[];
+	    variables: Record<string, string>;
 	
 	    static createFrom(source: any = {}) {
 	        return new Collection(source);
@@ -26,6 +27,7 @@ export namespace db {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.name = source["name"];
 	        this.requests = this.convertValues(source["requests"], pkg.RequestData);
+	        this.variables = source["variables"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -46,11 +48,34 @@ export namespace db {
 		    return a;
 		}
 	}
+	export class CredentialProfile {
+	    name: string;
+	    oauth2_config: string;
+	    access_token: string;
+	    refresh_token: string;
+	    id_token: string;
+	    expires_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CredentialProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.oauth2_config = source["oauth2_config"];
+	        this.access_token = source["access_token"];
+	        this.refresh_token = source["refresh_token"];
+	        this.id_token = source["id_token"];
+	        this.expires_at = source["expires_at"];
+	    }
+	}
 	export class Environment {
 	    name: string;
 	    base_url: string;
 	    access_token: string;
 	    refresh_token: string;
+	    id_token: string;
 	    expires_at: string;
 	    auth_url: string;
 	    token_url: string;
@@ -62,6 +87,10 @@ export namespace db {
 	    created_at: string;
 	    last_used: string;
 	    oauth2_config: string;
+	    parent: string;
+	    secret_variables: string[];
+	    profiles: CredentialProfile[];
+	    locked: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new Environment(source);
@@ -73,6 +102,7 @@ export namespace db {
 	        this.base_url = source["base_url"];
 	        this.access_token = source["access_token"];
 	        this.refresh_token = source["refresh_token"];
+	        this.id_token = source["id_token"];
 	        this.expires_at = source["expires_at"];
 	        this.auth_url = source["auth_url"];
 	        this.token_url = source["token_url"];
@@ -84,7 +114,29 @@ export namespace db {
 	        this.created_at = source["created_at"];
 	        this.last_used = source["last_used"];
 	        this.oauth2_config = source["oauth2_config"];
+	        this.parent = source["parent"];
+	        this.secret_variables = source["secret_variables"];
+	        this.profiles = this.convertValues(source["profiles"], CredentialProfile);
+	        this.locked = source["locked"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class HistoryRecord {
 	    id: number;
@@ -257,6 +309,61 @@ export namespace db {
 
 }
 
+export namespace filesync {
+	
+	export class Conflict {
+	    path: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Conflict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class Report {
+	    exported: string[];
+	    imported: string[];
+	    deleted: string[];
+	    conflicts: Conflict[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.exported = source["exported"];
+	        this.imported = source["imported"];
+	        this.deleted = source["deleted"];
+	        this.conflicts = this.convertValues(source["conflicts"], Conflict);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace frontend {
 	
 	export class FileFilter {
@@ -278,12 +385,36 @@ export namespace frontend {
 
 export namespace generator {
 	
+	export class OAuthFlow {
+	    Grant: string;
+	    AuthURL: string;
+	    TokenURL: string;
+	    RefreshURL: string;
+	    DeviceAuthURL: string;
+	    Scopes: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new OAuthFlow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Grant = source["Grant"];
+	        this.AuthURL = source["AuthURL"];
+	        this.TokenURL = source["TokenURL"];
+	        this.RefreshURL = source["RefreshURL"];
+	        this.DeviceAuthURL = source["DeviceAuthURL"];
+	        this.Scopes = source["Scopes"];
+	    }
+	}
 	export class AuthScheme {
 	    Name: string;
 	    Type: string;
 	    Scheme: string;
 	    In: string;
 	    HeaderName: string;
+	    Issuer: string;
+	    Flows: OAuthFlow[];
 	
 	    static createFrom(source: any = {}) {
 	        return new AuthScheme(source);
@@ -296,6 +427,232 @@ export namespace generator {
 	        this.Scheme = source["Scheme"];
 	        this.In = source["In"];
 	        this.HeaderName = source["HeaderName"];
+	        this.Issuer = source["Issuer"];
+	        this.Flows = this.convertValues(source["Flows"], OAuthFlow);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace jwt {
+	
+	export class Header {
+	    alg: string;
+	    typ?: string;
+	    kid?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Header(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.alg = source["alg"];
+	        this.typ = source["typ"];
+	        this.kid = source["kid"];
+	    }
+	}
+	export class Info {
+	    raw: string;
+	    header: Header;
+	    claims: Record<string, any>;
+	    expiresAt?: string;
+	    expiresIn: number;
+	    expired: boolean;
+	    hasExpiry: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Info(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.raw = source["raw"];
+	        this.header = this.convertValues(source["header"], Header);
+	        this.claims = source["claims"];
+	        this.expiresAt = source["expiresAt"];
+	        this.expiresIn = source["expiresIn"];
+	        this.expired = source["expired"];
+	        this.hasExpiry = source["hasExpiry"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MintOptions {
+	    alg: string;
+	    kid?: string;
+	    claims: Record<string, any>;
+	    expiresIn: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MintOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.alg = source["alg"];
+	        this.kid = source["kid"];
+	        this.claims = source["claims"];
+	        this.expiresIn = source["expiresIn"];
+	    }
+	}
+	export class Token {
+	    raw: string;
+	    header: Header;
+	    claims: Record<string, any>;
+	
+	    static createFrom(source: any = {}) {
+	        return new Token(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.raw = source["raw"];
+	        this.header = this.convertValues(source["header"], Header);
+	        this.claims = source["claims"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class VerifyResult {
+	    valid: boolean;
+	    signatureValid: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new VerifyResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.valid = source["valid"];
+	        this.signatureValid = source["signatureValid"];
+	        this.error = source["error"];
+	    }
+	}
+
+}
+
+export namespace oauth {
+	
+	export class Identity {
+	    idToken?: jwt.Token;
+	    verified: boolean;
+	    verifyError?: string;
+	    userInfo?: Record<string, any>;
+	    userInfoError?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Identity(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.idToken = this.convertValues(source["idToken"], jwt.Token);
+	        this.verified = source["verified"];
+	        this.verifyError = source["verifyError"];
+	        this.userInfo = source["userInfo"];
+	        this.userInfoError = source["userInfoError"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProviderMetadata {
+	    issuer: string;
+	    authorization_endpoint: string;
+	    token_endpoint: string;
+	    userinfo_endpoint?: string;
+	    jwks_uri?: string;
+	    device_authorization_endpoint?: string;
+	    introspection_endpoint?: string;
+	    revocation_endpoint?: string;
+	    end_session_endpoint?: string;
+	    scopes_supported?: string[];
+	    grant_types_supported?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProviderMetadata(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.issuer = source["issuer"];
+	        this.authorization_endpoint = source["authorization_endpoint"];
+	        this.token_endpoint = source["token_endpoint"];
+	        this.userinfo_endpoint = source["userinfo_endpoint"];
+	        this.jwks_uri = source["jwks_uri"];
+	        this.device_authorization_endpoint = source["device_authorization_endpoint"];
+	        this.introspection_endpoint = source["introspection_endpoint"];
+	        this.revocation_endpoint = source["revocation_endpoint"];
+	        this.end_session_endpoint = source["end_session_endpoint"];
+	        this.scopes_supported = source["scopes_supported"];
+	        this.grant_types_supported = source["grant_types_supported"];
 	    }
 	}
 
@@ -303,12 +660,31 @@ export namespace generator {
 
 export namespace pkg {
 	
+	export class ParameterDef {
+	    name: string;
+	    in: string;
+	    description?: string;
+	    required: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ParameterDef(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.in = source["in"];
+	        this.description = source["description"];
+	        this.required = source["required"];
+	    }
+	}
 	export class EndpointDef {
 	    method: string;
 	    path: string;
 	    summary: string;
 	    description: string;
 	    tags: string[];
+	    parameters?: ParameterDef[];
 	
 	    static createFrom(source: any = {}) {
 	        return new EndpointDef(source);
@@ -321,7 +697,26 @@ export namespace pkg {
 	        this.summary = source["summary"];
 	        this.description = source["description"];
 	        this.tags = source["tags"];
+	        this.parameters = this.convertValues(source["parameters"], ParameterDef);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class FormDataPart {
 	    value: string;
@@ -337,13 +732,21 @@ export namespace pkg {
 	        this.isFile = source["isFile"];
 	    }
 	}
+	
 	export class RequestData {
+	    name?: string;
+	    folder?: string;
 	    method: string;
 	    url: string;
 	    headers: Record<string, string>;
 	    body: string;
 	    formData: Record<string, FormDataPart>;
 	    timeout: number;
+	    variables?: Record<string, string>;
+	    environment?: string;
+	    collection?: string;
+	    profile?: string;
+	    signing?: signing.Config;
 	
 	    static createFrom(source: any = {}) {
 	        return new RequestData(source);
@@ -351,12 +754,19 @@ export namespace pkg {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.folder = source["folder"];
 	        this.method = source["method"];
 	        this.url = source["url"];
 	        this.headers = source["headers"];
 	        this.body = source["body"];
 	        this.formData = this.convertValues(source["formData"], FormDataPart, true);
 	        this.timeout = source["timeout"];
+	        this.variables = source["variables"];
+	        this.environment = source["environment"];
+	        this.collection = source["collection"];
+	        this.profile = source["profile"];
+	        this.signing = this.convertValues(source["signing"], signing.Config);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -432,3 +842,102 @@ export namespace pkg {
 
 }
 
+export namespace secrets {
+	
+	export class VaultStatus {
+	    mode: string;
+	    locked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new VaultStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.locked = source["locked"];
+	    }
+	}
+
+}
+
+export namespace signing {
+	
+	export class Config {
+	    type: string;
+	    accessKey?: string;
+	    secretKey?: string;
+	    sessionToken?: string;
+	    region?: string;
+	    service?: string;
+	    keyId?: string;
+	    secret?: string;
+	    algorithm?: string;
+	    stringToSign?: string;
+	    signatureHeader?: string;
+	    signatureFormat?: string;
+	    encoding?: string;
+	    timestampHeader?: string;
+	    nonceHeader?: string;
+	    username?: string;
+	    password?: string;
+	    hawkId?: string;
+	    hawkKey?: string;
+	    ext?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Config(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.accessKey = source["accessKey"];
+	        this.secretKey = source["secretKey"];
+	        this.sessionToken = source["sessionToken"];
+	        this.region = source["region"];
+	        this.service = source["service"];
+	        this.keyId = source["keyId"];
+	        this.secret = source["secret"];
+	        this.algorithm = source["algorithm"];
+	        this.stringToSign = source["stringToSign"];
+	        this.signatureHeader = source["signatureHeader"];
+	        this.signatureFormat = source["signatureFormat"];
+	        this.encoding = source["encoding"];
+	        this.timestampHeader = source["timestampHeader"];
+	        this.nonceHeader = source["nonceHeader"];
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.hawkId = source["hawkId"];
+	        this.hawkKey = source["hawkKey"];
+	        this.ext = source["ext"];
+	    }
+	}
+
+}
+
+export namespace variables {
+	
+	export class Resolved {
+	    name: string;
+	    value: string;
+	    source: string;
+	    secret: boolean;
+	    locked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Resolved(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.value = source["value"];
+	        this.source = source["source"];
+	        this.secret = source["secret"];
+	        this.locked = source["locked"];
+	    }
+	}
+
+}
+
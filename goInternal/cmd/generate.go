@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	generator "CommandPost/goInternal/pkg/generator"
+	"CommandPost/goInternal/pkg/spec"
 
 	"github.com/spf13/cobra"
 )
@@ -37,6 +39,22 @@ var generateCmd = &cobra.Command{
 		}
 
 		gen := generator.NewGenerator()
+		gen.Loader = spec.NewLoader()
+		specHeaders, _ := cmd.Flags().GetStringArray("spec-header")
+		if gen.Loader.Headers, err = spec.ParseHeaders(specHeaders); err != nil {
+			return err
+		}
+		if gen.Loader.Timeout, err = cmd.Flags().GetDuration("spec-timeout"); err != nil {
+			return err
+		}
+		if noCache, _ := cmd.Flags().GetBool("no-spec-cache"); noCache {
+			gen.Loader.CacheDir = ""
+		}
+		if configPath, _ := cmd.Flags().GetString("config"); configPath != "" {
+			if gen.Config, err = generator.LoadConfig(configPath); err != nil {
+				return err
+			}
+		}
 		return gen.Generate(specPath, outputDir, moduleName)
 	},
 }
@@ -44,10 +62,14 @@ var generateCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(generateCmd)
 
-	generateCmd.Flags().StringP("spec", "s", "", "Path to the OpenAPI specification file (required)")
+	generateCmd.Flags().StringP("spec", "s", "", "Path or URL of the OpenAPI specification (required)")
 	generateCmd.MarkFlagRequired("spec")
 	generateCmd.Flags().StringP("out", "o", "./cli-out", "Output directory for the generated code")
 	generateCmd.Flags().StringP("module", "m", "", "Module name for the generated code")
 	generateCmd.MarkFlagRequired("module")
+	generateCmd.Flags().StringP("config", "c", "", "Generator config file (YAML or JSON) with command name overrides")
+	generateCmd.Flags().StringArray("spec-header", nil, "Header sent when fetching the spec from a URL, as \"Name: value\" (repeatable)")
+	generateCmd.Flags().Duration("spec-timeout", 30*time.Second, "Timeout for each request made while fetching the spec")
+	generateCmd.Flags().Bool("no-spec-cache", false, "Always download remote specs instead of revalidating the cached copy")
 	generateCmd.Flags().BoolVar(&installCompletion, "install-completion", false, "install a completion file to /etc/bash_completion.d/<module>")
 }
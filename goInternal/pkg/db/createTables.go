@@ -6,11 +6,16 @@ func CreateCollectionsTable(db *sql.DB) error {
 	if _, err := db.Exec(`
 		CREATE TABLE IF NOT EXISTS collections (
 			name TEXT PRIMARY KEY,
-			requests TEXT
+			requests TEXT,
+			variables TEXT
 		)
 	`); err != nil {
 		return err
 	}
+
+	// Migrations for existing tables
+	db.Exec("ALTER TABLE collections ADD COLUMN variables TEXT")
+
 	return nil
 }
 
@@ -59,6 +64,43 @@ func CreateEnvironmentsTable(db *sql.DB) error {
 	db.Exec("ALTER TABLE environments ADD COLUMN redirect_uri TEXT")
 	db.Exec("ALTER TABLE environments ADD COLUMN scope TEXT")
 	db.Exec("ALTER TABLE environments ADD COLUMN oauth2_config TEXT")
+	db.Exec("ALTER TABLE environments ADD COLUMN secret_variables TEXT")
+	db.Exec("ALTER TABLE environments ADD COLUMN parent TEXT")
+	db.Exec("ALTER TABLE environments ADD COLUMN id_token TEXT")
+	db.Exec("ALTER TABLE environments ADD COLUMN profiles TEXT")
+
+	// Older versions inserted a new row on every save; keep the latest one
+	// per name so saves can upsert on a unique name.
+	db.Exec("DELETE FROM environments WHERE id NOT IN (SELECT MAX(id) FROM environments GROUP BY name)")
+	if _, err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS environments_name ON environments(name)"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func CreateVaultTable(db *sql.DB) error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS vault (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			mode TEXT NOT NULL,
+			salt BLOB,
+			check_value TEXT NOT NULL
+		)
+	`); err != nil {
+		return err
+	}
+	return nil
+}
 
+func CreateSyncStateTable(db *sql.DB) error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS sync_state (
+			dir TEXT PRIMARY KEY,
+			files TEXT NOT NULL
+		)
+	`); err != nil {
+		return err
+	}
 	return nil
 }
@@ -6,9 +6,28 @@ import (
 
 func DbWorker(db *sql.DB, writeChan <-chan DbQuery) {
 	for query := range writeChan {
-		_, err := db.Exec(query.Query, query.Args...)
+		var err error
+		if query.Batch != nil {
+			err = execBatch(db, query.Batch)
+		} else {
+			_, err = db.Exec(query.Query, query.Args...)
+		}
 		if query.Result != nil {
 			query.Result <- err
 		}
 	}
 }
+
+func execBatch(db *sql.DB, batch []DbQuery) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, q := range batch {
+		if _, err := tx.Exec(q.Query, q.Args...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
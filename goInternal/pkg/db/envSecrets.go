@@ -0,0 +1,90 @@
+package db
+
+import "CommandPost/goInternal/pkg/secrets"
+
+func sealEnvironment(vault *secrets.Vault, env Environment) (Environment, error) {
+	sealed := env
+	fields := []*string{&sealed.AccessToken, &sealed.RefreshToken, &sealed.IDToken, &sealed.ClientSecret, &sealed.OAuth2Config}
+	for _, f := range fields {
+		v, err := vault.Seal(*f)
+		if err != nil {
+			return env, err
+		}
+		*f = v
+	}
+
+	sealed.Profiles = make([]CredentialProfile, len(env.Profiles))
+	for i, p := range env.Profiles {
+		for _, f := range p.secretFields() {
+			v, err := vault.Seal(*f)
+			if err != nil {
+				return env, err
+			}
+			*f = v
+		}
+		sealed.Profiles[i] = p
+	}
+
+	sealed.Variables = make(map[string]string, len(env.Variables))
+	for k, v := range env.Variables {
+		if env.IsSecretVariable(k) {
+			s, err := vault.Seal(v)
+			if err != nil {
+				return env, err
+			}
+			v = s
+		}
+		sealed.Variables[k] = v
+	}
+	return sealed, nil
+}
+
+func openEnvironment(vault *secrets.Vault, env *Environment) error {
+	if vault.Locked() {
+		env.AccessToken = ""
+		env.RefreshToken = ""
+		env.IDToken = ""
+		env.ClientSecret = ""
+		env.OAuth2Config = ""
+		for i := range env.Profiles {
+			for _, f := range env.Profiles[i].secretFields() {
+				*f = ""
+			}
+		}
+		for k := range env.Variables {
+			if env.IsSecretVariable(k) {
+				env.Variables[k] = ""
+			}
+		}
+		env.Locked = true
+		return nil
+	}
+
+	fields := []*string{&env.AccessToken, &env.RefreshToken, &env.IDToken, &env.ClientSecret, &env.OAuth2Config}
+	for _, f := range fields {
+		v, err := vault.Open(*f)
+		if err != nil {
+			return err
+		}
+		*f = v
+	}
+	for i := range env.Profiles {
+		for _, f := range env.Profiles[i].secretFields() {
+			v, err := vault.Open(*f)
+			if err != nil {
+				return err
+			}
+			*f = v
+		}
+	}
+	for k, v := range env.Variables {
+		if env.IsSecretVariable(k) {
+			opened, err := vault.Open(v)
+			if err != nil {
+				return err
+			}
+			env.Variables[k] = opened
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"CommandPost/goInternal/pkg/secrets"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+func ExportEnvironment(db *sql.DB, vault *secrets.Vault, name string, path string, format string, secretMode string) error {
+	env, ok, err := GetEnvironment(db, vault, name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("environment %s not found", name)
+	}
+	if env.Locked && secretMode == SecretsInclude {
+		return secrets.ErrLocked
+	}
+
+	data, err := renderEnvironment(env, format, secretMode)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func renderEnvironment(env Environment, format string, secretMode string) ([]byte, error) {
+	env = applySecretMode(env, secretMode)
+	keys := make([]string, 0, len(env.Variables))
+	for k := range env.Variables {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	switch format {
+	case EnvFormatJSON, "":
+		env.Locked = false
+		return json.MarshalIndent(env, "", "  ")
+	case EnvFormatPostman:
+		out := PostmanEnvironment{Name: env.Name, Values: []PostmanEnvironmentValue{}, Scope: "environment"}
+		enabled := true
+		for _, k := range keys {
+			valueType := "default"
+			if env.IsSecretVariable(k) {
+				valueType = "secret"
+			}
+			out.Values = append(out.Values, PostmanEnvironmentValue{Key: k, Value: env.Variables[k], Type: valueType, Enabled: &enabled})
+		}
+		return json.MarshalIndent(out, "", "  ")
+	case EnvFormatDotenv:
+		var b strings.Builder
+		for _, k := range keys {
+			b.WriteString(k + "=" + quoteDotenv(env.Variables[k]) + "\n")
+		}
+		return []byte(b.String()), nil
+	default:
+		return nil, fmt.Errorf("unknown environment format %q", format)
+	}
+}
+
+// applySecretMode strips or masks every secret field. Excluded secret
+// variables are dropped entirely, but stay listed in SecretVariables.
+func applySecretMode(env Environment, secretMode string) Environment {
+	if secretMode == SecretsInclude {
+		return env
+	}
+	replace := func(v string) string {
+		if secretMode == SecretsMask && v != "" {
+			return MaskedValue
+		}
+		return ""
+	}
+
+	env.ClientSecret = replace(env.ClientSecret)
+	for _, name := range append([]string{""}, profileNames(env)...) {
+		view, _ := env.WithProfile(name)
+		view.AccessToken = replace(view.AccessToken)
+		view.RefreshToken = replace(view.RefreshToken)
+		view.IDToken = replace(view.IDToken)
+
+		var config map[string]any
+		if view.OAuth2Config != "" && json.Unmarshal([]byte(view.OAuth2Config), &config) == nil {
+			for _, k := range []string{"accessToken", "clientSecret", "refreshToken", "password"} {
+				if v, ok := config[k].(string); ok {
+					if r := replace(v); r == "" {
+						delete(config, k)
+					} else {
+						config[k] = r
+					}
+				}
+			}
+			if data, err := json.Marshal(config); err == nil {
+				view.OAuth2Config = string(data)
+			}
+		}
+		env.SetProfile(name, view)
+	}
+
+	variables := make(map[string]string, len(env.Variables))
+	for k, v := range env.Variables {
+		if env.IsSecretVariable(k) {
+			if secretMode != SecretsMask {
+				continue
+			}
+			v = replace(v)
+		}
+		variables[k] = v
+	}
+	env.Variables = variables
+	return env
+}
+
+var dotenvBare = regexp.MustCompile(`^[A-Za-z0-9_./:@+-]*$`)
+
+func quoteDotenv(v string) string {
+	if dotenvBare.MatchString(v) {
+		return v
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`)
+	return `"` + r.Replace(v) + `"`
+}
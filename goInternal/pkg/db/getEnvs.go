@@ -1,12 +1,13 @@
 package db
 
 import (
+	"CommandPost/goInternal/pkg/secrets"
 	"database/sql"
 	"encoding/json"
 )
 
-func GetEnvironments(db *sql.DB) ([]Environment, error) {
-	rows, err := db.Query(`SELECT name, base_url, access_token, refresh_token, expires_at, auth_url, token_url, client_id, client_secret, redirect_uri, scope, variables, created_at, last_used, oauth2_config FROM environments`)
+func GetEnvironments(db *sql.DB, vault *secrets.Vault) ([]Environment, error) {
+	rows, err := db.Query(`SELECT name, base_url, access_token, refresh_token, expires_at, auth_url, token_url, client_id, client_secret, redirect_uri, scope, variables, created_at, last_used, oauth2_config, secret_variables, parent, id_token, profiles FROM environments`)
 	if err != nil {
 		return nil, err
 	}
@@ -16,6 +17,8 @@ func GetEnvironments(db *sql.DB) ([]Environment, error) {
 	for rows.Next() {
 		var environment Environment
 		var variables []byte
+		var secretVariables []byte
+		var parent, idToken, profiles sql.NullString
 		if err := rows.Scan(
 			&environment.Name,
 			&environment.BaseURL,
@@ -32,13 +35,46 @@ func GetEnvironments(db *sql.DB) ([]Environment, error) {
 			&environment.CreatedAt,
 			&environment.LastUsed,
 			&environment.OAuth2Config,
+			&secretVariables,
+			&parent,
+			&idToken,
+			&profiles,
 		); err != nil {
 			continue
 		}
 		if err := json.Unmarshal(variables, &environment.Variables); err != nil {
 			continue
 		}
+		environment.Parent = parent.String
+		environment.IDToken = idToken.String
+		environment.Profiles = []CredentialProfile{}
+		if profiles.String != "" {
+			if err := json.Unmarshal([]byte(profiles.String), &environment.Profiles); err != nil {
+				continue
+			}
+		}
+		if len(secretVariables) > 0 {
+			if err := json.Unmarshal(secretVariables, &environment.SecretVariables); err != nil {
+				continue
+			}
+		}
+		if err := openEnvironment(vault, &environment); err != nil {
+			return nil, err
+		}
 		environments = append(environments, environment)
 	}
 	return environments, nil
 }
+
+func GetEnvironment(db *sql.DB, vault *secrets.Vault, name string) (Environment, bool, error) {
+	environments, err := GetEnvironments(db, vault)
+	if err != nil {
+		return Environment{}, false, err
+	}
+	for _, e := range environments {
+		if e.Name == name {
+			return e, true, nil
+		}
+	}
+	return Environment{}, false, nil
+}
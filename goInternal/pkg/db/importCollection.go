@@ -5,10 +5,11 @@ import (
 	"encoding/json"
 	"log"
 	"os"
+	"path"
 )
 
-func ImportCollections(dbChan chan<- DbQuery, path string) (*PostmanCollection, error) {
-	file, err := os.ReadFile(path)
+func ImportCollections(dbChan chan<- DbQuery, filePath string) (*PostmanCollection, error) {
+	file, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -28,7 +29,7 @@ func ImportCollections(dbChan chan<- DbQuery, path string) (*PostmanCollection,
 
 func SaveImportedCollections(dbChan chan<- DbQuery, collection *PostmanCollection) error {
 	var allRequests []pkg.RequestData
-	collectRequests(collection.Item, &allRequests)
+	collectRequests(collection.Item, "", &allRequests)
 
 	if len(allRequests) == 0 {
 		return nil
@@ -49,7 +50,7 @@ func SaveImportedCollections(dbChan chan<- DbQuery, collection *PostmanCollectio
 	return <-result
 }
 
-func collectRequests(items []Item, allRequests *[]pkg.RequestData) {
+func collectRequests(items []Item, folder string, allRequests *[]pkg.RequestData) {
 	for _, item := range items {
 		if item.Request != nil {
 			var body string
@@ -58,6 +59,8 @@ func collectRequests(items []Item, allRequests *[]pkg.RequestData) {
 			}
 
 			reqData := pkg.RequestData{
+				Name:    item.Name,
+				Folder:  folder,
 				Method:  item.Request.Method,
 				URL:     item.Request.URL.Raw,
 				Headers: headersToMap(item.Request.Header),
@@ -68,7 +71,7 @@ func collectRequests(items []Item, allRequests *[]pkg.RequestData) {
 		}
 
 		if len(item.Item) > 0 {
-			collectRequests(item.Item, allRequests)
+			collectRequests(item.Item, path.Join(folder, item.Name), allRequests)
 		}
 	}
 }
@@ -0,0 +1,231 @@
+package db
+
+import (
+	"CommandPost/goInternal/pkg/secrets"
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ImportEnvironment reads an environment in any supported format and merges
+// it into the environment with the same name, creating it if needed. name
+// overrides the name found in the file and is required for dotenv files.
+func ImportEnvironment(db *sql.DB, dbChan chan<- DbQuery, vault *secrets.Vault, path string, format string, name string) (Environment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Environment{}, err
+	}
+	if format == "" {
+		format = detectEnvFormat(path, data)
+	}
+
+	imported, err := parseEnvironment(data, format)
+	if err != nil {
+		return Environment{}, err
+	}
+	if name != "" {
+		imported.Name = name
+	}
+	if imported.Name == "" {
+		return Environment{}, fmt.Errorf("environment name is required for %s imports", format)
+	}
+
+	existing, ok, err := GetEnvironment(db, vault, imported.Name)
+	if err != nil {
+		return Environment{}, err
+	}
+	if !ok {
+		now := time.Now().Format(time.RFC3339)
+		existing = Environment{Name: imported.Name, Variables: map[string]string{}, CreatedAt: now, LastUsed: now}
+	}
+
+	merged := mergeEnvironment(existing, imported)
+	if err := SaveEnvironment(dbChan, vault, merged); err != nil {
+		return Environment{}, err
+	}
+	return merged, nil
+}
+
+func detectEnvFormat(path string, data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return EnvFormatDotenv
+	}
+	var probe struct {
+		Values []json.RawMessage `json:"values"`
+		Scope  string            `json:"_postman_variable_scope"`
+	}
+	if json.Unmarshal(trimmed, &probe) == nil && (probe.Scope != "" || probe.Values != nil) {
+		return EnvFormatPostman
+	}
+	if strings.HasSuffix(filepath.Base(path), ".env") {
+		return EnvFormatDotenv
+	}
+	return EnvFormatJSON
+}
+
+func parseEnvironment(data []byte, format string) (Environment, error) {
+	var env Environment
+	switch format {
+	case EnvFormatJSON:
+		if err := json.Unmarshal(data, &env); err != nil {
+			return env, fmt.Errorf("invalid environment JSON: %w", err)
+		}
+		env.Locked = false
+	case EnvFormatPostman:
+		var pe PostmanEnvironment
+		if err := json.Unmarshal(data, &pe); err != nil {
+			return env, fmt.Errorf("invalid Postman environment: %w", err)
+		}
+		env.Name = pe.Name
+		env.Variables = make(map[string]string, len(pe.Values))
+		for _, v := range pe.Values {
+			if v.Enabled != nil && !*v.Enabled {
+				continue
+			}
+			env.Variables[v.Key] = v.Value
+			if v.Type == "secret" {
+				env.SecretVariables = append(env.SecretVariables, v.Key)
+			}
+		}
+	case EnvFormatDotenv:
+		variables, err := parseDotenv(data)
+		if err != nil {
+			return env, err
+		}
+		env.Variables = variables
+	default:
+		return env, fmt.Errorf("unknown environment format %q", format)
+	}
+	return env, nil
+}
+
+// mergeEnvironment overlays imported onto existing. Empty and masked values
+// never replace what is already stored, so re-importing an export made
+// without secrets keeps them intact.
+func mergeEnvironment(existing Environment, imported Environment) Environment {
+	merged := existing
+	set := func(dst *string, v string) {
+		if v != "" && v != MaskedValue {
+			*dst = v
+		}
+	}
+	set(&merged.BaseURL, imported.BaseURL)
+	set(&merged.AccessToken, imported.AccessToken)
+	set(&merged.RefreshToken, imported.RefreshToken)
+	set(&merged.IDToken, imported.IDToken)
+	set(&merged.ExpiresAt, imported.ExpiresAt)
+	set(&merged.AuthURL, imported.AuthURL)
+	set(&merged.TokenURL, imported.TokenURL)
+	set(&merged.ClientID, imported.ClientID)
+	set(&merged.ClientSecret, imported.ClientSecret)
+	set(&merged.RedirectURI, imported.RedirectURI)
+	set(&merged.Scope, imported.Scope)
+	set(&merged.Parent, imported.Parent)
+	if imported.OAuth2Config != "" && !strings.Contains(imported.OAuth2Config, MaskedValue) {
+		merged.OAuth2Config = imported.OAuth2Config
+	}
+	merged.Profiles = append([]CredentialProfile(nil), existing.Profiles...)
+	for _, p := range imported.Profiles {
+		if p.Name == "" {
+			continue
+		}
+		view, err := merged.WithProfile(p.Name)
+		if err != nil {
+			view = Environment{}
+		}
+		set(&view.AccessToken, p.AccessToken)
+		set(&view.RefreshToken, p.RefreshToken)
+		set(&view.IDToken, p.IDToken)
+		set(&view.ExpiresAt, p.ExpiresAt)
+		if p.OAuth2Config != "" && !strings.Contains(p.OAuth2Config, MaskedValue) {
+			view.OAuth2Config = p.OAuth2Config
+		}
+		merged.SetProfile(p.Name, view)
+	}
+
+	merged.Variables = make(map[string]string, len(existing.Variables)+len(imported.Variables))
+	for k, v := range existing.Variables {
+		merged.Variables[k] = v
+	}
+	for k, v := range imported.Variables {
+		if v == MaskedValue {
+			if _, ok := merged.Variables[k]; ok {
+				continue
+			}
+			v = ""
+		}
+		merged.Variables[k] = v
+	}
+
+	merged.SecretVariables = append([]string(nil), existing.SecretVariables...)
+	for _, k := range imported.SecretVariables {
+		if !merged.IsSecretVariable(k) {
+			merged.SecretVariables = append(merged.SecretVariables, k)
+		}
+	}
+	merged.Locked = false
+	return merged
+}
+
+func parseDotenv(data []byte) (map[string]string, error) {
+	variables := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid .env line %d: %q", lineNo, line)
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			value = unescapeDotenv(value[1 : len(value)-1])
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		variables[key] = value
+	}
+	return variables, scanner.Err()
+}
+
+func unescapeDotenv(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -6,7 +6,7 @@ import (
 )
 
 func LoadCollections(db *sql.DB) ([]Collection, error) {
-	rows, err := db.Query(`SELECT name, requests FROM collections`)
+	rows, err := db.Query(`SELECT name, requests, variables FROM collections`)
 	if err != nil {
 		return nil, err
 	}
@@ -15,12 +15,19 @@ func LoadCollections(db *sql.DB) ([]Collection, error) {
 	for rows.Next() {
 		var collection Collection
 		var requests []byte
-		if err := rows.Scan(&collection.Name, &requests); err != nil {
+		var variables []byte
+		if err := rows.Scan(&collection.Name, &requests, &variables); err != nil {
 			return nil, err
 		}
 		if err := json.Unmarshal(requests, &collection.Requests); err != nil {
 			return nil, err
 		}
+		collection.Variables = map[string]string{}
+		if len(variables) > 0 {
+			if err := json.Unmarshal(variables, &collection.Variables); err != nil {
+				return nil, err
+			}
+		}
 		collections = append(collections, collection)
 	}
 	return collections, nil
@@ -0,0 +1,69 @@
+package db
+
+import "fmt"
+
+// WithProfile returns e with the grant config and tokens of the named
+// profile in place of the default ones, so token handling can work on any
+// profile alike. The empty name is the default profile.
+func (e Environment) WithProfile(name string) (Environment, error) {
+	if name == "" {
+		return e, nil
+	}
+	for _, p := range e.Profiles {
+		if p.Name == name {
+			e.OAuth2Config = p.OAuth2Config
+			e.AccessToken = p.AccessToken
+			e.RefreshToken = p.RefreshToken
+			e.IDToken = p.IDToken
+			e.ExpiresAt = p.ExpiresAt
+			return e, nil
+		}
+	}
+	return e, fmt.Errorf("environment %s has no credential profile %s", e.Name, name)
+}
+
+// SetProfile stores the grant config and token state of view, as returned
+// by WithProfile, back into the named profile.
+func (e *Environment) SetProfile(name string, view Environment) {
+	if name == "" {
+		e.OAuth2Config = view.OAuth2Config
+		e.AccessToken = view.AccessToken
+		e.RefreshToken = view.RefreshToken
+		e.IDToken = view.IDToken
+		e.ExpiresAt = view.ExpiresAt
+		return
+	}
+	updated := CredentialProfile{
+		Name:         name,
+		OAuth2Config: view.OAuth2Config,
+		AccessToken:  view.AccessToken,
+		RefreshToken: view.RefreshToken,
+		IDToken:      view.IDToken,
+		ExpiresAt:    view.ExpiresAt,
+	}
+	profiles := make([]CredentialProfile, 0, len(e.Profiles)+1)
+	found := false
+	for _, p := range e.Profiles {
+		if p.Name == name {
+			p = updated
+			found = true
+		}
+		profiles = append(profiles, p)
+	}
+	if !found {
+		profiles = append(profiles, updated)
+	}
+	e.Profiles = profiles
+}
+
+func profileNames(e Environment) []string {
+	names := make([]string, len(e.Profiles))
+	for i, p := range e.Profiles {
+		names[i] = p.Name
+	}
+	return names
+}
+
+func (p *CredentialProfile) secretFields() []*string {
+	return []*string{&p.OAuth2Config, &p.AccessToken, &p.RefreshToken, &p.IDToken}
+}
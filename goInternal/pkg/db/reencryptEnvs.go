@@ -0,0 +1,94 @@
+package db
+
+import (
+	"CommandPost/goInternal/pkg/secrets"
+	"database/sql"
+	"encoding/json"
+)
+
+// ReencryptEnvironments rewrites every environment's secret fields, opening
+// them with from and sealing them with to, and stores cfg, the config of
+// to, in the same transaction. Passing the same vault twice seals any
+// values that were still stored in plaintext.
+func ReencryptEnvironments(db *sql.DB, dbChan chan<- DbQuery, from *secrets.Vault, to *secrets.Vault, cfg secrets.VaultConfig) error {
+	if from.Locked() || to.Locked() {
+		return secrets.ErrLocked
+	}
+	rows, err := db.Query(`SELECT id, access_token, refresh_token, id_token, client_secret, oauth2_config, variables, secret_variables, profiles FROM environments`)
+	if err != nil {
+		return err
+	}
+
+	type row struct {
+		id  int
+		env Environment
+	}
+	var pending []row
+	for rows.Next() {
+		var r row
+		var accessToken, refreshToken, idToken, clientSecret, oauth2Config, variables, secretVariables, profiles sql.NullString
+		if err := rows.Scan(&r.id, &accessToken, &refreshToken, &idToken, &clientSecret, &oauth2Config, &variables, &secretVariables, &profiles); err != nil {
+			rows.Close()
+			return err
+		}
+		r.env = Environment{
+			AccessToken:  accessToken.String,
+			RefreshToken: refreshToken.String,
+			IDToken:      idToken.String,
+			ClientSecret: clientSecret.String,
+			OAuth2Config: oauth2Config.String,
+		}
+		if variables.String != "" {
+			if err := json.Unmarshal([]byte(variables.String), &r.env.Variables); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		if profiles.String != "" {
+			if err := json.Unmarshal([]byte(profiles.String), &r.env.Profiles); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		if secretVariables.String != "" {
+			if err := json.Unmarshal([]byte(secretVariables.String), &r.env.SecretVariables); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		pending = append(pending, r)
+	}
+	rows.Close()
+
+	// Writes go through the worker, which needs the single connection the
+	// rows above were holding, so they can only start once it is released.
+	batch := make([]DbQuery, 0, len(pending)+1)
+	for _, r := range pending {
+		if err := openEnvironment(from, &r.env); err != nil {
+			return err
+		}
+		sealed, err := sealEnvironment(to, r.env)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(sealed.Variables)
+		if err != nil {
+			return err
+		}
+		profileData, err := json.Marshal(sealed.Profiles)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, DbQuery{
+			Query: `UPDATE environments SET access_token = ?, refresh_token = ?, id_token = ?, client_secret = ?, oauth2_config = ?, variables = ?, profiles = ? WHERE id = ?`,
+			Args: []any{
+				sealed.AccessToken, sealed.RefreshToken, sealed.IDToken, sealed.ClientSecret, sealed.OAuth2Config, string(data), string(profileData), r.id,
+			},
+		})
+	}
+	batch = append(batch, vaultConfigQuery(cfg))
+
+	result := make(chan error, 1)
+	dbChan <- DbQuery{Batch: batch, Result: result}
+	return <-result
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"CommandPost/goInternal/pkg/secrets"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReencryptEnvironments(t *testing.T) {
+	database, dbChan, keyVault := testDB(t)
+	env := Environment{
+		Name:            "dev",
+		AccessToken:     "access-secret",
+		ClientSecret:    "client-secret",
+		Variables:       map[string]string{"host": "dev.local", "token": "variable-secret"},
+		SecretVariables: []string{"token"},
+		Profiles:        []CredentialProfile{{Name: "admin", AccessToken: "profile-secret"}},
+	}
+	if err := SaveEnvironment(dbChan, keyVault, env); err != nil {
+		t.Fatal(err)
+	}
+	// A secret written before encryption was enabled.
+	if err := SaveEnvironment(dbChan, keyVault, Environment{Name: "legacy"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec("UPDATE environments SET client_secret = 'legacy-secret' WHERE name = 'legacy'"); err != nil {
+		t.Fatal(err)
+	}
+
+	passCfg, c, err := secrets.NewVaultConfig(secrets.ModePassphrase, "", "new passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	passVault := secrets.NewVault()
+	passVault.Unlock(c)
+
+	if err := ReencryptEnvironments(database, dbChan, secrets.NewVault(), passVault, passCfg); !errors.Is(err, secrets.ErrLocked) {
+		t.Fatalf("ReencryptEnvironments() from a locked vault = %v, want ErrLocked", err)
+	}
+	if err := ReencryptEnvironments(database, dbChan, keyVault, passVault, passCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg, ok, err := LoadVaultConfig(database); err != nil || !ok || cfg.Check != passCfg.Check {
+		t.Fatalf("LoadVaultConfig() = %+v, %v, %v, want the new config", cfg, ok, err)
+	}
+
+	var raw strings.Builder
+	rows, err := database.Query("SELECT COALESCE(access_token, ''), COALESCE(client_secret, ''), COALESCE(variables, ''), COALESCE(profiles, '') FROM environments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var a, b, c, d string
+		if err := rows.Scan(&a, &b, &c, &d); err != nil {
+			t.Fatal(err)
+		}
+		raw.WriteString(a + b + c + d)
+	}
+	rows.Close()
+	for _, secret := range []string{"access-secret", "client-secret", "variable-secret", "profile-secret", "legacy-secret"} {
+		if strings.Contains(raw.String(), secret) {
+			t.Errorf("%s is stored in plaintext", secret)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		vault *secrets.Vault
+		ok    bool
+	}{
+		{name: "new vault", vault: passVault, ok: true},
+		{name: "old vault", vault: keyVault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := GetEnvironment(database, tt.vault, "dev")
+			if !tt.ok {
+				if err == nil {
+					t.Error("old key still opens the environment")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.AccessToken != "access-secret" || got.ClientSecret != "client-secret" ||
+				got.Variables["token"] != "variable-secret" || got.Variables["host"] != "dev.local" ||
+				got.Profiles[0].AccessToken != "profile-secret" {
+				t.Errorf("GetEnvironment() = %+v", got)
+			}
+			legacy, _, err := GetEnvironment(database, tt.vault, "legacy")
+			if err != nil || legacy.ClientSecret != "legacy-secret" {
+				t.Errorf("legacy client secret = %q, %v", legacy.ClientSecret, err)
+			}
+		})
+	}
+}
+
+// TestReencryptEnvironmentsFailure fails the vault config write and checks
+// that nothing was re-encrypted under a key the stored config does not
+// describe.
+func TestReencryptEnvironmentsFailure(t *testing.T) {
+	database, dbChan, _ := testDB(t)
+	vault := func(passphrase string) (secrets.VaultConfig, *secrets.Vault) {
+		cfg, c, err := secrets.NewVaultConfig(secrets.ModePassphrase, "", passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := secrets.NewVault()
+		v.Unlock(c)
+		return cfg, v
+	}
+	oldCfg, oldVault := vault("old passphrase")
+	newCfg, newVault := vault("new passphrase")
+
+	for _, name := range []string{"dev", "prod"} {
+		env := Environment{Name: name, ClientSecret: name + "-secret", Variables: map[string]string{"token": name + "-token"}, SecretVariables: []string{"token"}}
+		if err := SaveEnvironment(dbChan, oldVault, env); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ReencryptEnvironments(database, dbChan, oldVault, oldVault, oldCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := database.Exec(`CREATE TRIGGER fail_vault BEFORE INSERT ON vault BEGIN SELECT RAISE(ABORT, 'injected failure'); END`); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReencryptEnvironments(database, dbChan, oldVault, newVault, newCfg); err == nil || !strings.Contains(err.Error(), "injected failure") {
+		t.Fatalf("ReencryptEnvironments() = %v, want the injected failure", err)
+	}
+
+	cfg, ok, err := LoadVaultConfig(database)
+	if err != nil || !ok {
+		t.Fatalf("LoadVaultConfig() = %v, %v", ok, err)
+	}
+	reopened := secrets.NewVault()
+	if err := reopened.UnlockWith(cfg, "", "old passphrase"); err != nil {
+		t.Fatalf("stored config no longer matches the old passphrase: %v", err)
+	}
+	envs, err := GetEnvironments(database, reopened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envs) != 2 {
+		t.Fatalf("got %d environments, want 2", len(envs))
+	}
+	for _, env := range envs {
+		if env.ClientSecret != env.Name+"-secret" || env.Variables["token"] != env.Name+"-token" {
+			t.Errorf("environment %s = %+v, does not open with the old config", env.Name, env)
+		}
+	}
+}
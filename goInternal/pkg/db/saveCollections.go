@@ -6,13 +6,31 @@ import (
 )
 
 func SaveCollection(dbChan chan<- DbQuery, name string, requests []pkg.RequestData) error {
-	data, err := json.Marshal(requests)
+	stored := make([]pkg.RequestData, len(requests))
+	for i, req := range requests {
+		stored[i] = req.WithoutSecrets()
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	result := make(chan error, 1)
+	dbChan <- DbQuery{
+		Query:  "INSERT INTO collections (name, requests) VALUES (?, ?) ON CONFLICT(name) DO UPDATE SET requests = excluded.requests",
+		Args:   []any{name, string(data)},
+		Result: result,
+	}
+	return <-result
+}
+
+func SaveCollectionVariables(dbChan chan<- DbQuery, name string, variables map[string]string) error {
+	data, err := json.Marshal(variables)
 	if err != nil {
 		return err
 	}
 	result := make(chan error, 1)
 	dbChan <- DbQuery{
-		Query:  "INSERT OR REPLACE INTO collections (name, requests) VALUES (?, ?)",
+		Query:  "INSERT INTO collections (name, requests, variables) VALUES (?, '[]', ?) ON CONFLICT(name) DO UPDATE SET variables = excluded.variables",
 		Args:   []any{name, string(data)},
 		Result: result,
 	}
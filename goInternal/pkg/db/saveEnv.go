@@ -1,22 +1,50 @@
 package db
 
 import (
+	"CommandPost/goInternal/pkg/secrets"
 	"encoding/json"
+	"fmt"
 )
 
-func SaveEnvironment(dbChan chan<- DbQuery, env Environment) error {
+func SaveEnvironment(dbChan chan<- DbQuery, vault *secrets.Vault, env Environment) error {
+	if env.Parent != "" && env.Parent == env.Name {
+		return fmt.Errorf("environment %s cannot be its own parent", env.Name)
+	}
+	seen := make(map[string]bool, len(env.Profiles))
+	for _, p := range env.Profiles {
+		if p.Name == "" || seen[p.Name] {
+			return fmt.Errorf("environment %s has an unnamed or duplicate credential profile %q", env.Name, p.Name)
+		}
+		seen[p.Name] = true
+	}
+	env, err := sealEnvironment(vault, env)
+	if err != nil {
+		return err
+	}
 	data, err := json.Marshal(env.Variables)
 	if err != nil {
 		return err
 	}
+	secretVariables, err := json.Marshal(env.SecretVariables)
+	if err != nil {
+		return err
+	}
+	profiles, err := json.Marshal(env.Profiles)
+	if err != nil {
+		return err
+	}
 	result := make(chan error, 1)
 	dbChan <- DbQuery{
-		Query: `INSERT OR REPLACE INTO environments (name, base_url, access_token, refresh_token, expires_at, 
-		auth_url, token_url, client_id, client_secret, redirect_uri, scope, variables, created_at, last_used, oauth2_config) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		Query: `INSERT INTO environments (name, base_url, access_token, refresh_token, expires_at, 
+		auth_url, token_url, client_id, client_secret, redirect_uri, scope, variables, created_at, last_used, oauth2_config, secret_variables, parent, id_token, profiles) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET base_url = excluded.base_url, access_token = excluded.access_token, refresh_token = excluded.refresh_token,
+		expires_at = excluded.expires_at, auth_url = excluded.auth_url, token_url = excluded.token_url, client_id = excluded.client_id,
+		client_secret = excluded.client_secret, redirect_uri = excluded.redirect_uri, scope = excluded.scope, variables = excluded.variables,
+		last_used = excluded.last_used, oauth2_config = excluded.oauth2_config, secret_variables = excluded.secret_variables, parent = excluded.parent, id_token = excluded.id_token, profiles = excluded.profiles`,
 		Args: []any{
 			env.Name, env.BaseURL, env.AccessToken, env.RefreshToken, env.ExpiresAt,
 			env.AuthURL, env.TokenURL, env.ClientID, env.ClientSecret, env.RedirectURI, env.Scope,
-			string(data), env.CreatedAt, env.LastUsed, env.OAuth2Config,
+			string(data), env.CreatedAt, env.LastUsed, env.OAuth2Config, string(secretVariables), env.Parent, env.IDToken, string(profiles),
 		},
 		Result: result,
 	}
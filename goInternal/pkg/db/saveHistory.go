@@ -6,7 +6,7 @@ import (
 )
 
 func SaveHistory(dbChan chan<- DbQuery, req pkg.RequestData, res pkg.ResponseData) error {
-	reqJSON, err := json.Marshal(req)
+	reqJSON, err := json.Marshal(req.WithoutSecrets())
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	pkg "CommandPost/goInternal/pkg/inAppExec"
+	"CommandPost/goInternal/pkg/signing"
+	"strings"
+	"testing"
+)
+
+func TestSigningSecretsNotStored(t *testing.T) {
+	database, dbChan, _ := testDB(t)
+	req := pkg.RequestData{
+		Method: "GET",
+		URL:    "https://example.com",
+		Signing: &signing.Config{
+			Type:      signing.TypeAWS,
+			AccessKey: "AKIDEXAMPLE",
+			SecretKey: "plaintext-secret",
+			Region:    "us-east-1",
+		},
+	}
+	withRef := req
+	withRef.Signing = &signing.Config{Type: signing.TypeHMAC, KeyID: "id", Secret: "{{ hmac_secret }}"}
+
+	if err := SaveHistory(dbChan, req, pkg.ResponseData{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveCollection(dbChan, "signed", []pkg.RequestData{req, withRef}); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := LoadHistory(database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range history {
+		if strings.Contains(h.Request, "plaintext-secret") {
+			t.Errorf("history %d holds the secret: %s", h.ID, h.Request)
+		}
+	}
+	collections, err := LoadCollections(database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range collections {
+		for _, r := range c.Requests {
+			if r.Signing.SecretKey != "" {
+				t.Errorf("collection %s holds the secret %q", c.Name, r.Signing.SecretKey)
+			}
+			if r.Signing.Type == signing.TypeHMAC && r.Signing.Secret != "{{ hmac_secret }}" {
+				t.Errorf("variable reference dropped: %q", r.Signing.Secret)
+			}
+			if r.Signing.AccessKey == "" && r.Signing.Type == signing.TypeAWS {
+				t.Error("access key dropped")
+			}
+		}
+	}
+}
@@ -10,8 +10,9 @@ type HistoryRecord struct {
 }
 
 type Collection struct {
-	Name     string            `json:"name"`
-	Requests []pkg.RequestData `json:"requests"`
+	Name      string            `json:"name"`
+	Requests  []pkg.RequestData `json:"requests"`
+	Variables map[string]string `json:"variables"`
 }
 
 type PostmanCollection struct {
@@ -54,26 +55,86 @@ type Body struct {
 	Raw  string `json:"raw,omitempty"`
 }
 
+// GlobalEnvironmentName is the environment whose variables apply to every
+// request, below collection variables.
+const GlobalEnvironmentName = "Global"
+
 type Environment struct {
-	Name         string            `json:"name"`
-	BaseURL      string            `json:"base_url"`
-	AccessToken  string            `json:"access_token"`
-	RefreshToken string            `json:"refresh_token"`
-	ExpiresAt    string            `json:"expires_at"`
-	AuthURL      string            `json:"auth_url"`
-	TokenURL     string            `json:"token_url"`
-	ClientID     string            `json:"client_id"`
-	ClientSecret string            `json:"client_secret"`
-	RedirectURI  string            `json:"redirect_uri"`
-	Scope        string            `json:"scope"`
-	Variables    map[string]string `json:"variables"`
-	CreatedAt    string            `json:"created_at"`
-	LastUsed     string            `json:"last_used"`
-	OAuth2Config string            `json:"oauth2_config"`
+	Name            string              `json:"name"`
+	BaseURL         string              `json:"base_url"`
+	AccessToken     string              `json:"access_token"`
+	RefreshToken    string              `json:"refresh_token"`
+	IDToken         string              `json:"id_token"`
+	ExpiresAt       string              `json:"expires_at"`
+	AuthURL         string              `json:"auth_url"`
+	TokenURL        string              `json:"token_url"`
+	ClientID        string              `json:"client_id"`
+	ClientSecret    string              `json:"client_secret"`
+	RedirectURI     string              `json:"redirect_uri"`
+	Scope           string              `json:"scope"`
+	Variables       map[string]string   `json:"variables"`
+	CreatedAt       string              `json:"created_at"`
+	LastUsed        string              `json:"last_used"`
+	OAuth2Config    string              `json:"oauth2_config"`
+	Parent          string              `json:"parent"`           // optional environment whose variables this one inherits
+	SecretVariables []string            `json:"secret_variables"` // keys in Variables encrypted at rest, masked in the UI and excluded from exports
+	Profiles        []CredentialProfile `json:"profiles"`         // named credentials beside the default token fields above
+	Locked          bool                `json:"locked"`           // loaded while the vault was locked, so every secret field is blank
+}
+
+// CredentialProfile is a named set of credentials in an environment, such
+// as an admin or service token, with its own grant config and token state.
+type CredentialProfile struct {
+	Name         string `json:"name"`
+	OAuth2Config string `json:"oauth2_config"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+	ExpiresAt    string `json:"expires_at"`
+}
+
+func (e Environment) IsSecretVariable(key string) bool {
+	for _, k := range e.SecretVariables {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	EnvFormatJSON    = "json"
+	EnvFormatPostman = "postman"
+	EnvFormatDotenv  = "dotenv"
+
+	SecretsExclude = "exclude"
+	SecretsMask    = "mask"
+	SecretsInclude = "include"
+
+	// MaskedValue stands in for secrets in masked exports and is ignored
+	// on import so it never overwrites a real value.
+	MaskedValue = "********"
+)
+
+type PostmanEnvironment struct {
+	ID     string                    `json:"id,omitempty"`
+	Name   string                    `json:"name"`
+	Values []PostmanEnvironmentValue `json:"values"`
+	Scope  string                    `json:"_postman_variable_scope,omitempty"`
+}
+
+type PostmanEnvironmentValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Enabled *bool  `json:"enabled,omitempty"`
 }
 
 type DbQuery struct {
 	Query  string
 	Args   []any
 	Result chan error
+	// Batch, when set, runs in one transaction instead of Query. Results of
+	// the queries in it are not reported.
+	Batch []DbQuery
 }
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// LoadSyncState returns the content hash of every file as of the last sync
+// of dir, an absolute path, from this workspace.
+func LoadSyncState(db *sql.DB, dir string) (map[string]string, error) {
+	files := map[string]string{}
+	var data string
+	err := db.QueryRow(`SELECT files FROM sync_state WHERE dir = ?`, dir).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(data), &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func SaveSyncState(dbChan chan<- DbQuery, dir string, files map[string]string) error {
+	data, err := json.Marshal(files)
+	if err != nil {
+		return err
+	}
+	result := make(chan error, 1)
+	dbChan <- DbQuery{
+		Query:  `INSERT INTO sync_state (dir, files) VALUES (?, ?) ON CONFLICT(dir) DO UPDATE SET files = excluded.files`,
+		Args:   []any{dir, string(data)},
+		Result: result,
+	}
+	return <-result
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"CommandPost/goInternal/pkg/secrets"
+	"CommandPost/goInternal/pkg/variables"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// VariableLayers returns the variable sources for a request, highest
+// priority first: the environment, its chain of parents, the collection and
+// finally the global environment. Either name may be empty.
+func VariableLayers(db *sql.DB, vault *secrets.Vault, envName string, collectionName string) ([]variables.Layer, error) {
+	environments, err := GetEnvironments(db, vault)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]Environment, len(environments))
+	for _, e := range environments {
+		byName[e.Name] = e
+	}
+
+	var layers []variables.Layer
+	visited := make(map[string]bool)
+	source := "environment"
+	for name := envName; name != "" && name != GlobalEnvironmentName; {
+		if visited[name] {
+			return nil, fmt.Errorf("environment %s has a parent cycle", envName)
+		}
+		visited[name] = true
+
+		env, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("environment %s not found", name)
+		}
+		layers = append(layers, environmentLayer(source+" "+env.Name, env))
+		source = "parent"
+		name = env.Parent
+	}
+
+	if collectionName != "" {
+		var data []byte
+		err := db.QueryRow(`SELECT variables FROM collections WHERE name = ?`, collectionName).Scan(&data)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		layer := variables.Layer{Source: "collection " + collectionName}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &layer.Values); err != nil {
+				return nil, err
+			}
+		}
+		layers = append(layers, layer)
+	}
+
+	if global, ok := byName[GlobalEnvironmentName]; ok {
+		layers = append(layers, environmentLayer("global", global))
+	}
+	return layers, nil
+}
+
+func environmentLayer(source string, env Environment) variables.Layer {
+	return variables.Layer{
+		Source: source,
+		Values: env.Variables,
+		Secret: env.SecretVariables,
+		Locked: env.Locked,
+	}
+}
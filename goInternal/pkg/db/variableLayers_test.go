@@ -0,0 +1,139 @@
+package db
+
+import (
+	"CommandPost/goInternal/pkg/secrets"
+	"CommandPost/goInternal/pkg/variables"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// testDB opens a workspace database in a temporary directory, with its
+// write worker and a vault unlocked with a key file.
+func testDB(t *testing.T) (*sql.DB, chan DbQuery, *secrets.Vault) {
+	t.Helper()
+	dir := t.TempDir()
+	database, err := sql.Open("sqlite", filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.SetMaxOpenConns(1)
+	for _, create := range []func(*sql.DB) error{CreateCollectionsTable, CreateHistoryTable, CreateEnvironmentsTable, CreateVaultTable, CreateSyncStateTable} {
+		if err := create(database); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dbChan := make(chan DbQuery, 10)
+	done := make(chan struct{})
+	go func() {
+		DbWorker(database, dbChan)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		close(dbChan)
+		<-done
+		database.Close()
+	})
+
+	_, c, err := secrets.NewVaultConfig(secrets.ModeKeyFile, filepath.Join(dir, "test.key"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vault := secrets.NewVault()
+	vault.Unlock(c)
+	return database, dbChan, vault
+}
+
+func TestVariableLayers(t *testing.T) {
+	database, dbChan, vault := testDB(t)
+	envs := []Environment{
+		{Name: GlobalEnvironmentName, Variables: map[string]string{"host": "example.com", "version": "v1"}},
+		{Name: "base", Variables: map[string]string{"host": "base.local", "token": "base-token"}, SecretVariables: []string{"token"}},
+		{Name: "dev", Parent: "base", Variables: map[string]string{"id": "1"}},
+		{Name: "loop-a", Parent: "loop-b"},
+		{Name: "loop-b", Parent: "loop-a"},
+	}
+	for _, env := range envs {
+		if err := SaveEnvironment(dbChan, vault, env); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := SaveCollection(dbChan, "pets", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveCollectionVariables(dbChan, "pets", map[string]string{"id": "2", "version": "v2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		env, collection string
+		sources         []string
+		values          map[string]string
+		err             string
+	}{
+		{
+			env: "dev", collection: "pets",
+			sources: []string{"environment dev", "parent base", "collection pets", "global"},
+			values:  map[string]string{"host": "base.local", "id": "1", "token": "base-token", "version": "v2"},
+		},
+		{
+			env:     "base",
+			sources: []string{"environment base", "global"},
+			values:  map[string]string{"host": "base.local", "token": "base-token", "version": "v1"},
+		},
+		{
+			collection: "pets",
+			sources:    []string{"collection pets", "global"},
+			values:     map[string]string{"host": "example.com", "id": "2", "version": "v2"},
+		},
+		{env: "loop-a", err: "parent cycle"},
+		{env: "missing", err: "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env+"/"+tt.collection, func(t *testing.T) {
+			layers, err := VariableLayers(database, vault, tt.env, tt.collection)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var sources []string
+			for _, l := range layers {
+				sources = append(sources, l.Source)
+			}
+			if !reflect.DeepEqual(sources, tt.sources) {
+				t.Errorf("sources = %v, want %v", sources, tt.sources)
+			}
+			if got := variables.Values(variables.Resolve(layers...)); !reflect.DeepEqual(got, tt.values) {
+				t.Errorf("values = %v, want %v", got, tt.values)
+			}
+		})
+	}
+
+	t.Run("locked vault", func(t *testing.T) {
+		vault.Lock()
+		layers, err := VariableLayers(database, vault, "dev", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resolved := variables.Resolve(layers...)
+		if _, ok := variables.Values(resolved)["token"]; ok {
+			t.Error("locked secret resolved")
+		}
+		if got := variables.LockedIn("Bearer {{token}}", resolved); !reflect.DeepEqual(got, []string{"token"}) {
+			t.Errorf("LockedIn() = %v, want [token]", got)
+		}
+		if err := SaveEnvironment(dbChan, vault, envs[1]); !errors.Is(err, secrets.ErrLocked) {
+			t.Errorf("SaveEnvironment() error = %v, want ErrLocked", err)
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"CommandPost/goInternal/pkg/secrets"
+	"database/sql"
+	"errors"
+)
+
+func LoadVaultConfig(db *sql.DB) (secrets.VaultConfig, bool, error) {
+	var cfg secrets.VaultConfig
+	err := db.QueryRow(`SELECT mode, salt, check_value FROM vault WHERE id = 1`).Scan(&cfg.Mode, &cfg.Salt, &cfg.Check)
+	if errors.Is(err, sql.ErrNoRows) {
+		return cfg, false, nil
+	}
+	if err != nil {
+		return cfg, false, err
+	}
+	return cfg, true, nil
+}
+
+func vaultConfigQuery(cfg secrets.VaultConfig) DbQuery {
+	return DbQuery{
+		Query: `INSERT OR REPLACE INTO vault (id, mode, salt, check_value) VALUES (1, ?, ?, ?)`,
+		Args:  []any{cfg.Mode, cfg.Salt, cfg.Check},
+	}
+}
@@ -0,0 +1,232 @@
+package filesync
+
+import (
+	"CommandPost/goInternal/pkg/db"
+	pkg "CommandPost/goInternal/pkg/inAppExec"
+	"CommandPost/goInternal/pkg/secrets"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+type workspaceView struct {
+	files        map[string][]byte
+	collections  map[string]db.Collection  // keyed by collection directory
+	environments map[string]db.Environment // keyed by file path
+}
+
+func newWorkspaceView(collections []db.Collection, environments []db.Environment) workspaceView {
+	view := workspaceView{
+		files:        make(map[string][]byte),
+		collections:  make(map[string]db.Collection),
+		environments: make(map[string]db.Environment),
+	}
+	dirs := uniqueSlugs(collectionNames(collections))
+	for _, c := range collections {
+		view.collections[path.Join(collectionsDir, dirs[c.Name])] = c
+	}
+	names := make([]string, 0, len(environments))
+	for _, e := range environments {
+		names = append(names, e.Name)
+	}
+	slugs := uniqueSlugs(names)
+	for _, e := range environments {
+		view.environments[path.Join(environmentsDir, slugs[e.Name]+".json")] = e
+	}
+	return view
+}
+
+// applyChanges writes files edited on disk back into the database. A nil
+// entry in changes means the file was deleted. Files that cannot be parsed
+// are returned as conflicts, and a collection containing one is skipped as a
+// whole rather than imported half-way.
+func applyChanges(dbChan chan<- db.DbQuery, vault *secrets.Vault, view workspaceView, changes map[string][]byte) (map[string]string, error) {
+	conflicts := make(map[string]string)
+	collectionDirs := make(map[string]bool)
+	var environmentPaths []string
+	for p := range changes {
+		parts := strings.SplitN(p, "/", 3)
+		switch {
+		case parts[0] == collectionsDir && len(parts) == 3:
+			collectionDirs[path.Join(parts[0], parts[1])] = true
+		case parts[0] == environmentsDir && len(parts) == 2:
+			environmentPaths = append(environmentPaths, p)
+		}
+	}
+
+	dirs := make([]string, 0, len(collectionDirs))
+	for dir := range collectionDirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		if err := applyCollection(dbChan, view, changes, dir, conflicts); err != nil {
+			return conflicts, err
+		}
+	}
+
+	sort.Strings(environmentPaths)
+	for _, p := range environmentPaths {
+		if err := applyEnvironment(dbChan, vault, view, p, changes[p], conflicts); err != nil {
+			return conflicts, err
+		}
+	}
+	return conflicts, nil
+}
+
+func applyCollection(dbChan chan<- db.DbQuery, view workspaceView, changes map[string][]byte, dir string, conflicts map[string]string) error {
+	files := make(map[string][]byte)
+	for p, data := range view.files {
+		if strings.HasPrefix(p, dir+"/") {
+			files[strings.TrimPrefix(p, dir+"/")] = data
+		}
+	}
+	for p, data := range changes {
+		if !strings.HasPrefix(p, dir+"/") {
+			continue
+		}
+		rel := strings.TrimPrefix(p, dir+"/")
+		if data == nil {
+			delete(files, rel)
+		} else {
+			files[rel] = data
+		}
+	}
+
+	old, hadOld := view.collections[dir]
+	metaData, hasMeta := files[collectionMetaFile]
+	delete(files, collectionMetaFile)
+	if !hasMeta && len(files) == 0 {
+		if hadOld {
+			return db.DeleteCollection(dbChan, old.Name)
+		}
+		return nil
+	}
+
+	meta := collectionMeta{Name: path.Base(dir)}
+	if hadOld {
+		meta.Name = old.Name
+		meta.Variables = old.Variables
+	}
+	if hasMeta {
+		if err := json.Unmarshal(metaData, &meta); err != nil || meta.Name == "" {
+			skipCollection(changes, dir, path.Join(dir, collectionMetaFile), "invalid collection file", conflicts)
+			return nil
+		}
+	}
+
+	requests := make([]pkg.RequestData, 0, len(files))
+	for _, rel := range orderedRequests(meta.Requests, files) {
+		var req pkg.RequestData
+		if err := json.Unmarshal(files[rel], &req); err != nil {
+			skipCollection(changes, dir, path.Join(dir, rel), "invalid request file: "+err.Error(), conflicts)
+			return nil
+		}
+		// A file moved to another directory on disk moves to that folder.
+		folder := path.Dir(rel)
+		if folder == "." {
+			folder = ""
+		}
+		if slugPath(req.Folder) != folder {
+			req.Folder = folder
+		}
+		requests = append(requests, req)
+	}
+
+	if hadOld && old.Name != meta.Name {
+		if err := db.DeleteCollection(dbChan, old.Name); err != nil {
+			return err
+		}
+	}
+	if err := db.SaveCollection(dbChan, meta.Name, requests); err != nil {
+		return err
+	}
+	return db.SaveCollectionVariables(dbChan, meta.Name, meta.Variables)
+}
+
+// skipCollection marks every changed file in dir as a conflict so the
+// edits stay on disk instead of being overwritten by the database copy.
+func skipCollection(changes map[string][]byte, dir string, bad string, reason string, conflicts map[string]string) {
+	conflicts[bad] = reason
+	for p := range changes {
+		if strings.HasPrefix(p, dir+"/") && p != bad {
+			conflicts[p] = "not imported: " + bad + " is invalid"
+		}
+	}
+}
+
+// orderedRequests follows the order recorded in _collection.json and
+// appends files it does not mention in path order.
+func orderedRequests(order []string, files map[string][]byte) []string {
+	seen := make(map[string]bool, len(files))
+	result := make([]string, 0, len(files))
+	for _, rel := range order {
+		if _, ok := files[rel]; ok && !seen[rel] {
+			seen[rel] = true
+			result = append(result, rel)
+		}
+	}
+	var rest []string
+	for rel := range files {
+		if !seen[rel] {
+			rest = append(rest, rel)
+		}
+	}
+	sort.Strings(rest)
+	return append(result, rest...)
+}
+
+func applyEnvironment(dbChan chan<- db.DbQuery, vault *secrets.Vault, view workspaceView, p string, data []byte, conflicts map[string]string) error {
+	old, hadOld := view.environments[p]
+	if data == nil {
+		if hadOld {
+			return db.DeleteEnvironment(dbChan, old.Name)
+		}
+		return nil
+	}
+
+	var file environmentFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Name == "" {
+		conflicts[p] = "invalid environment file"
+		return nil
+	}
+
+	// Secrets never leave the database, so they are carried over from
+	// whichever environment already has this name.
+	now := time.Now().Format(time.RFC3339)
+	env := db.Environment{CreatedAt: now, LastUsed: now}
+	for _, e := range view.environments {
+		if e.Name == file.Name {
+			env = e
+			break
+		}
+	}
+
+	variables := make(map[string]string, len(file.Variables))
+	for k, v := range file.Variables {
+		variables[k] = v
+	}
+	for _, k := range file.SecretVariables {
+		variables[k] = env.Variables[k]
+	}
+
+	env.Name = file.Name
+	env.BaseURL = file.BaseURL
+	env.AuthURL = file.AuthURL
+	env.TokenURL = file.TokenURL
+	env.ClientID = file.ClientID
+	env.RedirectURI = file.RedirectURI
+	env.Scope = file.Scope
+	env.Parent = file.Parent
+	env.Variables = variables
+	env.SecretVariables = file.SecretVariables
+
+	if hadOld && old.Name != file.Name {
+		if err := db.DeleteEnvironment(dbChan, old.Name); err != nil {
+			return err
+		}
+	}
+	return db.SaveEnvironment(dbChan, vault, env)
+}
@@ -0,0 +1,172 @@
+package filesync
+
+import (
+	"CommandPost/goInternal/pkg/db"
+	pkg "CommandPost/goInternal/pkg/inAppExec"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// renderCollections lays collections out as one directory per collection
+// and one file per request, keyed by slash-separated relative path.
+func renderCollections(collections []db.Collection) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	dirs := uniqueSlugs(collectionNames(collections))
+
+	for _, c := range collections {
+		dir := path.Join(collectionsDir, dirs[c.Name])
+		meta := collectionMeta{Name: c.Name, Variables: c.Variables, Requests: []string{}}
+		used := make(map[string]bool)
+
+		for _, req := range c.Requests {
+			rel := path.Join(slugPath(req.Folder), requestFileName(req, used))
+			meta.Requests = append(meta.Requests, rel)
+
+			data, err := marshalFile(req.WithoutSecrets())
+			if err != nil {
+				return nil, err
+			}
+			files[path.Join(dir, rel)] = data
+		}
+
+		data, err := marshalFile(meta)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Join(dir, collectionMetaFile)] = data
+	}
+	return files, nil
+}
+
+func renderEnvironments(environments []db.Environment) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	names := make([]string, 0, len(environments))
+	for _, e := range environments {
+		names = append(names, e.Name)
+	}
+	slugs := uniqueSlugs(names)
+
+	for _, e := range environments {
+		data, err := marshalFile(toEnvironmentFile(e))
+		if err != nil {
+			return nil, err
+		}
+		files[path.Join(environmentsDir, slugs[e.Name]+".json")] = data
+	}
+	return files, nil
+}
+
+func toEnvironmentFile(e db.Environment) environmentFile {
+	variables := make(map[string]string, len(e.Variables))
+	for k, v := range e.Variables {
+		if !e.IsSecretVariable(k) {
+			variables[k] = v
+		}
+	}
+	secretVariables := append([]string(nil), e.SecretVariables...)
+	sort.Strings(secretVariables)
+
+	return environmentFile{
+		Name:            e.Name,
+		BaseURL:         e.BaseURL,
+		AuthURL:         e.AuthURL,
+		TokenURL:        e.TokenURL,
+		ClientID:        e.ClientID,
+		RedirectURI:     e.RedirectURI,
+		Scope:           e.Scope,
+		Parent:          e.Parent,
+		Variables:       variables,
+		SecretVariables: secretVariables,
+	}
+}
+
+func requestFileName(req pkg.RequestData, used map[string]bool) string {
+	base := slug(req.Name)
+	if req.Name == "" {
+		base = slug(req.Method + " " + req.URL)
+	}
+	folder := slugPath(req.Folder)
+	name := base + ".json"
+	for i := 2; used[path.Join(folder, name)]; i++ {
+		name = fmt.Sprintf("%s-%d.json", base, i)
+	}
+	used[path.Join(folder, name)] = true
+	return name
+}
+
+func collectionNames(collections []db.Collection) []string {
+	names := make([]string, 0, len(collections))
+	for _, c := range collections {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// uniqueSlugs maps each name to a slug, suffixing clashes in sorted name
+// order so the same set of names always produces the same paths.
+func uniqueSlugs(names []string) map[string]string {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+
+	result := make(map[string]string, len(sorted))
+	used := make(map[string]bool, len(sorted))
+	for _, name := range sorted {
+		base := slug(name)
+		s := base
+		for i := 2; used[s]; i++ {
+			s = fmt.Sprintf("%s-%d", base, i)
+		}
+		used[s] = true
+		result[name] = s
+	}
+	return result
+}
+
+func slugPath(folder string) string {
+	if folder == "" {
+		return ""
+	}
+	parts := strings.Split(folder, "/")
+	for i, p := range parts {
+		parts[i] = slug(p)
+	}
+	return path.Join(parts...)
+}
+
+func slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	out := strings.TrimSuffix(b.String(), "-")
+	if out == "" {
+		out = "untitled"
+	}
+	return out
+}
+
+func marshalFile(v any) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,42 @@
+package filesync
+
+const (
+	collectionsDir     = "collections"
+	environmentsDir    = "environments"
+	collectionMetaFile = "_collection.json"
+)
+
+type Conflict struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+type Report struct {
+	Exported  []string   `json:"exported"`
+	Imported  []string   `json:"imported"`
+	Deleted   []string   `json:"deleted"`
+	Conflicts []Conflict `json:"conflicts"`
+}
+
+// collectionMeta keeps the collection's display name and request order,
+// which the file names alone cannot carry.
+type collectionMeta struct {
+	Name      string            `json:"name"`
+	Variables map[string]string `json:"variables"`
+	Requests  []string          `json:"requests"`
+}
+
+// environmentFile is the non-secret part of db.Environment. Secret variables
+// are listed by key only so an import knows not to clear them.
+type environmentFile struct {
+	Name            string            `json:"name"`
+	BaseURL         string            `json:"base_url"`
+	AuthURL         string            `json:"auth_url"`
+	TokenURL        string            `json:"token_url"`
+	ClientID        string            `json:"client_id"`
+	RedirectURI     string            `json:"redirect_uri"`
+	Scope           string            `json:"scope"`
+	Parent          string            `json:"parent,omitempty"`
+	Variables       map[string]string `json:"variables"`
+	SecretVariables []string          `json:"secret_variables,omitempty"`
+}
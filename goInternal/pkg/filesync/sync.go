@@ -0,0 +1,178 @@
+package filesync
+
+import (
+	"CommandPost/goInternal/pkg/db"
+	"CommandPost/goInternal/pkg/secrets"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Sync mirrors the workspace into dir and imports edits made there. Each
+// file is compared against the hash recorded at the previous sync: a side
+// that still matches it is stale and takes the other side's version, and a
+// file changed on both sides is reported as a conflict and left untouched.
+// The hashes are kept in the workspace database, keyed by the absolute
+// path of dir, never in dir: it is shared with other clones whose
+// databases are at a different point.
+func Sync(database *sql.DB, dbChan chan<- db.DbQuery, vault *secrets.Vault, dir string) (Report, error) {
+	report := Report{Exported: []string{}, Imported: []string{}, Deleted: []string{}, Conflicts: []Conflict{}}
+	if vault.Locked() {
+		return report, secrets.ErrLocked
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return report, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return report, err
+	}
+
+	local, err := renderWorkspace(database, vault)
+	if err != nil {
+		return report, err
+	}
+	disk, err := readDisk(dir)
+	if err != nil {
+		return report, err
+	}
+	base, err := db.LoadSyncState(database, dir)
+	if err != nil {
+		return report, fmt.Errorf("failed to read sync state: %w", err)
+	}
+
+	changes := make(map[string][]byte)
+	conflicts := make(map[string]bool)
+	for _, p := range unionPaths(local.files, disk, base) {
+		lh, dh, bh := hashOf(local.files, p), hashOf(disk, p), base[p]
+		switch {
+		case lh == dh:
+		case dh == bh:
+			if lh == "" {
+				report.Deleted = append(report.Deleted, p)
+			} else {
+				report.Exported = append(report.Exported, p)
+			}
+		case lh == bh:
+			changes[p] = disk[p]
+			if dh == "" {
+				report.Deleted = append(report.Deleted, p)
+			} else {
+				report.Imported = append(report.Imported, p)
+			}
+		default:
+			conflicts[p] = true
+			report.Conflicts = append(report.Conflicts, Conflict{Path: p, Reason: "changed both in the workspace and on disk"})
+		}
+	}
+
+	if len(changes) > 0 {
+		failed, err := applyChanges(dbChan, vault, local, changes)
+		if err != nil {
+			return report, err
+		}
+		for _, p := range unionPaths(nil, nil, failed) {
+			conflicts[p] = true
+			report.Conflicts = append(report.Conflicts, Conflict{Path: p, Reason: failed[p]})
+		}
+		report.Imported = withoutConflicts(report.Imported, conflicts)
+		report.Deleted = withoutConflicts(report.Deleted, conflicts)
+		if local, err = renderWorkspace(database, vault); err != nil {
+			return report, err
+		}
+	}
+
+	// The database is now authoritative for everything that is not in
+	// conflict, so disk is brought in line with a fresh render of it.
+	next := make(map[string]string)
+	for _, p := range unionPaths(local.files, disk, nil) {
+		if conflicts[p] {
+			if h, ok := base[p]; ok {
+				next[p] = h
+			}
+			continue
+		}
+		want, ok := local.files[p]
+		if !ok {
+			if err := removeFile(dir, p); err != nil {
+				return report, err
+			}
+			continue
+		}
+		if hashOf(disk, p) != hashContent(want) {
+			if err := writeFile(dir, p, want); err != nil {
+				return report, err
+			}
+		}
+		next[p] = hashContent(want)
+	}
+
+	return report, db.SaveSyncState(dbChan, dir, next)
+}
+
+func renderWorkspace(database *sql.DB, vault *secrets.Vault) (workspaceView, error) {
+	collections, err := db.LoadCollections(database)
+	if err != nil {
+		return workspaceView{}, err
+	}
+	environments, err := db.GetEnvironments(database, vault)
+	if err != nil {
+		return workspaceView{}, err
+	}
+
+	view := newWorkspaceView(collections, environments)
+	collectionFiles, err := renderCollections(collections)
+	if err != nil {
+		return view, err
+	}
+	environmentFiles, err := renderEnvironments(environments)
+	if err != nil {
+		return view, err
+	}
+	for p, data := range collectionFiles {
+		view.files[p] = data
+	}
+	for p, data := range environmentFiles {
+		view.files[p] = data
+	}
+	return view, nil
+}
+
+func unionPaths(a map[string][]byte, b map[string][]byte, c map[string]string) []string {
+	seen := make(map[string]bool)
+	for p := range a {
+		seen[p] = true
+	}
+	for p := range b {
+		seen[p] = true
+	}
+	for p := range c {
+		seen[p] = true
+	}
+	paths := make([]string, 0, len(seen))
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func withoutConflicts(paths []string, conflicts map[string]bool) []string {
+	kept := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if !conflicts[p] {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+func hashOf(files map[string][]byte, p string) string {
+	data, ok := files[p]
+	if !ok {
+		return ""
+	}
+	return hashContent(data)
+}
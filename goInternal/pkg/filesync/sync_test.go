@@ -0,0 +1,161 @@
+package filesync
+
+import (
+	"CommandPost/goInternal/pkg/db"
+	pkg "CommandPost/goInternal/pkg/inAppExec"
+	"CommandPost/goInternal/pkg/secrets"
+	"database/sql"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+type testWorkspace struct {
+	db     *sql.DB
+	dbChan chan db.DbQuery
+	vault  *secrets.Vault
+}
+
+func openTestWorkspace(t *testing.T) testWorkspace {
+	t.Helper()
+	dir := t.TempDir()
+	database, err := sql.Open("sqlite", filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.SetMaxOpenConns(1)
+	for _, create := range []func(*sql.DB) error{db.CreateCollectionsTable, db.CreateEnvironmentsTable, db.CreateVaultTable, db.CreateSyncStateTable} {
+		if err := create(database); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dbChan := make(chan db.DbQuery, 10)
+	done := make(chan struct{})
+	go func() {
+		db.DbWorker(database, dbChan)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		close(dbChan)
+		<-done
+		database.Close()
+	})
+
+	_, c, err := secrets.NewVaultConfig(secrets.ModeKeyFile, filepath.Join(dir, "test.key"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vault := secrets.NewVault()
+	vault.Unlock(c)
+	return testWorkspace{db: database, dbChan: dbChan, vault: vault}
+}
+
+func (w testWorkspace) sync(t *testing.T, dir string) Report {
+	t.Helper()
+	report, err := Sync(w.db, w.dbChan, w.vault, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func (w testWorkspace) url(t *testing.T, collection string) string {
+	t.Helper()
+	collections, err := db.LoadCollections(w.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range collections {
+		if c.Name == collection && len(c.Requests) == 1 {
+			return c.Requests[0].URL
+		}
+	}
+	t.Fatalf("collection %s with one request not found in %+v", collection, collections)
+	return ""
+}
+
+// pull replaces dst with the content of src, as a git pull of the other
+// clone's commit would.
+func pull(t *testing.T, src, dst string) {
+	t.Helper()
+	if err := os.RemoveAll(dst); err != nil {
+		t.Fatal(err)
+	}
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, p)
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), data, 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSyncImportsPulledChanges has two clones share collections through a
+// repository: a change pulled into the second clone, whose own database is
+// untouched, must be imported rather than overwritten.
+func TestSyncImportsPulledChanges(t *testing.T) {
+	alice, bob := openTestWorkspace(t), openTestWorkspace(t)
+	aliceDir, bobDir := t.TempDir(), filepath.Join(t.TempDir(), "clone")
+
+	save := func(w testWorkspace, url string) {
+		t.Helper()
+		if err := db.SaveCollection(w.dbChan, "pets", []pkg.RequestData{{Name: "list", Method: "GET", URL: url}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	save(alice, "https://api.example.com/v1/pets")
+	alice.sync(t, aliceDir)
+	pull(t, aliceDir, bobDir)
+	if report := bob.sync(t, bobDir); len(report.Imported) == 0 || len(report.Conflicts) > 0 {
+		t.Fatalf("first sync of the clone = %+v, want an import", report)
+	}
+
+	save(alice, "https://api.example.com/v2/pets")
+	alice.sync(t, aliceDir)
+	pull(t, aliceDir, bobDir)
+
+	report := bob.sync(t, bobDir)
+	if len(report.Exported) > 0 || len(report.Conflicts) > 0 || len(report.Imported) != 1 {
+		t.Fatalf("sync after pull = %+v, want one import", report)
+	}
+	if got := bob.url(t, "pets"); got != "https://api.example.com/v2/pets" {
+		t.Errorf("clone's request URL = %s, want the pulled v2 URL", got)
+	}
+	disk, err := readDisk(bobDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := readDisk(aliceDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p, data := range want {
+		if string(disk[p]) != string(data) {
+			t.Errorf("%s was rewritten on disk:\n%s", p, disk[p])
+		}
+	}
+
+	entries, err := os.ReadDir(bobDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != collectionsDir && e.Name() != environmentsDir {
+			t.Errorf("sync wrote %s into the shared directory", e.Name())
+		}
+	}
+}
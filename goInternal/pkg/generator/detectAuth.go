@@ -1,15 +1,30 @@
 package generator
 
 import (
+	"CommandPost/goInternal/pkg/oauth"
+	"sort"
+	"strings"
+
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
 type AuthScheme struct {
-	Name       string // The key in securitySchemes
-	Type       string // "http", "apiKey"
-	Scheme     string // "bearer", "basic" etc (only for http)
-	In         string // "header", "query", "cookie" (for apiKey)
-	HeaderName string // Actual header name (for apiKey) or "Authorization" (for bearer)
+	Name       string      // The key in securitySchemes
+	Type       string      // "http", "apiKey", "oauth2"
+	Scheme     string      // "bearer", "basic" etc (only for http)
+	In         string      // "header", "query", "cookie" (for apiKey)
+	HeaderName string      // Actual header name (for apiKey) or "Authorization" (for bearer)
+	Issuer     string      // OpenID Connect issuer, whose discovery document supplies the endpoints
+	Flows      []OAuthFlow // OAuth2 flows the generated login command can run
+}
+
+type OAuthFlow struct {
+	Grant         string
+	AuthURL       string
+	TokenURL      string
+	RefreshURL    string
+	DeviceAuthURL string
+	Scopes        []string
 }
 
 func DetectAuth(doc *openapi3.T) map[string]AuthScheme {
@@ -25,11 +40,12 @@ func DetectAuth(doc *openapi3.T) map[string]AuthScheme {
 
 		switch s.Type {
 		case "http":
-			if s.Scheme == "bearer" {
+			switch strings.ToLower(s.Scheme) {
+			case "bearer", "basic":
 				schemes[name] = AuthScheme{
 					Name:       name,
 					Type:       "http",
-					Scheme:     "bearer",
+					Scheme:     strings.ToLower(s.Scheme),
 					In:         "header",
 					HeaderName: "Authorization",
 				}
@@ -37,10 +53,24 @@ func DetectAuth(doc *openapi3.T) map[string]AuthScheme {
 		case "oauth2":
 			schemes[name] = AuthScheme{
 				Name:       name,
-				Type:       "http",
-				Scheme:     "bearer",
+				Type:       "oauth2",
 				In:         "header",
 				HeaderName: "Authorization",
+				Flows:      oauthFlows(s.Flows),
+			}
+		case "openIdConnect":
+			// The flows come from the provider's discovery document.
+			schemes[name] = AuthScheme{
+				Name:       name,
+				Type:       "oauth2",
+				In:         "header",
+				HeaderName: "Authorization",
+				Issuer:     strings.TrimSuffix(strings.TrimRight(s.OpenIdConnectUrl, "/"), "/.well-known/openid-configuration"),
+				Flows: []OAuthFlow{
+					{Grant: oauth.GrantAuthorizationCode},
+					{Grant: oauth.GrantDeviceCode},
+					{Grant: oauth.GrantClientCredentials},
+				},
 			}
 		case "apiKey":
 			schemes[name] = AuthScheme{
@@ -53,3 +83,59 @@ func DetectAuth(doc *openapi3.T) map[string]AuthScheme {
 	}
 	return schemes
 }
+
+// oauthFlows lists the flows a CLI can log in with, most interactive first.
+// The implicit flow is left out, as it cannot be run without a browser page
+// to receive the token. The device authorization flow is the one added in
+// OpenAPI 3.2, which 3.0 documents can only carry as an unknown key.
+func oauthFlows(flows *openapi3.OAuthFlows) []OAuthFlow {
+	if flows == nil {
+		return nil
+	}
+	var out []OAuthFlow
+	add := func(grant string, f *openapi3.OAuthFlow) {
+		if f != nil {
+			out = append(out, OAuthFlow{
+				Grant:      grant,
+				AuthURL:    f.AuthorizationURL,
+				TokenURL:   f.TokenURL,
+				RefreshURL: f.RefreshURL,
+				Scopes:     sortedKeys(f.Scopes),
+			})
+		}
+	}
+	add(oauth.GrantAuthorizationCode, flows.AuthorizationCode)
+	if device, ok := flows.Extensions["deviceAuthorization"].(map[string]interface{}); ok {
+		f := OAuthFlow{Grant: oauth.GrantDeviceCode}
+		f.DeviceAuthURL, _ = device["deviceAuthorizationUrl"].(string)
+		f.TokenURL, _ = device["tokenUrl"].(string)
+		f.RefreshURL, _ = device["refreshUrl"].(string)
+		if scopes, ok := device["scopes"].(map[string]interface{}); ok {
+			for scope := range scopes {
+				f.Scopes = append(f.Scopes, scope)
+			}
+			sort.Strings(f.Scopes)
+		}
+		out = append(out, f)
+	}
+	add(oauth.GrantClientCredentials, flows.ClientCredentials)
+	add(oauth.GrantPassword, flows.Password)
+	return out
+}
+
+// authField and authKey name a scheme's field in the generated Config and
+// its key in the config file (or, upper-cased and prefixed with the app
+// name, its environment variable).
+func authField(scheme AuthScheme, suffix string) string {
+	return toGoName(scheme.Name) + suffix
+}
+
+func authKey(scheme AuthScheme, suffix string) string {
+	return strings.ReplaceAll(toCLIName(scheme.Name), "-", "_") + "_" + suffix
+}
+
+// canLogin reports whether the generated login command supports s. OAuth2
+// schemes with only the implicit flow fall back to a static bearer token.
+func canLogin(s AuthScheme) bool {
+	return s.Type == "oauth2" && len(s.Flows) > 0
+}
@@ -0,0 +1,18 @@
+package generator
+
+import "testing"
+
+func TestErrorResponses(t *testing.T) {
+	out := generateTestdata(t, "errors.yaml", Config{})
+	// error schemas are keyed by code, range or default
+	assertContains(t, out, "cmd/geterr.go",
+		`errorSchemas := utils.ErrorSchemas{"404": func() interface{} { return new(models.Problem) }, "4XX": func() interface{} { return new(models.Message) }}`,
+		"return &utils.NetworkError{Err: err}",
+	)
+	assertContains(t, out, "cmd/root.go",
+		`cmd.PersistentFlags().StringSliceVar(&FailOn, "fail-on", []string{"4xx", "5xx"},`,
+		"return printOptions().Validate()",
+	)
+	assertContains(t, out, "main.go", "os.Exit(utils.ReportError(err))")
+	testGeneratedPackage(t, out, "utils", "errors_test.go")
+}
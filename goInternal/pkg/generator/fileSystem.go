@@ -30,8 +30,10 @@ func writeGoMod(outputDir, moduleName string) error {
 go 1.22
 
 require (
+	github.com/joho/godotenv v1.5.1
 	github.com/spf13/cobra v1.8.0
 	github.com/spf13/viper v1.19.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 `, moduleName)
 
@@ -39,8 +41,11 @@ require (
 	return os.WriteFile(path, []byte(goModContent), 0644)
 }
 
-func writeMain(outputDir string, moduleName string, tagToCmds map[string][]CommandInfo) error {
+func writeMain(outputDir string, moduleName string, tagToCmds map[string][]CommandInfo, hasLogin bool) error {
 	cmdsInit := ""
+	if hasLogin {
+		cmdsInit += "\trootCmd.AddCommand(cmd.NewLoginCmd(), cmd.NewLogoutCmd())\n"
+	}
 	for tag, cmds := range tagToCmds {
 		sanitizedTagGo := sanitizeTagName(tag)
 		sanitizedTagCLI := sanitizeTagCLIName(tag)
@@ -57,20 +62,25 @@ func writeMain(outputDir string, moduleName string, tagToCmds map[string][]Comma
 package main
 
 import (
+	"os"
+
 	"%s/cmd"
-	"github.com/spf13/cobra"
+	"%s/utils"
 )
 
 func main() {
 	rootCmd := cmd.NewRootCmd()
+	rootCmd.AddCommand(cmd.NewServersCmd())
 %s
 
 	rootCmd.CompletionOptions.DisableDefaultCmd = false
 	rootCmd.CompletionOptions.HiddenDefaultCmd = false
 
-	cobra.CheckErr(rootCmd.Execute())
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(utils.ReportError(err))
+	}
 }
-`, moduleName, cmdsInit)
+`, moduleName, moduleName, cmdsInit)
 
 	path := filepath.Join(outputDir, "main.go")
 	return os.WriteFile(path, []byte(mainCode), 0644)
@@ -1,44 +1,35 @@
 package generator
 
 import (
+	"CommandPost/goInternal/pkg/spec"
 	"fmt"
-	"net/url"
+	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
-type Generator struct{}
+type Generator struct {
+	Config Config
+	Loader *spec.Loader
+}
 
 type CommandInfo struct {
 	GoName  string
 	CLIName string
+	Aliases []string
+	Hidden  bool
 }
 
 func NewGenerator() *Generator {
 	return &Generator{}
 }
 
-func IsURL(s string) bool {
-	u, err := url.Parse(s)
-	return err == nil && u.Scheme != "" && u.Host != ""
-}
-
 func (g *Generator) Generate(specPath, outputDir string, moduleName string) error {
-	loader := openapi3.NewLoader()
-
-	var doc *openapi3.T
-	var err error
-
-	if IsURL(specPath) {
-		parsedURL, err := url.Parse(specPath)
-		if err != nil {
-			return fmt.Errorf("invalid URL: %w", err)
-		}
-		doc, err = loader.LoadFromURI(parsedURL)
-	} else {
-		doc, err = loader.LoadFromFile(specPath)
+	loader := g.Loader
+	if loader == nil {
+		loader = spec.NewLoader()
 	}
-
+	doc, err := loader.Load(specPath)
 	if err != nil {
 		return fmt.Errorf("failed to load spec: %w", err)
 	}
@@ -53,9 +44,6 @@ func (g *Generator) Generate(specPath, outputDir string, moduleName string) erro
 		return err
 	}
 
-	if err := writeConfig(outputDir, schemes, doc.Servers, specPath); err != nil {
-		return err
-	}
 	if err := writeRootCmd(outputDir, moduleName); err != nil {
 		return err
 	}
@@ -65,41 +53,76 @@ func (g *Generator) Generate(specPath, outputDir string, moduleName string) erro
 	}
 
 	tagToCmds := make(map[string][]CommandInfo)
-	for path, pathItem := range doc.Paths.Map() {
-		ops := map[string]*openapi3.Operation{
-			"get":    pathItem.Get,
-			"post":   pathItem.Post,
-			"put":    pathItem.Put,
-			"delete": pathItem.Delete,
+	overrides := make(map[string]openapi3.Servers)
+	names := newCommandNames()
+	paths := doc.Paths.Map()
+	for _, path := range sortedKeys(paths) {
+		pathItem := paths[path]
+		if pathItem == nil {
+			return fmt.Errorf("path %s has no definition", path)
 		}
+		ops := pathItem.Operations()
 
-		for method, op := range ops {
-			if op == nil {
-				continue
-			}
+		for _, method := range sortedKeys(ops) {
+			op := ops[method]
+			method = strings.ToLower(method)
+			operation := strings.ToUpper(method) + " " + path
 
 			tag := "Misc"
 			if len(op.Tags) > 0 {
 				tag = op.Tags[0]
 			}
 
-			goName := sanitizeCommandName(path, method)
-			cliName := sanitizeCLIName(path, method)
-			if err := writeEndpointCmd(outputDir, moduleName, goName, cliName, op, path, method, schemes, &doc.Security); err != nil {
+			info, explicit, err := commandInfo(path, method, op, g.Config.override(path, method, op))
+			if err != nil {
+				return fmt.Errorf("%s: %w", operation, err)
+			}
+			if err := names.claimTag(tag); err != nil {
 				return err
 			}
+			if names.conflict(tag, info) != "" && !explicit {
+				info = legacyCommandInfo(path, method, info)
+			}
+			if other := names.conflict(tag, info); other != "" {
+				return fmt.Errorf("%s: command %s %s (%s) collides with %s; rename one with x-cli-name or a commands entry in the generator config",
+					operation, sanitizeTagCLIName(tag), info.CLIName, info.GoName, other)
+			}
+			names.claim(tag, info, operation)
+
+			params, err := operationParameters(path, pathItem, op)
+			if err != nil {
+				return fmt.Errorf("%s: %w", operation, err)
+			}
+			servers := "config.Servers"
+			if override := commandServers(pathItem, op); override != nil {
+				key := sanitizeTagCLIName(tag) + " " + info.CLIName
+				overrides[key] = override
+				servers = fmt.Sprintf("config.CommandServers[%q]", key)
+			}
+			if err := writeEndpointCmd(outputDir, moduleName, info, op, params, path, method, servers, schemes, &doc.Security); err != nil {
+				return fmt.Errorf("%s: %w", operation, err)
+			}
 
-			tagToCmds[tag] = append(tagToCmds[tag], CommandInfo{GoName: goName, CLIName: cliName})
+			tagToCmds[tag] = append(tagToCmds[tag], info)
 		}
 	}
 
+	if err := writeConfig(outputDir, schemes, doc.Servers, overrides); err != nil {
+		return err
+	}
+
 	for tag := range tagToCmds {
 		if err := writeTagCmd(outputDir, tag); err != nil {
 			return err
 		}
 	}
 
-	if err := writeMain(outputDir, moduleName, tagToCmds); err != nil {
+	hasLogin, err := writeAuth(outputDir, moduleName, schemes)
+	if err != nil {
+		return err
+	}
+
+	if err := writeMain(outputDir, moduleName, tagToCmds, hasLogin); err != nil {
 		return err
 	}
 
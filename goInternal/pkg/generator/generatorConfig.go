@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/oasdiff/yaml"
+)
+
+// Config holds the generator settings read from a YAML or JSON file.
+type Config struct {
+	// Commands overrides command naming, keyed by operationId or by
+	// "METHOD /path".
+	Commands map[string]CommandOverride `json:"commands,omitempty"`
+}
+
+type CommandOverride struct {
+	Name    string   `json:"name,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+	Hidden  *bool    `json:"hidden,omitempty"`
+}
+
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read generator config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid generator config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// override returns the config entry for an operation, preferring the
+// operationId key over the method and path.
+func (c Config) override(path, method string, op *openapi3.Operation) CommandOverride {
+	if op.OperationID != "" {
+		if o, ok := c.Commands[op.OperationID]; ok {
+			return o
+		}
+	}
+	return c.Commands[strings.ToUpper(method)+" "+path]
+}
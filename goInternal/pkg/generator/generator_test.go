@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// generateTestdata generates the CLI for testdata/<spec> into a temporary
+// directory and returns it.
+func generateTestdata(t *testing.T, spec string, cfg Config) string {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), "out")
+	g := NewGenerator()
+	g.Config = cfg
+	if err := g.Generate(filepath.Join("testdata", spec), out, "testcli"); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// emitted returns a generated file, relative to the output directory.
+func emitted(t *testing.T, out, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(rel)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// assertContains fails for each snippet missing from the generated file rel.
+func assertContains(t *testing.T, out, rel string, snippets ...string) {
+	t.Helper()
+	code := emitted(t, out, rel)
+	for _, s := range snippets {
+		if !strings.Contains(code, s) {
+			t.Errorf("%s does not contain %q", rel, s)
+		}
+	}
+}
+
+// testGeneratedPackage runs the tests in testdata against the generated
+// package pkg, copied on its own into a scratch module. Only packages
+// without third-party imports other than yaml.v3 can be tested this way.
+func testGeneratedPackage(t *testing.T, out, pkg string, tests ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module testcli\n\ngo 1.22\n\nrequire gopkg.in/yaml.v3 v3.0.1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// the checksums are those of this module's own yaml.v3 dependency
+	sums, err := os.ReadFile(filepath.Join("..", "..", "..", "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.sum"), sums, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pkgDir := filepath.Join(dir, pkg)
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join(out, pkg, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		files = append(files, filepath.Join("testdata", test))
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(pkgDir, filepath.Base(f)), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	goTest(t, dir, "./"+pkg)
+}
+
+func goTest(t *testing.T, dir string, pkgs ...string) {
+	t.Helper()
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not found")
+	}
+	cmd := exec.Command(goBin, append([]string{"test", "-count=1"}, pkgs...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOTOOLCHAIN=local")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+}
+
+func TestGenerateSwagger2(t *testing.T) {
+	out := generateTestdata(t, "swagger2.yaml", Config{})
+	// host, basePath and schemes make the server
+	assertContains(t, out, "config/config.go",
+		`{Name: "https://api.example.com/v2", URL: "https://api.example.com/v2", Description: ""},`,
+		"KeyAuth      string",
+	)
+	assertContains(t, out, "cmd/getitem.go",
+		`Use:   "get-item"`,
+		`req.Header.Set("X-Key", cfg.KeyAuth)`,
+	)
+	// formData parameters become a form body
+	assertContains(t, out, "cmd/putitem.go",
+		`bodyContentType := "application/x-www-form-urlencoded"`,
+		`form.Set("name", body_name)`,
+	)
+	// definitions become models; x-nullable ones stay pointers
+	assertContains(t, out, "models/models.go",
+		"type Item struct {",
+		"N  *int    `json:\"n,omitempty\"`",
+	)
+}
+
+func TestGenerateOpenAPI31(t *testing.T) {
+	out := generateTestdata(t, "openapi31.yaml", Config{})
+	assertContains(t, out, "cmd/listthings.go", `Use:   "list-things"`)
+	assertContains(t, out, "models/models.go",
+		// type arrays, with or without "null"
+		"Id    string     `json:\"id\"`",
+		"Label *string    `json:\"label,omitempty\"`",
+		// const is a single-value enum
+		`ThingKindThing ThingKind = "thing"`,
+	)
+	testGeneratedPackage(t, out, "models")
+}
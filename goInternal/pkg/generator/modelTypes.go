@@ -0,0 +1,215 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const componentSchemaPrefix = "#/components/schemas/"
+
+// schemaTypeName is the Go type generated for a component schema.
+func schemaTypeName(name string) string {
+	typeName := toGoName(name)
+	if typeName == "" || !unicode.IsLetter([]rune(typeName)[0]) {
+		typeName = "Model" + typeName
+	}
+	return typeName
+}
+
+// modelTypeName returns the Go type of a $ref to a component schema. Refs
+// into other locations have no named type of their own.
+func modelTypeName(ref string) (string, bool) {
+	name, ok := strings.CutPrefix(ref, componentSchemaPrefix)
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return schemaTypeName(name), true
+}
+
+// schemaType is the schema's type, ignoring the "null" member of an
+// OpenAPI 3.1 type array.
+func schemaType(s *openapi3.Schema) string {
+	if s == nil || s.Type == nil {
+		return ""
+	}
+	for _, t := range *s.Type {
+		if t != "null" {
+			return t
+		}
+	}
+	return ""
+}
+
+func isNullable(s *openapi3.Schema) bool {
+	return s != nil && (s.Nullable || (s.Type != nil && s.Type.Includes("null")))
+}
+
+func isScalar(s *openapi3.Schema) bool {
+	switch schemaType(s) {
+	case "string", "integer", "number", "boolean":
+		return true
+	}
+	return false
+}
+
+func isComposite(s *openapi3.Schema) bool {
+	return len(s.AllOf) > 0 || len(s.OneOf) > 0 || len(s.AnyOf) > 0
+}
+
+func isStruct(s *openapi3.Schema) bool {
+	t := schemaType(s)
+	return (t == "object" || t == "") && len(s.Properties) > 0
+}
+
+func hasExtraProperties(s *openapi3.Schema) bool {
+	ap := s.AdditionalProperties
+	return ap.Schema != nil || (ap.Has != nil && *ap.Has)
+}
+
+// hasCustomJSON reports whether the type generated for s has its own JSON
+// methods, which would be promoted (and take over) if it were embedded.
+func hasCustomJSON(s *openapi3.Schema) bool {
+	return len(s.OneOf) > 0 || len(s.AnyOf) > 0 || (isStruct(s) && hasExtraProperties(s))
+}
+
+// needsNamedType reports whether an inline schema has to be declared as a
+// type of its own rather than spelled out where it is used.
+func needsNamedType(s *openapi3.Schema) bool {
+	return isComposite(s) || (len(s.Enum) > 0 && isScalar(s)) || isStruct(s)
+}
+
+// pointerable reports whether an optional or nullable field of this schema
+// is generated as a pointer. Slices, maps and interfaces already have nil.
+func pointerable(ref *openapi3.SchemaRef) bool {
+	if ref == nil || ref.Value == nil {
+		return false
+	}
+	s := ref.Value
+	if isComposite(s) {
+		return true
+	}
+	switch schemaType(s) {
+	case "array":
+		return false
+	case "object", "":
+		return isStruct(s)
+	}
+	return true
+}
+
+// goType returns the Go type for a schema, declaring named types for
+// inline objects, enums and compositions under a name derived from owner.
+func (w *modelWriter) goType(owner string, ref *openapi3.SchemaRef) (string, error) {
+	if ref == nil {
+		return "interface{}", nil
+	}
+	if ref.Ref != "" {
+		if name, ok := modelTypeName(ref.Ref); ok {
+			return name, nil
+		}
+		if name, ok := w.external[ref.Ref]; ok {
+			return name, nil
+		}
+		if ref.Value == nil {
+			return "", fmt.Errorf("unresolved $ref %s", ref.Ref)
+		}
+		parts := strings.Split(ref.Ref, "/")
+		name := w.newName(schemaTypeName(parts[len(parts)-1]))
+		w.external[ref.Ref] = name
+		return name, w.declare(name, &openapi3.SchemaRef{Value: ref.Value})
+	}
+	s := ref.Value
+	if s == nil {
+		return "interface{}", nil
+	}
+	if needsNamedType(s) {
+		name := w.newName(owner)
+		return name, w.declare(name, ref)
+	}
+
+	switch schemaType(s) {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			w.imports["time"] = true
+			return "time.Time", nil
+		case "byte":
+			return "[]byte", nil
+		}
+		return "string", nil
+	case "integer":
+		switch s.Format {
+		case "int32":
+			return "int32", nil
+		case "int64":
+			return "int64", nil
+		}
+		return "int", nil
+	case "number":
+		switch s.Format {
+		case "float":
+			return "float32", nil
+		case "int32":
+			return "int32", nil
+		case "int64":
+			return "int64", nil
+		}
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		item, err := w.goType(owner+"Item", s.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	case "object":
+		return w.mapType(owner, s)
+	}
+	if hasExtraProperties(s) {
+		return w.mapType(owner, s)
+	}
+	return "interface{}", nil
+}
+
+func (w *modelWriter) mapType(owner string, s *openapi3.Schema) (string, error) {
+	if s.AdditionalProperties.Schema == nil {
+		return "map[string]interface{}", nil
+	}
+	value, err := w.goType(owner+"Value", s.AdditionalProperties.Schema)
+	if err != nil {
+		return "", err
+	}
+	return "map[string]" + value, nil
+}
+
+// newName returns base, or base with a number appended if a type or
+// constant of that name already exists.
+func (w *modelWriter) newName(base string) string {
+	name := base
+	for i := 2; w.idents[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	w.idents[name] = true
+	return name
+}
+
+// fieldName returns a unique exported Go identifier for a JSON name within
+// one struct.
+func fieldName(used map[string]bool, jsonName string) string {
+	base := toGoName(jsonName)
+	if base == "" {
+		base = "Field"
+	} else if !unicode.IsLetter([]rune(base)[0]) {
+		base = "F" + base
+	}
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	used[name] = true
+	return name
+}
@@ -0,0 +1,169 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type unionVariant struct {
+	field    string
+	goType   string
+	jsonName string
+}
+
+// declareUnion emits a struct with one pointer field per oneOf/anyOf
+// variant. Decoding uses the discriminator when the schema has one and
+// otherwise takes the first variant the data decodes into strictly.
+func (w *modelWriter) declareUnion(name string, s *openapi3.Schema, members openapi3.SchemaRefs) (string, error) {
+	w.imports["encoding/json"] = true
+	w.imports["fmt"] = true
+
+	used := make(map[string]bool)
+	variants := make([]unionVariant, 0, len(members))
+	for i, member := range members {
+		owner := fmt.Sprintf("%sOption%d", name, i+1)
+		goType, err := w.goType(owner, member)
+		if err != nil {
+			return "", fmt.Errorf("variant %d: %w", i+1, err)
+		}
+		v := unionVariant{goType: goType}
+		switch {
+		case goType == owner:
+			v.field = fieldName(used, fmt.Sprintf("Option%d", i+1))
+		case goType == "time.Time":
+			v.field = fieldName(used, "Time")
+		case goType == "interface{}":
+			v.field = fieldName(used, "Value")
+		case strings.HasPrefix(goType, "map["):
+			v.field = fieldName(used, "Map")
+		default:
+			v.field = fieldName(used, strings.TrimLeft(goType, "[]")+strings.Repeat("List", strings.Count(goType, "[]")))
+		}
+		if member != nil {
+			if component, ok := strings.CutPrefix(member.Ref, componentSchemaPrefix); ok {
+				v.jsonName = component
+			}
+		}
+		variants = append(variants, v)
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("type %s struct {\n", name))
+	for _, v := range variants {
+		b.WriteString(fmt.Sprintf("\t%s *%s\n", v.field, v.goType))
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString(fmt.Sprintf("func (u *%s) UnmarshalJSON(data []byte) error {\n\t*u = %s{}\n", name, name))
+	if d := s.Discriminator; d != nil && d.PropertyName != "" {
+		b.WriteString(w.discriminatorDecode(name, d, variants))
+	} else {
+		w.strictHelper = true
+		w.imports["bytes"] = true
+		for _, v := range variants {
+			b.WriteString(fmt.Sprintf("\tif v, err := decodeStrict[%s](data); err == nil {\n\t\tu.%s = v\n\t\treturn nil\n\t}\n", v.goType, v.field))
+		}
+		b.WriteString(fmt.Sprintf("\treturn fmt.Errorf(\"%s: value matches none of the variants\")\n", name))
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString(fmt.Sprintf("func (u %s) MarshalJSON() ([]byte, error) {\n\tswitch {\n", name))
+	for _, v := range variants {
+		b.WriteString(fmt.Sprintf("\tcase u.%s != nil:\n\t\treturn json.Marshal(u.%s)\n", v.field, v.field))
+	}
+	b.WriteString("\t}\n\treturn []byte(\"null\"), nil\n}\n")
+
+	return b.String(), nil
+}
+
+func (w *modelWriter) discriminatorDecode(name string, d *openapi3.Discriminator, variants []unionVariant) string {
+	values := make(map[string][]string)
+	mapped := make(map[string]bool)
+	for _, value := range sortedKeys(d.Mapping) {
+		target := d.Mapping[value]
+		if !strings.Contains(target, "/") {
+			target = componentSchemaPrefix + target
+		}
+		for _, v := range variants {
+			if v.jsonName != "" && componentSchemaPrefix+v.jsonName == target {
+				values[v.field] = append(values[v.field], value)
+				mapped[v.field] = true
+			}
+		}
+	}
+	for _, v := range variants {
+		if !mapped[v.field] && v.jsonName != "" {
+			values[v.field] = []string{v.jsonName}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\tvar probe struct {\n\t\tKind string `json:%q`\n\t}\n", d.PropertyName))
+	b.WriteString("\tif err := json.Unmarshal(data, &probe); err != nil {\n\t\treturn err\n\t}\n\tswitch probe.Kind {\n")
+	for _, v := range variants {
+		if len(values[v.field]) == 0 {
+			continue
+		}
+		quoted := make([]string, len(values[v.field]))
+		for i, value := range values[v.field] {
+			quoted[i] = fmt.Sprintf("%q", value)
+		}
+		b.WriteString(fmt.Sprintf("\tcase %s:\n\t\tu.%s = new(%s)\n\t\treturn json.Unmarshal(data, u.%s)\n", strings.Join(quoted, ", "), v.field, v.goType, v.field))
+	}
+	b.WriteString("\t}\n")
+	b.WriteString(fmt.Sprintf("\treturn fmt.Errorf(\"%s: unknown %s %%q\", probe.Kind)\n", name, d.PropertyName))
+	return b.String()
+}
+
+const decodeStrictHelper = `
+// decodeStrict decodes data into a new T, rejecting unknown fields so that
+// union variants are told apart by their properties.
+func decodeStrict[T any](data []byte) (*T, error) {
+	v := new(T)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+`
+
+// declareEnum emits a named scalar type and a constant per enum value.
+func (w *modelWriter) declareEnum(name string, s *openapi3.Schema) (string, error) {
+	base := "string"
+	if schemaType(s) != "string" {
+		plain := *s
+		plain.Enum = nil
+		var err error
+		if base, err = w.goType(name, &openapi3.SchemaRef{Value: &plain}); err != nil {
+			return "", err
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("type %s %s\n\nconst (\n", name, base))
+	for i, value := range s.Enum {
+		if value == nil {
+			continue
+		}
+		literal := fmt.Sprintf("%v", value)
+		suffix := toGoName(literal)
+		switch v := value.(type) {
+		case string:
+			literal = fmt.Sprintf("%q", v)
+		case float64:
+			if v < 0 {
+				suffix = "Minus" + suffix
+			}
+		}
+		if suffix == "" {
+			suffix = fmt.Sprintf("Value%d", i+1)
+		}
+		b.WriteString(fmt.Sprintf("\t%s %s = %s\n", w.newName(name+suffix), name, literal))
+	}
+	b.WriteString(")\n")
+	return b.String(), nil
+}
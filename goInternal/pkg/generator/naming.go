@@ -0,0 +1,251 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// commandInfo names the command for an operation. Names come from, in
+// order, the generator config, the x-cli-name extension, the operationId
+// and finally a verb-noun guess from the method and path. explicit reports
+// whether the name was chosen by the spec author rather than guessed.
+func commandInfo(path, method string, op *openapi3.Operation, override CommandOverride) (info CommandInfo, explicit bool, err error) {
+	name := override.Name
+	if name == "" {
+		if name, err = stringExtension(op, "x-cli-name"); err != nil {
+			return info, false, err
+		}
+	}
+
+	switch {
+	case name != "":
+		info.GoName = toGoName(name)
+		info.CLIName = toKebab(name)
+		explicit = true
+	case op.OperationID != "":
+		info.GoName = toGoName(op.OperationID)
+		info.CLIName = toKebab(op.OperationID)
+		explicit = true
+	default:
+		info.CLIName = verbNounName(path, method)
+		info.GoName = toGoName(info.CLIName)
+	}
+	if info.GoName == "" || info.CLIName == "" {
+		return info, false, fmt.Errorf("cannot derive a command name from %q", name+op.OperationID)
+	}
+	if !unicode.IsLetter([]rune(info.GoName)[0]) {
+		info.GoName = "Op" + info.GoName
+	}
+
+	if override.Aliases != nil {
+		// copied, as the override belongs to the caller's config
+		info.Aliases = slices.Clone(override.Aliases)
+	} else if info.Aliases, err = aliasesExtension(op); err != nil {
+		return info, false, err
+	}
+	for i, a := range info.Aliases {
+		info.Aliases[i] = toKebab(a)
+	}
+
+	if override.Hidden != nil {
+		info.Hidden = *override.Hidden
+	} else if v, ok := op.Extensions["x-cli-hidden"]; ok {
+		hidden, ok := v.(bool)
+		if !ok {
+			return info, false, fmt.Errorf("x-cli-hidden must be a boolean")
+		}
+		info.Hidden = hidden
+	}
+	return info, explicit, nil
+}
+
+// legacyCommandInfo is the method and path based name, which is unique per
+// operation and used when a guessed name collides.
+func legacyCommandInfo(path, method string, info CommandInfo) CommandInfo {
+	info.GoName = sanitizeCommandName(path, method)
+	info.CLIName = sanitizeCLIName(path, method)
+	return info
+}
+
+func stringExtension(op *openapi3.Operation, key string) (string, error) {
+	v, ok := op.Extensions[key]
+	if !ok {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", key)
+	}
+	return strings.TrimSpace(s), nil
+}
+
+func aliasesExtension(op *openapi3.Operation) ([]string, error) {
+	v, ok := op.Extensions["x-cli-aliases"]
+	if !ok {
+		return nil, nil
+	}
+	switch v := v.(type) {
+	case string:
+		return strings.FieldsFunc(v, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }), nil
+	case []interface{}:
+		aliases := make([]string, 0, len(v))
+		for _, a := range v {
+			s, ok := a.(string)
+			if !ok {
+				return nil, fmt.Errorf("x-cli-aliases must be a list of strings")
+			}
+			aliases = append(aliases, s)
+		}
+		return aliases, nil
+	}
+	return nil, fmt.Errorf("x-cli-aliases must be a list of strings")
+}
+
+// verbNounName guesses a name such as list-users or get-user from the
+// method and the last static path segment.
+func verbNounName(path, method string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	noun := ""
+	onItem := false
+	for _, s := range segments {
+		if strings.HasPrefix(s, "{") {
+			onItem = noun != ""
+			continue
+		}
+		if s != "" {
+			noun = toKebab(s)
+			onItem = false
+		}
+	}
+	if noun == "" {
+		noun = "root"
+	}
+
+	var verb string
+	switch strings.ToUpper(method) {
+	case "GET":
+		verb = "list"
+		if onItem {
+			verb = "get"
+		}
+	case "POST":
+		verb = "create"
+		onItem = true
+	case "PUT", "PATCH":
+		verb = "update"
+	case "DELETE":
+		verb = "delete"
+	default:
+		verb = strings.ToLower(method)
+	}
+	if onItem {
+		noun = singular(noun)
+	}
+	return verb + "-" + noun
+}
+
+func singular(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies"):
+		return strings.TrimSuffix(s, "ies") + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "s") && !strings.HasSuffix(s, "ss"):
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+// toKebab splits camelCase and any non-alphanumeric separators, so
+// listUserOrders and list_user_orders both become list-user-orders.
+func toKebab(s string) string {
+	var words []string
+	var cur []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+			if len(cur) > 0 {
+				words = append(words, string(cur))
+				cur = nil
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && len(cur) > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsNumber(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(cur))
+				cur = nil
+			}
+		}
+		cur = append(cur, unicode.ToLower(r))
+	}
+	if len(cur) > 0 {
+		words = append(words, string(cur))
+	}
+	return strings.Join(words, "-")
+}
+
+// commandNames tracks the names already generated. Go names share one
+// package (and, lowercased, one directory), while CLI names and aliases
+// only need to be unique under their tag command.
+type commandNames struct {
+	goNames  map[string]string
+	cliNames map[string]map[string]string
+	tags     map[string]string
+}
+
+func newCommandNames() *commandNames {
+	return &commandNames{
+		// NewRootCmd and NewServersCmd are written by writeRootCmd, the
+		// login commands by writeAuth
+		goNames:  map[string]string{"root": "the root command", "servers": "the servers command", "login": "the login command", "logout": "the logout command"},
+		cliNames: make(map[string]map[string]string),
+		tags:     make(map[string]string),
+	}
+}
+
+// claimTag reserves the tag command, whose constructor shares the
+// New<Name>Cmd namespace with the operations.
+func (n *commandNames) claimTag(tag string) error {
+	key := strings.ToLower(sanitizeTagName(tag))
+	if other, ok := n.tags[key]; ok {
+		if other != tag {
+			return fmt.Errorf("tags %q and %q both generate the %s command", other, tag, sanitizeTagCLIName(tag))
+		}
+		return nil
+	}
+	if other, ok := n.goNames[key]; ok {
+		return fmt.Errorf("tag %q generates the same Go name as %s", tag, other)
+	}
+	n.tags[key] = tag
+	n.goNames[key] = fmt.Sprintf("the %s tag command", tag)
+	return nil
+}
+
+// conflict returns the operation already using one of info's names.
+func (n *commandNames) conflict(tag string, info CommandInfo) string {
+	if other, ok := n.goNames[strings.ToLower(info.GoName)]; ok {
+		return other
+	}
+	for _, name := range append([]string{info.CLIName}, info.Aliases...) {
+		if other, ok := n.cliNames[tag][name]; ok {
+			return other
+		}
+	}
+	return ""
+}
+
+func (n *commandNames) claim(tag string, info CommandInfo, operation string) {
+	n.goNames[strings.ToLower(info.GoName)] = operation
+	if n.cliNames[tag] == nil {
+		n.cliNames[tag] = make(map[string]string)
+	}
+	for _, name := range append([]string{info.CLIName}, info.Aliases...) {
+		n.cliNames[tag][name] = operation
+	}
+}
@@ -0,0 +1,247 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestToKebab(t *testing.T) {
+	tests := map[string]string{
+		"listUserOrders":   "list-user-orders",
+		"list_user_orders": "list-user-orders",
+		"ListUserOrders":   "list-user-orders",
+		"getHTTPResponse":  "get-http-response",
+		"v2Users":          "v2-users",
+		"  spaced  out ":   "spaced-out",
+		"already-kebab":    "already-kebab",
+		"ÄpfelListe":       "äpfel-liste",
+		"":                 "",
+	}
+	for in, want := range tests {
+		if got := toKebab(in); got != want {
+			t.Errorf("toKebab(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestVerbNounName(t *testing.T) {
+	tests := []struct {
+		method, path, want string
+	}{
+		{"GET", "/users", "list-users"},
+		{"GET", "/users/{id}", "get-user"},
+		{"POST", "/users", "create-user"},
+		{"PUT", "/users/{id}", "update-user"},
+		{"PATCH", "/users/{id}", "update-user"},
+		{"DELETE", "/users/{id}", "delete-user"},
+		{"GET", "/users/{id}/addresses", "list-addresses"},
+		{"GET", "/categories/{id}", "get-category"},
+		{"GET", "/boxes/{id}", "get-box"},
+		{"GET", "/access/{id}", "get-access"},
+		{"GET", "/userGroups", "list-user-groups"},
+		{"GET", "/", "list-root"},
+		{"HEAD", "/users", "head-users"},
+	}
+	for _, tt := range tests {
+		if got := verbNounName(tt.path, tt.method); got != tt.want {
+			t.Errorf("verbNounName(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCommandInfo(t *testing.T) {
+	hidden := true
+	tests := []struct {
+		name     string
+		op       *openapi3.Operation
+		override CommandOverride
+		want     CommandInfo
+		explicit bool
+		err      string
+	}{
+		{
+			name: "guessed",
+			op:   &openapi3.Operation{},
+			want: CommandInfo{GoName: "GetUser", CLIName: "get-user"},
+		},
+		{
+			name:     "operationId",
+			op:       &openapi3.Operation{OperationID: "fetchUser"},
+			want:     CommandInfo{GoName: "FetchUser", CLIName: "fetch-user"},
+			explicit: true,
+		},
+		{
+			name:     "x-cli-name beats operationId",
+			op:       &openapi3.Operation{OperationID: "fetchUser", Extensions: map[string]any{"x-cli-name": " show_user ", "x-cli-aliases": "su, show"}},
+			want:     CommandInfo{GoName: "ShowUser", CLIName: "show-user", Aliases: []string{"su", "show"}},
+			explicit: true,
+		},
+		{
+			name:     "config beats extensions",
+			op:       &openapi3.Operation{OperationID: "fetchUser", Extensions: map[string]any{"x-cli-name": "show-user", "x-cli-hidden": false}},
+			override: CommandOverride{Name: "user", Aliases: []string{"userInfo"}, Hidden: &hidden},
+			want:     CommandInfo{GoName: "User", CLIName: "user", Aliases: []string{"user-info"}, Hidden: true},
+			explicit: true,
+		},
+		{
+			name:     "leading digit",
+			op:       &openapi3.Operation{OperationID: "2fa-verify"},
+			want:     CommandInfo{GoName: "Op2faVerify", CLIName: "2fa-verify"},
+			explicit: true,
+		},
+		{name: "unusable name", op: &openapi3.Operation{OperationID: "--"}, err: "cannot derive a command name"},
+		{name: "non-string name", op: &openapi3.Operation{Extensions: map[string]any{"x-cli-name": 1}}, err: "x-cli-name must be a string"},
+		{name: "non-bool hidden", op: &openapi3.Operation{Extensions: map[string]any{"x-cli-hidden": "yes"}}, err: "x-cli-hidden must be a boolean"},
+		{name: "bad aliases", op: &openapi3.Operation{Extensions: map[string]any{"x-cli-aliases": []any{"a", 1}}}, err: "x-cli-aliases must be a list of strings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aliases := slices.Clone(tt.override.Aliases)
+			got, explicit, err := commandInfo("/users/{id}", "get", tt.op, tt.override)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("commandInfo() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) || explicit != tt.explicit {
+				t.Errorf("commandInfo() = %+v, %v, want %+v, %v", got, explicit, tt.want, tt.explicit)
+			}
+			if !reflect.DeepEqual(tt.override.Aliases, aliases) {
+				t.Errorf("override aliases changed to %v", tt.override.Aliases)
+			}
+		})
+	}
+}
+
+// TestCommandNameCollisions generates CLIs whose operations compete for
+// the same names and checks which commands are written, or why not.
+func TestCommandNameCollisions(t *testing.T) {
+	type op struct {
+		method, path, id, tag string
+		ext                   map[string]any
+	}
+	tests := []struct {
+		name   string
+		ops    []op
+		config Config
+		files  []string // cmd files for the operations
+		err    string
+	}{
+		{
+			name:  "guessed names fall back to method and path",
+			ops:   []op{{method: "get", path: "/users"}, {method: "get", path: "/v2/users"}},
+			files: []string{"listusers.go", "getv2users.go"},
+		},
+		{
+			name:  "a guess yields to an operationId",
+			ops:   []op{{method: "get", path: "/accounts", id: "listUsers"}, {method: "get", path: "/users"}},
+			files: []string{"listusers.go", "getusers.go"},
+		},
+		{
+			name: "operationIds with the same Go name",
+			ops:  []op{{method: "get", path: "/users", id: "listUsers"}, {method: "get", path: "/people", id: "list_users"}},
+			err:  "collides with GET /people",
+		},
+		{
+			name:   "config renames one of them",
+			ops:    []op{{method: "get", path: "/users", id: "listUsers"}, {method: "get", path: "/people", id: "list_users"}},
+			config: Config{Commands: map[string]CommandOverride{"GET /users": {Name: "list-all-users"}}},
+			files:  []string{"listusers.go", "listallusers.go"},
+		},
+		{
+			name: "alias shadows a command in its tag",
+			ops: []op{
+				{method: "get", path: "/users", id: "listUsers", tag: "users"},
+				{method: "get", path: "/users/{id}", id: "getUser", tag: "users", ext: map[string]any{"x-cli-aliases": []any{"list-users"}}},
+			},
+			err: "collides with GET /users",
+		},
+		{
+			name: "aliases are per tag",
+			ops: []op{
+				{method: "get", path: "/users", id: "listUsers", tag: "users", ext: map[string]any{"x-cli-aliases": "ls"}},
+				{method: "get", path: "/pets", id: "listPets", tag: "pets", ext: map[string]any{"x-cli-aliases": "ls"}},
+			},
+			files: []string{"listusers.go", "listpets.go"},
+		},
+		{
+			name: "reserved command",
+			ops:  []op{{method: "post", path: "/session", id: "login"}},
+			err:  "collides with the login command",
+		},
+		{
+			name: "tags with the same command",
+			ops:  []op{{method: "get", path: "/a", tag: "Pet Store"}, {method: "get", path: "/b", tag: "pet-store"}},
+			err:  `tags "Pet Store" and "pet-store" both generate the pet-store command`,
+		},
+		{
+			name: "operation named like a tag",
+			ops:  []op{{method: "get", path: "/a", tag: "pets"}, {method: "get", path: "/b", id: "pets", tag: "pets"}},
+			err:  "collides with the pets tag command",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := map[string]map[string]any{}
+			for _, o := range tt.ops {
+				operation := map[string]any{"responses": map[string]any{"200": map[string]any{"description": "ok"}}}
+				if o.id != "" {
+					operation["operationId"] = o.id
+				}
+				if o.tag != "" {
+					operation["tags"] = []string{o.tag}
+				}
+				for k, v := range o.ext {
+					operation[k] = v
+				}
+				if paths[o.path] == nil {
+					paths[o.path] = map[string]any{}
+				}
+				paths[o.path][o.method] = operation
+			}
+			doc, err := json.Marshal(map[string]any{
+				"openapi": "3.0.3",
+				"info":    map[string]any{"title": "collisions", "version": "1"},
+				"paths":   paths,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			specPath := filepath.Join(dir, "openapi.json")
+			if err := os.WriteFile(specPath, doc, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			g := NewGenerator()
+			g.Config = tt.config
+			out := filepath.Join(dir, "out")
+			err = g.Generate(specPath, out, "collisions")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Generate() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range tt.files {
+				if _, err := os.Stat(filepath.Join(out, "cmd", f)); err != nil {
+					t.Errorf("missing command file: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package generator
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -35,3 +38,52 @@ func chooseRequestContentType(op *openapi3.Operation) string {
 	}
 	return ""
 }
+
+var pathTemplateRe = regexp.MustCompile(`\{([^}]+)\}`)
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// operationParameters merges the parameters declared on the path item with
+// those of the operation. An operation parameter with the same name and
+// location replaces the path-level one.
+func operationParameters(path string, pathItem *openapi3.PathItem, op *openapi3.Operation) (openapi3.Parameters, error) {
+	var params openapi3.Parameters
+	for _, pRef := range pathItem.Parameters {
+		if pRef == nil || pRef.Value == nil {
+			continue
+		}
+		if op.Parameters.GetByInAndName(pRef.Value.In, pRef.Value.Name) == nil {
+			params = append(params, pRef)
+		}
+	}
+	for _, pRef := range op.Parameters {
+		if pRef == nil || pRef.Value == nil {
+			continue
+		}
+		params = append(params, pRef)
+	}
+
+	flags := make(map[string]string)
+	for _, pRef := range params {
+		p := pRef.Value
+		if other, ok := flags[sanitizeVar(p.Name)]; ok {
+			return nil, fmt.Errorf("%s parameter %s and %s parameter %s would share a flag", other, p.Name, p.In, p.Name)
+		}
+		flags[sanitizeVar(p.Name)] = p.In
+	}
+
+	for _, match := range pathTemplateRe.FindAllStringSubmatch(path, -1) {
+		name := match[1]
+		if params.GetByInAndName("path", name) == nil {
+			return nil, fmt.Errorf("path parameter %s is not declared", name)
+		}
+	}
+	return params, nil
+}
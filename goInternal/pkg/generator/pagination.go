@@ -0,0 +1,374 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// pagination describes how the pages of a list operation are linked; see
+// utils.Pagination in the generated CLI.
+type pagination struct {
+	Style     string // cursor, offset, page, next or link
+	Param     string
+	SizeParam string
+	Start     int
+	NextPath  []string
+	ItemsPath []string
+	ItemModel string
+}
+
+var (
+	cursorParams = []string{"cursor", "pagetoken", "nexttoken", "after", "startingafter", "continuationtoken", "pagecursor", "marker"}
+	offsetParams = []string{"offset", "skip"}
+	pageParams   = []string{"page", "pagenumber", "pagenum", "pageno"}
+	sizeParams   = []string{"limit", "pagesize", "perpage", "size", "maxresults", "top"}
+	cursorFields = []string{"nextcursor", "nextpagetoken", "nexttoken", "endcursor", "continuationtoken", "cursor", "after"}
+	nextFields   = []string{"next", "nexturl", "nextlink", "nextpageurl", "odatanextlink"}
+	itemsFields  = []string{"data", "items", "results", "records", "entries", "values", "content"}
+)
+
+// detectPagination works out how to follow the pages of op, from its
+// x-pagination extension or, for GET operations, from common cursor, offset
+// and page query parameters, next fields in the response and Link headers.
+// Extension fields left out are detected the same way. It returns nil for
+// operations that do not page.
+//
+//	x-pagination:
+//	  style: cursor        # cursor, offset, page, next or link
+//	  param: cursor        # query parameter taking the cursor, offset or page
+//	  sizeParam: limit     # query parameter taking the page size
+//	  start: 1             # first offset or page number
+//	  next: meta.next      # response field with the next cursor or page URL
+//	  items: data          # response field with the items
+func detectPagination(op *openapi3.Operation, method string, params openapi3.Parameters) (*pagination, error) {
+	var ext map[string]interface{}
+	switch v := op.Extensions["x-pagination"].(type) {
+	case nil:
+		if method != "get" {
+			return nil, nil
+		}
+	case bool:
+		if !v {
+			return nil, nil
+		}
+		ext = map[string]interface{}{}
+	case map[string]interface{}:
+		ext = v
+	default:
+		return nil, fmt.Errorf("x-pagination must be an object or false")
+	}
+	str := func(key string) (string, error) {
+		v, ok := ext[key]
+		if !ok {
+			return "", nil
+		}
+		s, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("x-pagination %s must be a string", key)
+		}
+		return s, nil
+	}
+
+	var query []*openapi3.Parameter
+	for _, p := range params {
+		if p == nil || p.Value == nil {
+			continue
+		}
+		switch p.Value.Name {
+		case "all", "max-items", "page-size":
+			// the pagination flags would clash with the parameter's
+			return nil, nil
+		}
+		if p.Value.In == "query" {
+			query = append(query, p.Value)
+		}
+	}
+	findParam := func(names []string) *openapi3.Parameter {
+		for _, name := range names {
+			for _, p := range query {
+				if normalizeName(p.Name) == name {
+					return p
+				}
+			}
+		}
+		return nil
+	}
+
+	pg := &pagination{}
+	var err error
+	if pg.Style, err = str("style"); err != nil {
+		return nil, err
+	}
+	if pg.Param, err = str("param"); err != nil {
+		return nil, err
+	}
+	if pg.SizeParam, err = str("sizeParam"); err != nil {
+		return nil, err
+	}
+	if pg.SizeParam == "" {
+		if p := findParam(sizeParams); p != nil {
+			pg.SizeParam = p.Name
+		}
+	}
+
+	resp := successResponse(op)
+	var schema *openapi3.SchemaRef
+	if resp != nil {
+		if content := resp.Content.Get("application/json"); content != nil {
+			schema = content.Schema
+		}
+	}
+
+	items, itemsSet := ext["items"]
+	var itemSchema *openapi3.SchemaRef
+	if itemsSet {
+		s, ok := items.(string)
+		if !ok {
+			return nil, fmt.Errorf("x-pagination items must be a string")
+		}
+		pg.ItemsPath = splitPath(s)
+		itemSchema = schemaPath(schema, pg.ItemsPath)
+		if itemSchema != nil && itemSchema.Value != nil && schemaType(itemSchema.Value) == "array" {
+			itemSchema = itemSchema.Value.Items
+		} else {
+			itemSchema = nil
+		}
+	} else {
+		var ok bool
+		if pg.ItemsPath, itemSchema, ok = findItems(schema); !ok {
+			if ext != nil {
+				return nil, fmt.Errorf("x-pagination: cannot find the list of items in the response; set items")
+			}
+			return nil, nil
+		}
+	}
+	if itemSchema != nil {
+		pg.ItemModel, _ = modelTypeName(itemSchema.Ref)
+	}
+
+	next, err := str("next")
+	if err != nil {
+		return nil, err
+	}
+	if next != "" {
+		pg.NextPath = splitPath(next)
+	}
+	var nextURL, cursor []string
+	if pg.NextPath == nil && schema != nil {
+		nextURL = findField(schema.Value, nextFields, true)
+		cursor = findField(schema.Value, cursorFields, false)
+	}
+
+	cursorParam, offsetParam, pageParam := findParam(cursorParams), findParam(offsetParams), findParam(pageParams)
+	if pg.Style == "" {
+		switch {
+		case pg.NextPath != nil && (pg.Param != "" || cursorParam != nil):
+			pg.Style = "cursor"
+		case pg.NextPath != nil || nextURL != nil:
+			pg.Style = "next"
+		case cursor != nil && (pg.Param != "" || cursorParam != nil):
+			pg.Style = "cursor"
+		case offsetParam != nil:
+			pg.Style = "offset"
+		case pageParam != nil:
+			pg.Style = "page"
+		case resp != nil && resp.Headers["Link"] != nil:
+			pg.Style = "link"
+		case ext != nil:
+			pg.Style = "link"
+		default:
+			return nil, nil
+		}
+	}
+
+	var param *openapi3.Parameter
+	switch pg.Style {
+	case "cursor":
+		param = cursorParam
+		if pg.NextPath == nil {
+			pg.NextPath = cursor
+		}
+	case "next":
+		// a next value that is not a URL is sent as the cursor
+		param = cursorParam
+		if pg.NextPath == nil {
+			pg.NextPath = nextURL
+		}
+	case "offset":
+		param = offsetParam
+	case "page":
+		param, pg.Start = pageParam, 1
+	case "link":
+	default:
+		return nil, fmt.Errorf("x-pagination style must be cursor, offset, page, next or link, not %q", pg.Style)
+	}
+	if pg.Param == "" && param != nil {
+		pg.Param = param.Name
+	}
+	if pg.Param != "" {
+		param = findByName(query, pg.Param)
+	}
+	if (pg.Style == "cursor" || pg.Style == "offset" || pg.Style == "page") && pg.Param == "" {
+		return nil, fmt.Errorf("x-pagination: %s pagination needs a param", pg.Style)
+	}
+	if (pg.Style == "cursor" || pg.Style == "next") && pg.NextPath == nil {
+		return nil, fmt.Errorf("x-pagination: %s pagination needs the next field of the response", pg.Style)
+	}
+
+	if param != nil && param.Schema != nil && param.Schema.Value != nil {
+		switch v := param.Schema.Value.Default.(type) {
+		case float64:
+			pg.Start = int(v)
+		case int:
+			pg.Start = v
+		default:
+			if param.Schema.Value.Min != nil {
+				pg.Start = int(*param.Schema.Value.Min)
+			}
+		}
+	}
+	if v, ok := ext["start"]; ok {
+		n, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("x-pagination start must be a number")
+		}
+		pg.Start = int(n)
+	}
+	return pg, nil
+}
+
+// literal is the utils.Pagination the generated command passes to
+// utils.PaginatedPrint.
+func (pg *pagination) literal() string {
+	return fmt.Sprintf("utils.Pagination{Style: %q, Param: %q, SizeParam: %q, Start: %d, NextPath: %s, ItemsPath: %s}",
+		pg.Style, pg.Param, pg.SizeParam, pg.Start, stringSlice(pg.NextPath), stringSlice(pg.ItemsPath))
+}
+
+func stringSlice(s []string) string {
+	if s == nil {
+		return "nil"
+	}
+	return "[]string{" + pathLiteral(s) + "}"
+}
+
+// normalizeName folds page_size, pageSize and @odata.nextLink to pagesize
+// and odatanextlink.
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', '.', '@', '$':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+func splitPath(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ".")
+}
+
+func findByName(params []*openapi3.Parameter, name string) *openapi3.Parameter {
+	for _, p := range params {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func successResponse(op *openapi3.Operation) *openapi3.Response {
+	if op.Responses == nil {
+		return nil
+	}
+	for _, code := range []string{"200", "201", "202", "206", "default"} {
+		if r := op.Responses.Value(code); r != nil && r.Value != nil {
+			return r.Value
+		}
+	}
+	return nil
+}
+
+// findItems finds the list in a page: the response itself, a field with a
+// common name such as data or items, or its only array field.
+func findItems(schema *openapi3.SchemaRef) ([]string, *openapi3.SchemaRef, bool) {
+	if schema == nil || schema.Value == nil {
+		return nil, nil, false
+	}
+	if schemaType(schema.Value) == "array" {
+		return nil, schema.Value.Items, true
+	}
+	props, _ := objectProperties(schema.Value)
+	var arrays []string
+	for _, name := range sortedKeys(props) {
+		if p := props[name]; p != nil && p.Value != nil && schemaType(p.Value) == "array" {
+			arrays = append(arrays, name)
+		}
+	}
+	for _, want := range itemsFields {
+		for _, name := range arrays {
+			if normalizeName(name) == want {
+				return []string{name}, props[name].Value.Items, true
+			}
+		}
+	}
+	if len(arrays) == 1 {
+		return arrays, props[arrays[0]].Value.Items, true
+	}
+	return nil, nil, false
+}
+
+// findField looks for one of names among the fields of a page and of its
+// objects, such as meta or links. URL fields may be strings or objects with
+// an href.
+func findField(s *openapi3.Schema, names []string, url bool) []string {
+	props, _ := objectProperties(s)
+	if path := matchField(props, names, url); path != nil {
+		return path
+	}
+	for _, name := range sortedKeys(props) {
+		p := props[name]
+		if p == nil || p.Value == nil || schemaType(p.Value) != "object" {
+			continue
+		}
+		nested, _ := objectProperties(p.Value)
+		if path := matchField(nested, names, url); path != nil {
+			return append([]string{name}, path...)
+		}
+	}
+	return nil
+}
+
+func matchField(props openapi3.Schemas, names []string, url bool) []string {
+	for _, want := range names {
+		for _, name := range sortedKeys(props) {
+			p := props[name]
+			if p == nil || p.Value == nil || normalizeName(name) != want {
+				continue
+			}
+			switch t := schemaType(p.Value); {
+			case t == "string" || t == "", t == "integer" && !url:
+				return []string{name}
+			case url && t == "object" && p.Value.Properties["href"] != nil:
+				return []string{name, "href"}
+			}
+		}
+	}
+	return nil
+}
+
+// schemaPath returns the schema of the response field at path.
+func schemaPath(s *openapi3.SchemaRef, path []string) *openapi3.SchemaRef {
+	for _, name := range path {
+		if s == nil || s.Value == nil {
+			return nil
+		}
+		props, _ := objectProperties(s.Value)
+		s = props[name]
+	}
+	return s
+}
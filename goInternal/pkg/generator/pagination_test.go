@@ -0,0 +1,35 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPaginationFlags(t *testing.T) {
+	out := generateTestdata(t, "pagination.yaml", Config{})
+	tests := []struct {
+		cmd        string
+		pagination string
+	}{
+		{"listcursor", `utils.Pagination{Style: "cursor", Param: "cursor", SizeParam: "limit", Start: 0, NextPath: []string{"meta", "next_cursor"}, ItemsPath: []string{"data"}}`},
+		{"listoffset", `utils.Pagination{Style: "offset", Param: "offset", SizeParam: "limit", Start: 0, NextPath: nil, ItemsPath: nil}`},
+		{"listpage", `utils.Pagination{Style: "page", Param: "page", SizeParam: "per_page", Start: 1, NextPath: nil, ItemsPath: []string{"results"}}`},
+		{"listnext", `utils.Pagination{Style: "next", Param: "", SizeParam: "", Start: 0, NextPath: []string{"next"}, ItemsPath: []string{"items"}}`},
+		{"listlink", `utils.Pagination{Style: "link", Param: "", SizeParam: "", Start: 0, NextPath: nil, ItemsPath: nil}`},
+		// x-pagination overrides what is inferred
+		{"listcustom", `utils.Pagination{Style: "cursor", Param: "from", SizeParam: "", Start: 0, NextPath: []string{"paging", "after"}, ItemsPath: []string{"rows"}}`},
+	}
+	for _, tt := range tests {
+		assertContains(t, out, "cmd/"+tt.cmd+".go",
+			"if paginateAll || paginateMaxItems > 0 {",
+			"utils.PaginatedPrint(client, req, "+tt.pagination+", paginateMaxItems,",
+			`cmd.Flags().BoolVar(&paginateAll, "all", false, "Fetch every page and print the combined results")`,
+			`cmd.Flags().IntVar(&paginateMaxItems, "max-items", 0, "Fetch pages until this many items were read")`,
+		)
+	}
+	// x-pagination: false turns it off
+	if code := emitted(t, out, "cmd/listno.go"); strings.Contains(code, "PaginatedPrint") || strings.Contains(code, `"all"`) {
+		t.Error("listno.go paginates despite x-pagination: false")
+	}
+	testGeneratedPackage(t, out, "utils", "paginate_test.go")
+}
@@ -0,0 +1,444 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type bodyKind int
+
+const (
+	jsonBody bodyKind = iota
+	formBody
+	multipartBody
+	binaryBody
+	rawBody
+)
+
+// bodyFlag is a --body-* flag and the body value it sets.
+type bodyFlag struct {
+	path     []string
+	varName  string
+	goType   string
+	flagFunc string
+	flag     string
+	desc     string
+	enum     []interface{}
+	objects  bool   // a repeated key=value or JSON object flag
+	object   bool   // a single key=value or JSON object flag
+	value    bool   // any JSON value
+	file     bool   // a multipart file upload
+	fields   string // utils.Fields literal for object flags
+}
+
+// buildRequestBody adds the body flags for the operation's request body and
+// returns the code that builds the body and sets its Content-Type. JSON
+// bodies merge the flags into --body, form bodies take one flag per field
+// (file uploads for binary multipart fields) and binary bodies are read
+// from --body-file.
+func buildRequestBody(method string, op *openapi3.Operation, imports map[string]bool, varDecls *strings.Builder, flagsSetup *strings.Builder,
+	validationBuild *strings.Builder) (string, string) {
+	if !hasRequestBody(method, op) {
+		return `
+			var bodyReader io.Reader = nil
+`, ""
+	}
+
+	varDecls.WriteString("\tvar body string\n")
+	flagsSetup.WriteString("\tcmd.Flags().StringVarP(&body, \"body\", \"b\", \"\", \"Request body (raw, @filename, or '-' for stdin); body flags override its fields\")\n")
+	varDecls.WriteString("\tvar contentType string\n")
+	flagsSetup.WriteString("\tcmd.Flags().StringVar(&contentType, \"content-type\", \"\", \"Content-Type header for the request body\")\n")
+
+	ct := chooseRequestContentType(op)
+	var schema *openapi3.Schema
+	required := false
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		required = op.RequestBody.Value.Required
+		if content := op.RequestBody.Value.Content.Get(ct); content != nil && content.Schema != nil {
+			schema = content.Schema.Value
+		}
+	}
+	kind := requestBodyKind(ct, schema)
+	if ct == "" {
+		ct = "application/json"
+	}
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("\n\t\t\tvar bodyReader io.Reader\n\t\t\tbodyContentType := %q\n", ct))
+	switch kind {
+	case jsonBody, formBody, multipartBody:
+		var flags []bodyFlag
+		var requiredPaths [][]string
+		if kind == jsonBody {
+			flags, requiredPaths = jsonBodyFlags(schema)
+		} else {
+			flags, requiredPaths = formBodyFlags(schema, kind == multipartBody)
+		}
+		for _, f := range flags {
+			writeBodyFlag(f, varDecls, flagsSetup)
+			generateEnumCheck(validationBuild, f.varName, f.flag, f.goType, f.enum, imports)
+		}
+		if kind == jsonBody {
+			code.WriteString(jsonBodyCode(flags, requiredPaths, required, imports))
+		} else {
+			code.WriteString(formBodyCode(flags, requiredPaths, kind == multipartBody, required))
+		}
+	case binaryBody:
+		varDecls.WriteString("\tvar bodyFile string\n")
+		flagsSetup.WriteString("\tcmd.Flags().StringVar(&bodyFile, \"body-file\", \"\", \"File to send as the request body ('-' for stdin)\")\n")
+		flagsSetup.WriteString("\tcmd.MarkFlagsMutuallyExclusive(\"body\", \"body-file\")\n")
+		code.WriteString(`			if bodyFile != "" {
+				body = "@" + bodyFile
+			}
+`)
+		code.WriteString(rawBodyCode(required))
+	default:
+		code.WriteString(rawBodyCode(required))
+	}
+
+	headerHandling := `
+			if bodyReader != nil {
+				ct := contentType
+				if ct == "" {
+					ct = bodyContentType
+				}
+				req.Header.Set("Content-Type", ct)
+			}
+`
+	return code.String(), headerHandling
+}
+
+func requestBodyKind(ct string, schema *openapi3.Schema) bodyKind {
+	switch {
+	case ct == "" || ct == "application/json" || strings.HasSuffix(ct, "+json"):
+		return jsonBody
+	case ct == "application/x-www-form-urlencoded":
+		return formBody
+	case strings.HasPrefix(ct, "multipart/"):
+		return multipartBody
+	case isBinary(schema), ct == "application/octet-stream", ct == "*/*",
+		strings.HasPrefix(ct, "image/"), strings.HasPrefix(ct, "audio/"), strings.HasPrefix(ct, "video/"):
+		return binaryBody
+	}
+	return rawBody
+}
+
+func isBinary(s *openapi3.Schema) bool {
+	return s != nil && schemaType(s) == "string" && s.Format == "binary"
+}
+
+// objectProperties returns the properties of an object schema, including
+// those its allOf members declare, and the names of the required ones.
+func objectProperties(s *openapi3.Schema) (openapi3.Schemas, []string) {
+	props := make(openapi3.Schemas)
+	var required []string
+	var collect func(s *openapi3.Schema, depth int)
+	collect = func(s *openapi3.Schema, depth int) {
+		if s == nil || depth > 8 {
+			return
+		}
+		for _, member := range s.AllOf {
+			if member != nil {
+				collect(member.Value, depth+1)
+			}
+		}
+		for name, p := range s.Properties {
+			props[name] = p
+		}
+		required = append(required, s.Required...)
+	}
+	collect(s, 0)
+	return props, required
+}
+
+// jsonBodyFlags walks a JSON body schema. Nested objects get one flag per
+// field, arrays of objects a repeated flag and free-form objects a flag
+// taking key=value pairs or JSON. An array body takes repeated --body-item
+// flags, which replace the items of a --body array rather than merging.
+func jsonBodyFlags(schema *openapi3.Schema) ([]bodyFlag, [][]string) {
+	if schema == nil {
+		return nil, nil
+	}
+	if schemaType(schema) == "array" {
+		if schema.Items == nil || schema.Items.Value == nil {
+			return nil, nil
+		}
+		f := valueFlag(schema, []string{"item"}, "Items of the request body, replacing those of --body")
+		f.path = nil
+		f.varName = "body_item"
+		f.flag = "body-item"
+		if f.objects || f.goType != "" {
+			return []bodyFlag{f}, nil
+		}
+		return nil, nil
+	}
+
+	var flags []bodyFlag
+	var requiredPaths [][]string
+	visiting := make(map[*openapi3.Schema]bool)
+	var walk func(s *openapi3.Schema, path []string)
+	walk = func(s *openapi3.Schema, path []string) {
+		visiting[s] = true
+		defer delete(visiting, s)
+		props, required := objectProperties(s)
+		for _, name := range sortedKeys(props) {
+			ref := props[name]
+			if ref == nil || ref.Value == nil || ref.Value.ReadOnly {
+				continue
+			}
+			p := ref.Value
+			childPath := append(append([]string{}, path...), name)
+			if slices.Contains(required, name) {
+				requiredPaths = append(requiredPaths, childPath)
+			}
+			if nested, _ := objectProperties(p); len(nested) > 0 && !visiting[p] && schemaType(p) != "array" {
+				walk(p, childPath)
+				continue
+			}
+			flags = append(flags, valueFlag(p, childPath, fieldDesc(p, childPath)))
+		}
+	}
+	walk(schema, nil)
+	return flags, requiredPaths
+}
+
+// formBodyFlags gives each field of a form body a flag. Binary fields of a
+// multipart body upload files; other structured fields take their value as
+// text, usually JSON.
+func formBodyFlags(schema *openapi3.Schema, multipart bool) ([]bodyFlag, [][]string) {
+	props, required := objectProperties(schema)
+	var flags []bodyFlag
+	var requiredPaths [][]string
+	for _, name := range sortedKeys(props) {
+		ref := props[name]
+		if ref == nil || ref.Value == nil || ref.Value.ReadOnly {
+			continue
+		}
+		p := ref.Value
+		path := []string{name}
+		if slices.Contains(required, name) {
+			requiredPaths = append(requiredPaths, path)
+		}
+		f := bodyFlag{path: path, varName: bodyVarName(path), flag: bodyFlagName(path), desc: fieldDesc(p, path), goType: "string", flagFunc: "StringVar"}
+		switch {
+		case multipart && isBinary(p):
+			f.file = true
+			f.desc = joinDesc(f.desc, "file to upload, '-' for stdin")
+		case multipart && schemaType(p) == "array" && p.Items != nil && isBinary(p.Items.Value):
+			f.goType, f.flagFunc, f.file = "[]string", "StringArrayVar", true
+			f.desc = joinDesc(f.desc, "file to upload, '-' for stdin, repeatable")
+		case isScalar(p) || (schemaType(p) == "array" && p.Items != nil && isScalar(p.Items.Value)):
+			f.goType, f.flagFunc = mapSchemaToFlag(p)
+			f.enum = p.Enum
+		default:
+			f.desc = joinDesc(f.desc, "JSON")
+		}
+		flags = append(flags, f)
+	}
+	return flags, requiredPaths
+}
+
+// valueFlag is the flag for the JSON value at path.
+func valueFlag(p *openapi3.Schema, path []string, desc string) bodyFlag {
+	f := bodyFlag{path: path, varName: bodyVarName(path), flag: bodyFlagName(path), desc: desc}
+	switch {
+	case schemaType(p) == "array" && p.Items != nil && p.Items.Value != nil && isObject(p.Items.Value):
+		f.objects = true
+		f.fields, f.desc = objectFields(p.Items.Value, f.desc, "repeatable; ")
+	case isObject(p):
+		f.object = true
+		f.fields, f.desc = objectFields(p, f.desc, "")
+	case isScalar(p) || (schemaType(p) == "array" && p.Items != nil && isScalar(p.Items.Value)):
+		f.goType, f.flagFunc = mapSchemaToFlag(p)
+		f.enum = p.Enum
+	default:
+		// arrays of arrays and untyped values
+		f.value = true
+		f.desc = joinDesc(f.desc, "JSON")
+	}
+	if len(p.Enum) > 0 {
+		f.desc += fmt.Sprintf(" (one of: %v)", p.Enum)
+	}
+	return f
+}
+
+func isObject(s *openapi3.Schema) bool {
+	props, _ := objectProperties(s)
+	return schemaType(s) == "object" || len(props) > 0
+}
+
+// objectFields returns the utils.Fields literal describing an object flag's
+// keys and a description with an example value.
+func objectFields(s *openapi3.Schema, desc, note string) (string, string) {
+	props, required := objectProperties(s)
+	var types, example []string
+	for _, name := range sortedKeys(props) {
+		if props[name] == nil || props[name].Value == nil {
+			continue
+		}
+		typ := schemaType(props[name].Value)
+		if typ == "array" && props[name].Value.Items != nil {
+			if item := schemaType(props[name].Value.Items.Value); item != "" {
+				typ += ":" + item
+			}
+		}
+		if typ != "" {
+			types = append(types, fmt.Sprintf("%q: %q", name, typ))
+		}
+		if len(example) < 2 {
+			example = append(example, name+"=...")
+		}
+	}
+	var req []string
+	for _, name := range required {
+		req = append(req, fmt.Sprintf("%q", name))
+	}
+	var parts []string
+	if len(types) > 0 {
+		parts = append(parts, "Types: map[string]string{"+strings.Join(types, ", ")+"}")
+	}
+	if len(req) > 0 {
+		parts = append(parts, "Required: []string{"+strings.Join(req, ", ")+"}")
+	}
+	fields := "utils.Fields{" + strings.Join(parts, ", ") + "}"
+	if len(example) == 0 {
+		example = []string{"key=value"}
+	}
+	return fields, joinDesc(desc, note+strings.Join(example, ",")+" or JSON")
+}
+
+func fieldDesc(p *openapi3.Schema, path []string) string {
+	if p.Description != "" {
+		return p.Description
+	}
+	return strings.Join(path, ".") + " field"
+}
+
+func joinDesc(desc, note string) string {
+	return desc + " (" + note + ")"
+}
+
+func bodyVarName(path []string) string {
+	return "body_" + sanitizeVar(strings.Join(path, "_"))
+}
+
+func bodyFlagName(path []string) string {
+	return "body-" + strings.Join(path, "-")
+}
+
+func writeBodyFlag(f bodyFlag, varDecls, flagsSetup *strings.Builder) {
+	goType, flagFunc := f.goType, f.flagFunc
+	switch {
+	case f.objects:
+		goType, flagFunc = "[]string", "StringArrayVar"
+	case f.object, f.value:
+		goType, flagFunc = "string", "StringVar"
+	}
+	varDecls.WriteString(fmt.Sprintf("\tvar %s %s\n", f.varName, goType))
+	flagsSetup.WriteString(fmt.Sprintf("\tcmd.Flags().%s(&%s, %q, %s, %q)\n", flagFunc, f.varName, f.flag, defaultForType(goType), f.desc))
+}
+
+func pathLiteral(path []string) string {
+	quoted := make([]string, len(path))
+	for i, p := range path {
+		quoted[i] = fmt.Sprintf("%q", p)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func jsonBodyCode(flags []bodyFlag, requiredPaths [][]string, required bool, imports map[string]bool) string {
+	var code strings.Builder
+	code.WriteString(`			bodyObj, err := utils.NewJSONBody(body)
+			if err != nil {
+				return err
+			}
+`)
+	for _, f := range flags {
+		value := f.varName
+		target := pathLiteral(f.path)
+		if target != "" {
+			target = ", " + target
+		}
+		code.WriteString(fmt.Sprintf("\t\t\tif cmd.Flags().Changed(%q) {\n", f.flag))
+		switch {
+		case f.objects:
+			imports["fmt"] = true
+			code.WriteString(fmt.Sprintf("\t\t\t\tvalue, err := utils.ParseObjects(%s, %s)\n", f.varName, f.fields))
+			code.WriteString("\t\t\t\tif err != nil {\n\t\t\t\t\treturn fmt.Errorf(\"--" + f.flag + ": %w\", err)\n\t\t\t\t}\n")
+			value = "value"
+		case f.object:
+			imports["fmt"] = true
+			code.WriteString(fmt.Sprintf("\t\t\t\tvalue, err := utils.ParseObject(%s, %s)\n", f.varName, f.fields))
+			code.WriteString("\t\t\t\tif err != nil {\n\t\t\t\t\treturn fmt.Errorf(\"--" + f.flag + ": %w\", err)\n\t\t\t\t}\n")
+			value = "value"
+		case f.value:
+			value = "utils.ParseValue(" + f.varName + ")"
+		}
+		code.WriteString(fmt.Sprintf("\t\t\t\tif err := bodyObj.Set(%s%s); err != nil {\n\t\t\t\t\treturn err\n\t\t\t\t}\n\t\t\t}\n", value, target))
+	}
+	if len(requiredPaths) > 0 {
+		paths := make([]string, len(requiredPaths))
+		for i, p := range requiredPaths {
+			paths[i] = "[]string{" + pathLiteral(p) + "}"
+		}
+		code.WriteString(fmt.Sprintf("\t\t\tif err := bodyObj.Require(%s); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n", strings.Join(paths, ", ")))
+	}
+	code.WriteString(fmt.Sprintf("\t\t\tif bodyReader, err = bodyObj.Reader(%t); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n", required))
+	return code.String()
+}
+
+func formBodyCode(flags []bodyFlag, requiredPaths [][]string, multipart, required bool) string {
+	var code strings.Builder
+	code.WriteString(`			form, err := utils.NewForm(body)
+			if err != nil {
+				return err
+			}
+`)
+	for _, f := range flags {
+		name := f.path[0]
+		code.WriteString(fmt.Sprintf("\t\t\tif cmd.Flags().Changed(%q) {\n", f.flag))
+		switch {
+		case f.file && f.goType == "[]string":
+			code.WriteString(fmt.Sprintf("\t\t\t\tform.SetFiles(%q, %s...)\n", name, f.varName))
+		case f.file:
+			code.WriteString(fmt.Sprintf("\t\t\t\tform.SetFiles(%q, %s)\n", name, f.varName))
+		default:
+			code.WriteString(fmt.Sprintf("\t\t\t\tform.Set(%q, %s)\n", name, f.varName))
+		}
+		code.WriteString("\t\t\t}\n")
+	}
+	if len(requiredPaths) > 0 {
+		names := make([]string, len(requiredPaths))
+		for i, p := range requiredPaths {
+			names[i] = fmt.Sprintf("%q", p[0])
+		}
+		code.WriteString(fmt.Sprintf("\t\t\tif err := form.Require(%s); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n", strings.Join(names, ", ")))
+	}
+	code.WriteString(fmt.Sprintf(`			formReader, formContentType, err := form.Reader(%t, %t)
+			if err != nil {
+				return err
+			}
+			if formReader != nil {
+				bodyReader, bodyContentType = formReader, formContentType
+			}
+`, multipart, required))
+	return code.String()
+}
+
+func rawBodyCode(required bool) string {
+	code := `			bodyReader, err = utils.GetBodyReader(body)
+			if err != nil {
+				return err
+			}
+`
+	if required {
+		code += `			if bodyReader == nil {
+				return utils.ErrBodyRequired
+			}
+`
+	}
+	return code
+}
@@ -0,0 +1,42 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRequestBodyFlags(t *testing.T) {
+	out := generateTestdata(t, "body.yaml", Config{})
+	assertContains(t, out, "cmd/createorder.go",
+		// nested fields get their own flags; readOnly ones none
+		`cmd.Flags().IntVar(&body_customer_id, "body-customer-id", 0, "customer.id field")`,
+		`if err := bodyObj.Set(body_customer_id, "customer", "id"); err != nil {`,
+		`cmd.Flags().StringArrayVar(&body_items, "body-items", nil, "items field (repeatable; name=...,price=... or JSON)")`,
+		`utils.ParseObjects(body_items, utils.Fields{Types: map[string]string{"name": "string", "price": "number", "qty": "integer", "tags": "array:string"}, Required: []string{"name"}})`,
+		`if err := bodyObj.Require([]string{"customer"}, []string{"customer", "id"}, []string{"items"}, []string{"shipping", "city"}); err != nil {`,
+		`if bodyReader, err = bodyObj.Reader(true); err != nil {`,
+	)
+	if code := emitted(t, out, "cmd/createorder.go"); strings.Contains(code, "body-created") {
+		t.Error("createorder.go has a flag for a readOnly field")
+	}
+	// an array body is set whole from a repeatable item flag
+	assertContains(t, out, "cmd/bulk.go",
+		`cmd.Flags().StringArrayVar(&body_item, "body-item", nil, "Items of the request body, replacing those of --body (repeatable; name=...,price=... or JSON)")`,
+		"if err := bodyObj.Set(value); err != nil {",
+		"if bodyReader, err = bodyObj.Reader(false); err != nil {",
+	)
+	assertContains(t, out, "cmd/upload.go",
+		`cmd.Flags().StringVar(&body_file, "body-file", "", "file field (file to upload, '-' for stdin)")`,
+		`cmd.Flags().StringArrayVar(&body_attachments, "body-attachments", nil, "attachments field (file to upload, '-' for stdin, repeatable)")`,
+		`form.SetFiles("file", body_file)`,
+		`if err := form.Require("file"); err != nil {`,
+		"form.Reader(true, false)",
+	)
+	assertContains(t, out, "cmd/submitform.go", "form.Reader(false, true)")
+	assertContains(t, out, "cmd/putraw.go",
+		`cmd.Flags().StringVar(&bodyFile, "body-file", "", "File to send as the request body ('-' for stdin)")`,
+		`bodyContentType := "application/octet-stream"`,
+		"return utils.ErrBodyRequired",
+	)
+	testGeneratedPackage(t, out, "utils", "body_test.go")
+}
@@ -16,10 +16,20 @@ func New{{.GoName}}Cmd() *cobra.Command {
 {{.VarDecls}}
     cmd := &cobra.Command{
         Use:   "{{.CommandName}}",
+{{- if .Aliases}}
+        Aliases: []string{ {{- range $i, $a := .Aliases}}{{if $i}}, {{end}}{{printf "%q" $a}}{{end -}} },
+{{- end}}
+{{- if .Hidden}}
+        Hidden: true,
+{{- end}}
         Short: "{{.Short}}",
         RunE: func(cmd *cobra.Command, args []string) error {
 {{.Validation}}
             cfg := config.Load("{{.ModuleName}}", Env)
+            baseURL, err := cfg.ServerURL({{.Servers}}, ServerVars)
+            if err != nil {
+                return err
+            }
             pathWithParams := "{{.Path}}"
 {{.PathReplacements}}
             // build URL and query params
@@ -27,7 +37,7 @@ func New{{.GoName}}Cmd() *cobra.Command {
             q := url.Values{}
 {{.QueryBuild}}
             u.RawQuery = q.Encode()
-            fullUrl := strings.TrimRight(cfg.BaseURL, "/") + u.String()
+            fullUrl := strings.TrimRight(baseURL, "/") + u.String()
 
 {{.BodyHandling}}
 
@@ -37,7 +47,8 @@ func New{{.GoName}}Cmd() *cobra.Command {
             }
 
 {{.HeaderHandling}}
-
+{{.HeaderBuild}}
+{{.CookieBuild}}
 {{.AuthCode}}
 
 			if Debug {
@@ -47,18 +58,33 @@ func New{{.GoName}}Cmd() *cobra.Command {
             client := &http.Client{
                 Timeout: cfg.Timeout,
             }
+{{- if .ErrorSchemas}}
+            errorSchemas := {{.ErrorSchemas}}
+{{- else}}
+            var errorSchemas utils.ErrorSchemas
+{{- end}}
+{{- if .Pagination}}
+            if paginateAll || paginateMaxItems > 0 {
+{{- if .ItemModel}}
+                var items []models.{{.ItemModel}}
+                return utils.PaginatedPrint(client, req, {{.Pagination}}, paginateMaxItems, &items, errorSchemas, printOptions())
+{{- else}}
+                return utils.PaginatedPrint(client, req, {{.Pagination}}, paginateMaxItems, nil, errorSchemas, printOptions())
+{{- end}}
+            }
+{{- end}}
             resp, err := client.Do(req)
             if err != nil {
-                return err
+                return &utils.NetworkError{Err: err}
             }
             
             {{if .ResponseModel}}
             var respObj {{if .IsArray}}[]{{end}}models.{{.ResponseModel}}
-            if err := utils.ResponsePrint(resp, &respObj, Output); err != nil {
+            if err := utils.ResponsePrint(resp, &respObj, errorSchemas, printOptions()); err != nil {
                 return err
             }
             {{else}}
-            if err := utils.ResponsePrint(resp, nil, Output); err != nil {
+            if err := utils.ResponsePrint(resp, nil, errorSchemas, printOptions()); err != nil {
                 return err
             }
             {{end}}
@@ -88,16 +114,31 @@ const rootTemplate = `package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"{{.ModuleName}}/utils"
 )
 
 var Debug bool
 var Env string
+var ServerVars []string
 var Output string
+var Query string
+var Columns []string
+var FailOn []string
+
+func printOptions() utils.PrintOptions {
+	return utils.PrintOptions{Format: Output, Query: Query, Columns: Columns, FailOn: FailOn}
+}
 
 func NewRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "{{.ModuleName}}",
 		Short: "{{.ModuleName}} is a command-line tool to interact with the API",
+		Long: ` + "`" + `{{.ModuleName}} is a command-line tool to interact with the API.
+
+Exit codes: 1 for usage and other errors, 3 when no response came back,
+4 for 4xx responses, 5 for 5xx, 6 for 401 and 403, and 7 for 404.` + "`" + `,
+		// errors are printed by main, and failed responses by the command
+		SilenceErrors: true,
 		CompletionOptions: cobra.CompletionOptions{
 			DisableDefaultCmd: false,
 			HiddenDefaultCmd:  false,
@@ -105,108 +146,582 @@ func NewRootCmd() *cobra.Command {
 	}
 	
 	cmd.PersistentFlags().BoolVar(&Debug, "debug", false, "Debug mode Show request/response details")
-	cmd.PersistentFlags().StringVar(&Env, "env", "", "Environment to use (e.g. production, staging)")
-	cmd.PersistentFlags().StringVar(&Output, "output", "pretty", "Output format (pretty, json, table, csv)")
+	cmd.PersistentFlags().StringVar(&Env, "env", "", "Server to use, by name or number (see the servers command)")
+	cmd.PersistentFlags().StringArrayVar(&ServerVars, "server-var", nil, "Server variable as name=value, e.g. region=eu (repeatable)")
+	cmd.PersistentFlags().StringVar(&Output, "output", "pretty", "Output format (pretty, json, yaml, ndjson, raw, table, csv)")
+	cmd.PersistentFlags().StringVar(&Query, "query", "", "Filter the JSON response with a jq/JMESPath subset, e.g. '.items[] | select(.active) | .name' or 'items[?active].name'")
+	cmd.PersistentFlags().StringSliceVar(&Columns, "columns", nil, "Columns for table and csv output, as field paths, e.g. id,owner.login")
+	cmd.PersistentFlags().StringSliceVar(&FailOn, "fail-on", []string{"4xx", "5xx"}, "Response statuses that fail the command: classes such as 4xx, codes such as 404, or none")
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// flags parsed; errors from here on are not usage errors
+		cmd.SilenceUsage = true
+		return printOptions().Validate()
+	}
 
 	return cmd
 }
 `
 
-const utilTemplate = `package utils
+const serversTemplate = `package cmd
+
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"reflect"
+	"sort"
 	"strings"
+	"text/tabwriter"
+
+	"{{.ModuleName}}/config"
+
+	"github.com/spf13/cobra"
 )
 
-func GetBodyReader(body string) (io.Reader, error) {
-	var bodyReader io.Reader
-	if body != "" {
-		if strings.HasPrefix(body, "@") {
-			fname := strings.TrimPrefix(body, "@")
-			var data []byte
-			var err error
-			if fname == "-" {
-				data, err = io.ReadAll(os.Stdin)
-				if err != nil {
-					return nil, err
-				}
-			} else {
-				data, err = os.ReadFile(fname)
-				if err != nil {
-					return nil, err
-				}
+func NewServersCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "servers",
+		Short: "List the API servers and their variables",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := config.Load("{{.ModuleName}}", Env)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			printServers(w, config.Servers)
+			for _, name := range sortedCommands() {
+				fmt.Fprintf(w, "\n%s:\n", name)
+				printServers(w, config.CommandServers[name])
 			}
-			bodyReader = bytes.NewReader(data)
-		} else if body == "-" {
-			data, err := io.ReadAll(os.Stdin)
+			w.Flush()
+
+			baseURL, err := cfg.ServerURL(config.Servers, ServerVars)
 			if err != nil {
-				return nil, err
-				}
-			bodyReader = bytes.NewReader(data)
-		} else {
-			bodyReader = bytes.NewReader([]byte(body))
+				return err
+			}
+			fmt.Printf("\nBase URL: %s\n", baseURL)
+			return nil
+		},
+	}
+}
+
+func printServers(w *tabwriter.Writer, servers []config.Server) {
+	for i, s := range servers {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", i+1, s.Name, s.URL)
+		for _, v := range s.Variables {
+			line := fmt.Sprintf("\t  {%s}\tdefault %q", v.Name, v.Default)
+			if len(v.Enum) > 0 {
+				line += ", one of " + strings.Join(v.Enum, ", ")
+			}
+			if v.Description != "" {
+				line += " - " + v.Description
+			}
+			fmt.Fprintln(w, line)
 		}
-	} else {
-		return nil, nil
 	}
-	return bodyReader, nil
 }
 
-func ResponsePrint(resp *http.Response, target interface{}, format string) error {
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+func sortedCommands() []string {
+	names := make([]string, 0, len(config.CommandServers))
+	for name := range config.CommandServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+`
+
+const authTemplate = `package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"{{.ModuleName}}/config"
+	"{{.ModuleName}}/oauth"
+)
+
+const appName = "{{.ModuleName}}"
+
+// DefaultRedirectURI is the loopback address the authorization code flow
+// listens on when no redirect_uri is configured.
+const DefaultRedirectURI = "http://127.0.0.1:8085/callback"
+
+type Flow struct {
+	Grant         string
+	AuthURL       string
+	TokenURL      string
+	RefreshURL    string
+	DeviceAuthURL string
+	Scopes        []string
+}
+
+type Scheme struct {
+	Name      string
+	Issuer    string
+	KeyPrefix string // prefix of the scheme's config keys
+	Flows     []Flow
+}
+
+var Schemes = {{.Schemes}}
+
+type storedToken struct {
+	AccessToken  string ` + "`" + `json:"access_token"` + "`" + `
+	RefreshToken string ` + "`" + `json:"refresh_token,omitempty"` + "`" + `
+	TokenType    string ` + "`" + `json:"token_type,omitempty"` + "`" + `
+	ExpiresAt    string ` + "`" + `json:"expires_at,omitempty"` + "`" + `
+	Grant        string ` + "`" + `json:"grant"` + "`" + `
+	Scope        string ` + "`" + `json:"scope,omitempty"` + "`" + `
+}
+
+// Find returns the scheme called name, or the only scheme when name is empty.
+func Find(name string) (Scheme, error) {
+	if name == "" && len(Schemes) == 1 {
+		return Schemes[0], nil
+	}
+	names := make([]string, len(Schemes))
+	for i, s := range Schemes {
+		if s.Name == name {
+			return s, nil
+		}
+		names[i] = s.Name
+	}
+	if name == "" {
+		return Scheme{}, fmt.Errorf("choose a security scheme: %v", names)
+	}
+	return Scheme{}, fmt.Errorf("unknown security scheme %q (available: %v)", name, names)
+}
+
+// Flow returns the flow for grant, or the first flow the scheme declares.
+func (s Scheme) Flow(grant string) (Flow, error) {
+	for _, f := range s.Flows {
+		if grant == "" || f.Grant == grant {
+			return f, nil
+		}
+	}
+	grants := make([]string, len(s.Flows))
+	for i, f := range s.Flows {
+		grants[i] = f.Grant
+	}
+	return Flow{}, fmt.Errorf("%s does not support the %s grant (available: %v)", s.Name, grant, grants)
+}
+
+func (s Scheme) oauthConfig(f Flow, client config.OAuthClient) oauth.Config {
+	cfg := oauth.Config{
+		GrantType:     f.Grant,
+		Issuer:        s.Issuer,
+		ClientID:      client.ID,
+		ClientSecret:  client.Secret,
+		AuthURL:       f.AuthURL,
+		TokenURL:      f.TokenURL,
+		DeviceAuthURL: f.DeviceAuthURL,
+		RedirectURI:   client.RedirectURI,
+		Scope:         client.Scope,
+		Username:      client.Username,
+		Password:      client.Password,
+	}
+	if cfg.RedirectURI == "" {
+		cfg.RedirectURI = DefaultRedirectURI
+	}
+	return cfg
+}
+
+// Login runs the scheme's flow for grant and caches the token it returns for
+// use against server.
+func Login(ctx context.Context, s Scheme, server, grant string, client config.OAuthClient, openBrowser func(string), onDeviceCode func(oauth.DeviceAuthorization)) error {
+	f, err := s.Flow(grant)
+	if err != nil {
+		return err
+	}
+	if client.ID == "" {
+		return fmt.Errorf("no client ID configured for %s; set %sclient_id in the config file or %s_%sCLIENT_ID",
+			s.Name, s.KeyPrefix, strings.ToUpper(appName), strings.ToUpper(s.KeyPrefix))
+	}
+	oauth.AppName = appName
+	token, err := oauth.PerformOAuthFlow(ctx, s.oauthConfig(f, client), openBrowser, onDeviceCode)
 	if err != nil {
 		return err
 	}
+	return saveToken(tokenKey(s.Name, server, client.ID), token, storedToken{Grant: f.Grant, Scope: client.Scope})
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fmt.Println("Request failed:")
-		fmt.Printf("%-15s: %s\n", "Error", resp.Status)
-		fmt.Printf("%-15s: %s\n", "URL", resp.Request.URL.String())
-		fmt.Printf("%-15s: %s\n", "METHOD", resp.Request.Method)
-		fmt.Println("----------------")
+// Available reports whether AccessToken can return a token for the scheme
+// called name on server: a token is cached, or the client is configured for a
+// grant that needs no user.
+func Available(name, server string, client config.OAuthClient) bool {
+	if tokens, err := loadTokens(); err == nil && tokens[tokenKey(name, server, client.ID)].AccessToken != "" {
+		return true
 	}
+	s, err := Find(name)
+	if err != nil {
+		return false
+	}
+	_, ok := s.unattendedFlow(client)
+	return ok
+}
 
-	if strings.Contains(resp.Header.Get("Content-Type"), "json") {
-		if target != nil {
-			if err := json.Unmarshal(body, target); err != nil {
+// unattendedFlow returns the client credentials or password flow when client
+// is configured for it.
+func (s Scheme) unattendedFlow(client config.OAuthClient) (Flow, bool) {
+	if client.ID == "" {
+		return Flow{}, false
+	}
+	for _, f := range s.Flows {
+		switch {
+		case f.Grant == oauth.GrantClientCredentials && client.Secret != "",
+			f.Grant == oauth.GrantPassword && client.Username != "":
+			return f, true
+		}
+	}
+	return Flow{}, false
+}
+
+// AccessToken returns a token for the scheme called name on server that was
+// granted scopes. Tokens that have expired, or will within 30 seconds, are refreshed
+// first; when no refresh token was issued, grants that need no user are run
+// again, which is also how a token is fetched without logging in.
+func AccessToken(ctx context.Context, name, server string, client config.OAuthClient, scopes []string) (string, error) {
+	s, err := Find(name)
+	if err != nil {
+		return "", err
+	}
+	tokens, err := loadTokens()
+	if err != nil {
+		return "", err
+	}
+	key := tokenKey(name, server, client.ID)
+	t, ok := tokens[key]
+	switch {
+	case !ok || t.AccessToken == "":
+		f, ok := s.unattendedFlow(client)
+		if !ok {
+			return "", fmt.Errorf("not logged in to %s; run '%s login %s'", name, appName, name)
+		}
+		t = storedToken{Grant: f.Grant, Scope: client.Scope}
+		if t.Scope == "" {
+			t.Scope = strings.Join(scopes, " ")
+		}
+	case t.Scope != "":
+		granted := strings.Fields(t.Scope)
+		var missing []string
+		for _, scope := range scopes {
+			if !contains(granted, scope) {
+				missing = append(missing, scope)
+				granted = append(granted, scope)
+			}
+		}
+		if f, ok := s.unattendedFlow(client); ok && len(missing) > 0 {
+			client.Scope = strings.Join(granted, " ")
+			t = storedToken{Grant: f.Grant, Scope: client.Scope}
+		} else if len(missing) > 0 {
+			return "", fmt.Errorf("the %s token was not granted %s; run '%s login %s --scope \"%s\"'",
+				name, strings.Join(missing, ", "), appName, name, strings.Join(granted, " "))
+		}
+	}
+	if t.AccessToken != "" && !oauth.Expired(t.ExpiresAt, 30*time.Second) {
+		return t.AccessToken, nil
+	}
+
+	f, err := s.Flow(t.Grant)
+	if err != nil {
+		return "", err
+	}
+	if client.Scope == "" {
+		client.Scope = t.Scope
+	}
+	cfg := s.oauthConfig(f, client)
+	var token oauth.Token
+	switch {
+	case t.RefreshToken != "":
+		if f.RefreshURL != "" {
+			cfg.TokenURL = f.RefreshURL
+		}
+		token, err = oauth.RefreshToken(ctx, cfg, t.RefreshToken)
+	case !cfg.Interactive():
+		token, err = oauth.PerformOAuthFlow(ctx, cfg, nil, nil)
+	default:
+		err = errors.New("the token has expired")
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w; run '%s login %s'", name, err, appName, name)
+	}
+	if err := saveToken(key, token, t); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Logout forgets the token cached for the scheme called name on server.
+func Logout(name, server string, client config.OAuthClient) error {
+	tokens, err := loadTokens()
+	if err != nil {
+		return err
+	}
+	key := tokenKey(name, server, client.ID)
+	if _, ok := tokens[key]; !ok {
+		return fmt.Errorf("not logged in to %s on %s", name, server)
+	}
+	delete(tokens, key)
+	return writeTokens(tokens)
+}
+
+// tokenKey identifies a cached token. Tokens are only reused against the
+// server and with the client they were obtained for, so that one fetched for
+// a staging server or another client_id is never sent elsewhere.
+func tokenKey(name, server, clientID string) string {
+	return name + " " + server + " " + clientID
+}
+
+func tokenFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appName, "tokens.json"), nil
+}
+
+func loadTokens() (map[string]storedToken, error) {
+	tokens := make(map[string]storedToken)
+	path, err := tokenFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return tokens, nil
+}
+
+// saveToken caches token, keeping the grant of previous and its scope
+// unless the provider reports the granted one. Providers that do not rotate refresh tokens leave them out of refresh
+// responses, so the previous one is kept.
+func saveToken(key string, token oauth.Token, previous storedToken) error {
+	tokens, err := loadTokens()
+	if err != nil {
+		return err
+	}
+	t := storedToken{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenType:    token.TokenType,
+		Grant:        previous.Grant,
+		Scope:        previous.Scope,
+	}
+	if token.Scope != "" {
+		t.Scope = token.Scope
+	}
+	if t.RefreshToken == "" {
+		t.RefreshToken = previous.RefreshToken
+	}
+	if token.ExpiresIn > 0 {
+		t.ExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second).UTC().Format(time.RFC3339)
+	}
+	tokens[key] = t
+	return writeTokens(tokens)
+}
+
+func writeTokens(tokens map[string]storedToken) error {
+	path, err := tokenFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+`
+
+const loginTemplate = `package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+
+	"{{.ModuleName}}/auth"
+	"{{.ModuleName}}/config"
+	"{{.ModuleName}}/oauth"
+
+	"github.com/spf13/cobra"
+)
+
+func NewLoginCmd() *cobra.Command {
+	var grant string
+	var scope string
+	var noBrowser bool
+	cmd := &cobra.Command{
+		Use:   "login [scheme]",
+		Short: "Log in with OAuth2 and cache the token for later commands",
+		Long: ` + "`" + `Log in with one of the API's OAuth2 security schemes. The token is cached in
+the user config directory for the server --env selects and the configured
+client, and refreshed automatically when it expires.
+
+{{.LoginHelp}}` + "`" + `,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{ {{- range $i, $s := .SchemeNames}}{{if $i}}, {{end}}{{printf "%q" $s}}{{end -}} },
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			scheme, err := auth.Find(name)
+			if err != nil {
 				return err
 			}
-			if format == "table" {
-				TablePrint(target)
-				return nil
+			cfg := config.Load("{{.ModuleName}}", Env)
+			client := cfg.OAuthClients[scheme.Name]
+			if scope != "" {
+				client.Scope = scope
 			}
-			if format == "csv" {
-				CSVPrint(target)
-				return nil
+			if client.Scope == "" {
+				if f, err := scheme.Flow(grant); err == nil {
+					client.Scope = strings.Join(f.Scopes, " ")
+				}
 			}
 
-			prettyJSON, err := json.MarshalIndent(target, "", "  ")
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			openBrowser := func(url string) {
+				fmt.Fprintf(os.Stderr, "Open this URL to log in:\n\n  %s\n\n", url)
+				if !noBrowser {
+					launchBrowser(url)
+				}
+			}
+			onDeviceCode := func(d oauth.DeviceAuthorization) {
+				fmt.Fprintf(os.Stderr, "Go to %s and enter the code %s\n", d.VerificationURI, d.UserCode)
+				if d.VerificationURIComplete != "" && !noBrowser {
+					launchBrowser(d.VerificationURIComplete)
+				}
+			}
+			server, err := cfg.ServerURL(config.Servers, ServerVars)
 			if err != nil {
 				return err
 			}
-			fmt.Println("Response body:\n" + string(prettyJSON))
-		} else {
-			var pretty interface{}
-			if err := json.Unmarshal(body, &pretty); err != nil {
+			if err := auth.Login(ctx, scheme, server, grant, client, openBrowser, onDeviceCode); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Logged in to %s on %s\n", scheme.Name, server)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&grant, "grant", "", "Grant to use: authorization_code, device_code, client_credentials or password (default: the first the scheme declares)")
+	cmd.Flags().StringVar(&scope, "scope", "", "Space-separated scopes to request (default: the configured scope, or all the scheme declares)")
+	cmd.Flags().BoolVar(&noBrowser, "no-browser", false, "Print the login URL instead of opening a browser")
+	return cmd
+}
+
+func NewLogoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:       "logout [scheme]",
+		Short:     "Forget the cached OAuth2 token",
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{ {{- range $i, $s := .SchemeNames}}{{if $i}}, {{end}}{{printf "%q" $s}}{{end -}} },
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			scheme, err := auth.Find(name)
+			if err != nil {
 				return err
 			}
-			prettyJSON, err := json.MarshalIndent(pretty, "", "  ")
+			cfg := config.Load("{{.ModuleName}}", Env)
+			server, err := cfg.ServerURL(config.Servers, ServerVars)
 			if err != nil {
 				return err
 			}
-			fmt.Println("Response body:\n" + string(prettyJSON))
+			return auth.Logout(scheme.Name, server, cfg.OAuthClients[scheme.Name])
+		},
+	}
+}
+
+func launchBrowser(url string) {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		c = exec.Command("open", url)
+	case "windows":
+		c = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		c = exec.Command("xdg-open", url)
+	}
+	_ = c.Start()
+}
+`
+
+const utilTemplate = `package utils
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+func GetBodyReader(body string) (io.Reader, error) {
+	if body == "" {
+		return nil, nil
+	}
+	data, err := ReadBody(body)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+func ResponsePrint(resp *http.Response, target interface{}, errs ErrorSchemas, opts PrintOptions) error {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &NetworkError{Err: err}
+	}
+
+	success := resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !success && opts.fails(resp.StatusCode) {
+		return apiError(resp, body, errs, opts)
+	}
+
+	// HEAD requests and 204 responses carry no body to decode
+	if len(bytes.TrimSpace(body)) == 0 {
+		fmt.Printf("%-15s: %s\n", "Status", resp.Status)
+		return nil
+	}
+	if !success {
+		// a status --fail-on lets through prints like a success, without
+		// the success model
+		if opts.Format == "pretty" {
+			fmt.Printf("%-15s: %s\n", "Status", resp.Status)
 		}
-	} else {
-		fmt.Println("Response body:\n" + string(body))
+		target = nil
 	}
-	return nil
+	return printBody(body, resp.Header.Get("Content-Type"), target, opts)
 }
 
 func DebugPrintRequest(req *http.Request, bodyReader *io.Reader) error {
@@ -229,116 +744,1942 @@ func DebugPrintRequest(req *http.Request, bodyReader *io.Reader) error {
 				return err
 			}
 			fmt.Printf("%-15s: %s\n", "Request Body", string(prettyDebugJSON))
-		} else {
+		} else if utf8.Valid(data) && bytes.IndexByte(data, 0) < 0 {
 			fmt.Printf("%-15s: %s\n", "Request Body", string(data))
+		} else {
+			fmt.Printf("%-15s: (%d bytes of binary data)\n", "Request Body", len(data))
 		}
 		*bodyReader = bytes.NewReader(data) // reset bodyReader
+		// the request wraps the reader just drained
+		req.Body = io.NopCloser(bytes.NewReader(data))
 	} else {
 		fmt.Printf("%-15s: %s\n", "Request Body", "(empty)")
 	}
 	fmt.Println("----------------")
 	return nil
 }
+`
 
-func CheckBody(body string, bodyObj map[string]interface{}, bodyReader *io.Reader) error {
-	if body == "" && len(bodyObj) <= 0 {
-		return fmt.Errorf("request body is required (use either --body or body flags!!!)")
-	}
-	if body == "" && len(bodyObj) > 0 {
-		data, err := json.Marshal(bodyObj)
-		if err != nil {
-			return err
-		}
-		*bodyReader = bytes.NewReader(data)
+const bodyTemplate = `package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrBodyRequired = errors.New("request body is required (use --body or the --body-* flags)")
+
+// ReadBody returns the --body value: raw text, @filename, or - (or @-) for stdin.
+func ReadBody(body string) ([]byte, error) {
+	switch {
+	case body == "-" || body == "@-":
+		return io.ReadAll(os.Stdin)
+	case strings.HasPrefix(body, "@"):
+		return os.ReadFile(strings.TrimPrefix(body, "@"))
 	}
-	return nil
+	return []byte(body), nil
 }
 
-func TablePrint(target interface{}) {
-	v := reflect.ValueOf(target)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
+// JSONBody is a JSON request body built from --body and the body flags,
+// whose values override the ones --body sets.
+type JSONBody struct {
+	raw     []byte
+	value   interface{}
+	invalid error // why raw is not JSON
+	changed bool
+}
 
-	if v.Kind() != reflect.Slice {
-		fmt.Println("Table output only supported for lists")
-		return
+func NewJSONBody(body string) (*JSONBody, error) {
+	data, err := ReadBody(body)
+	if err != nil {
+		return nil, err
 	}
-
-	if v.Len() == 0 {
-		fmt.Println("No data found")
-		return
+	b := &JSONBody{raw: data}
+	if len(bytes.TrimSpace(data)) > 0 {
+		b.invalid = json.Unmarshal(data, &b.value)
 	}
+	return b, nil
+}
 
-	t := v.Index(0).Type()
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+// Set stores value at path, creating the objects along it. An empty path
+// replaces the whole body.
+func (b *JSONBody) Set(value interface{}, path ...string) error {
+	if b.invalid != nil {
+		return fmt.Errorf("--body is not valid JSON, so the body flags cannot be merged into it: %w", b.invalid)
 	}
+	b.changed = true
+	if len(path) == 0 {
+		b.value = value
+		return nil
+	}
+	if b.value == nil {
+		b.value = map[string]interface{}{}
+	}
+	obj, ok := b.value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("cannot set %s: the body is not a JSON object", strings.Join(path, "."))
+	}
+	for i, key := range path[:len(path)-1] {
+		if obj[key] == nil {
+			obj[key] = map[string]interface{}{}
+		}
+		child, ok := obj[key].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cannot set %s: %s is not a JSON object", strings.Join(path, "."), strings.Join(path[:i+1], "."))
+		}
+		obj = child
+	}
+	obj[path[len(path)-1]] = value
+	return nil
+}
+
+// Require checks the required fields at paths. Fields of an optional
+// object are only required when the object is present.
+func (b *JSONBody) Require(paths ...[]string) error {
+	if b.value == nil || b.invalid != nil {
+		return nil
+	}
+	for _, path := range paths {
+		obj, ok := b.value.(map[string]interface{})
+		for _, key := range path[:len(path)-1] {
+			if !ok {
+				break
+			}
+			obj, ok = obj[key].(map[string]interface{})
+		}
+		if !ok {
+			continue
+		}
+		if _, ok := obj[path[len(path)-1]]; !ok {
+			return fmt.Errorf("missing required body field %s; set it with the --body-* flags or in --body", strings.Join(path, "."))
+		}
+	}
+	return nil
+}
+
+// Reader returns the body to send, or nil when there is none. --body is
+// sent as given unless a flag changed it.
+func (b *JSONBody) Reader(required bool) (io.Reader, error) {
+	if !b.changed {
+		if len(bytes.TrimSpace(b.raw)) == 0 {
+			if required {
+				return nil, ErrBodyRequired
+			}
+			return nil, nil
+		}
+		return bytes.NewReader(b.raw), nil
+	}
+	data, err := json.Marshal(b.value)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// Fields describes the keys of an object flag. Types holds their JSON
+// types, with array:<type> for arrays.
+type Fields struct {
+	Types    map[string]string
+	Required []string
+}
+
+// ParseObject parses an object flag: a JSON object, or key=value pairs such
+// as name=x,qty=2. A comma that is not followed by key= continues the
+// previous value, so tags=a,b sets an array.
+func ParseObject(s string, fields Fields) (map[string]interface{}, error) {
+	obj := make(map[string]interface{})
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		if err := json.Unmarshal([]byte(s), &obj); err != nil {
+			return nil, fmt.Errorf("invalid JSON object %q: %w", s, err)
+		}
+	} else {
+		var pairs []string
+		for _, part := range strings.Split(s, ",") {
+			if !strings.Contains(part, "=") && len(pairs) > 0 {
+				pairs[len(pairs)-1] += "," + part
+				continue
+			}
+			pairs = append(pairs, part)
+		}
+		for _, pair := range pairs {
+			key, value, ok := strings.Cut(pair, "=")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				return nil, fmt.Errorf("invalid value %q: use key=value pairs such as name=x,qty=2, or a JSON object", s)
+			}
+			v, err := convert(value, fields.Types[key])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s in %q: %w", key, s, err)
+			}
+			obj[key] = v
+		}
+	}
+	for _, key := range fields.Required {
+		if _, ok := obj[key]; !ok {
+			return nil, fmt.Errorf("missing required field %s in %q", key, s)
+		}
+	}
+	return obj, nil
+}
+
+// ParseObjects parses the values of a repeated object flag.
+func ParseObjects(values []string, fields Fields) ([]interface{}, error) {
+	objs := make([]interface{}, 0, len(values))
+	for _, s := range values {
+		obj, err := ParseObject(s, fields)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// ParseValue decodes s as JSON, keeping it as a string when it is not JSON.
+func ParseValue(s string) interface{} {
+	v, _ := convert(s, "")
+	return v
+}
+
+// convert turns s into a value of the JSON type typ. Values of unknown type
+// are decoded as JSON when they are valid JSON and kept as strings otherwise.
+func convert(s, typ string) (interface{}, error) {
+	if item, ok := strings.CutPrefix(typ, "array:"); ok {
+		if strings.HasPrefix(strings.TrimSpace(s), "[") {
+			return convert(s, "")
+		}
+		var items []interface{}
+		for _, part := range strings.Split(s, ",") {
+			v, err := convert(part, item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+	}
+	switch typ {
+	case "string":
+		return s, nil
+	case "integer":
+		return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	case "number":
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	case "boolean":
+		return strconv.ParseBool(strings.TrimSpace(s))
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err == nil {
+		return v, nil
+	}
+	return s, nil
+}
+
+// Form is a url-encoded or multipart request body built from --body and
+// the body flags. --body may be url-encoded (a=1&b=2) or a JSON object.
+type Form struct {
+	values url.Values
+	files  map[string][]string
+}
+
+func NewForm(body string) (*Form, error) {
+	f := &Form{values: url.Values{}, files: make(map[string][]string)}
+	data, err := ReadBody(body)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return f, nil
+	}
+	if data[0] != '{' {
+		if f.values, err = url.ParseQuery(string(data)); err != nil {
+			return nil, fmt.Errorf("invalid form body: %w", err)
+		}
+		return f, nil
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("invalid form body: %w", err)
+	}
+	for key, v := range obj {
+		f.Set(key, v)
+	}
+	return f, nil
+}
+
+// Set replaces the field's values. Slices set one value per element.
+func (f *Form) Set(name string, value interface{}) {
+	var values []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			values = append(values, formValue(item))
+		}
+	case []string:
+		values = v
+	case []int:
+		for _, item := range v {
+			values = append(values, strconv.Itoa(item))
+		}
+	case []float64:
+		for _, item := range v {
+			values = append(values, strconv.FormatFloat(item, 'f', -1, 64))
+		}
+	case []bool:
+		for _, item := range v {
+			values = append(values, strconv.FormatBool(item))
+		}
+	default:
+		values = []string{formValue(v)}
+	}
+	f.values[name] = values
+}
+
+func formValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// SetFiles replaces the files uploaded in the field; - reads stdin.
+func (f *Form) SetFiles(name string, paths ...string) {
+	f.files[name] = paths
+}
+
+func (f *Form) Require(names ...string) error {
+	for _, name := range names {
+		if len(f.values[name]) == 0 && len(f.files[name]) == 0 {
+			return fmt.Errorf("missing required form field %s (set --body-%s or include it in --body)", name, name)
+		}
+	}
+	return nil
+}
+
+// Reader encodes the form, returning the body and its content type, or a
+// nil body when the form is empty.
+func (f *Form) Reader(multipartForm bool, required bool) (io.Reader, string, error) {
+	if len(f.values) == 0 && len(f.files) == 0 {
+		if required {
+			return nil, "", ErrBodyRequired
+		}
+		return nil, "", nil
+	}
+	if !multipartForm {
+		if len(f.files) > 0 {
+			return nil, "", errors.New("files can only be uploaded in a multipart body")
+		}
+		return strings.NewReader(f.values.Encode()), "application/x-www-form-urlencoded", nil
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	names := make([]string, 0, len(f.values))
+	for name := range f.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range f.values[name] {
+			if err := w.WriteField(name, v); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	names = names[:0]
+	for name := range f.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, path := range f.files[name] {
+			if err := writeFile(w, name, path); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return &buf, w.FormDataContentType(), nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", ` + "`" + `"` + "`" + `, "\\\"")
+
+func writeFile(w *multipart.Writer, field, path string) error {
+	data, err := ReadBody("@" + path)
+	if err != nil {
+		return err
+	}
+	filename := filepath.Base(path)
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if path == "-" {
+		filename = field
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(` + "`" + `form-data; name="%s"; filename="%s"` + "`" + `, quoteEscaper.Replace(field), quoteEscaper.Replace(filename)))
+	h.Set("Content-Type", contentType)
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(data)
+	return err
+}
+`
+
+const paginateTemplate = `package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Pagination describes how the pages of a list operation are linked. A Link
+// header with rel="next" is followed whatever the style.
+type Pagination struct {
+	Style     string   // cursor, offset, page, next or link
+	Param     string   // query parameter taking the cursor, offset or page number
+	SizeParam string   // query parameter taking the page size
+	Start     int      // first offset or page number
+	NextPath  []string // response field holding the next cursor or page URL
+	ItemsPath []string // response field holding the items; empty when the page is the list
+}
+
+// PaginatedPrint follows the pages of req until they run out or, when
+// maxItems is positive, that many items were read, and prints the items as
+// one list.
+func PaginatedPrint(client *http.Client, req *http.Request, p Pagination, maxItems int, target interface{}, errs ErrorSchemas, opts PrintOptions) error {
+	items := []json.RawMessage{}
+	seen := map[string]bool{req.URL.String(): true}
+	for {
+		resp, err := client.Do(req)
+		if err != nil {
+			return &NetworkError{Err: err}
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return ResponsePrint(resp, nil, errs, opts)
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return &NetworkError{Err: err}
+		}
+		page, doc, err := p.items(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", req.URL, err)
+		}
+		if maxItems > 0 && len(items)+len(page) > maxItems {
+			page = page[:maxItems-len(items)]
+		}
+		items = append(items, page...)
+		if len(page) == 0 || (maxItems > 0 && len(items) >= maxItems) {
+			break
+		}
+
+		next, err := p.next(req.URL, resp.Header, doc, len(page))
+		if err != nil {
+			return err
+		}
+		if next == nil || seen[next.String()] {
+			break
+		}
+		seen[next.String()] = true
+		req = req.Clone(req.Context())
+		req.URL, req.Host = next, next.Host
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return printBody(data, "application/json", target, opts)
+}
+
+func (p Pagination) items(data []byte) ([]json.RawMessage, interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("the page is not JSON: %w", err)
+	}
+	found := lookup(doc, p.ItemsPath)
+	if found == nil {
+		return nil, doc, nil
+	}
+	list, ok := found.([]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("the page has no list of items at %q", strings.Join(p.ItemsPath, "."))
+	}
+	page := make([]json.RawMessage, len(list))
+	for i, item := range list {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, nil, err
+		}
+		page[i] = data
+	}
+	return page, doc, nil
+}
+
+// next returns the URL of the page after current, or nil after the last.
+// Offset and page numbers stop once a page comes back short.
+func (p Pagination) next(current *url.URL, header http.Header, doc interface{}, count int) (*url.URL, error) {
+	if link := nextLink(header); link != "" {
+		return current.Parse(link)
+	}
+	q := current.Query()
+	switch p.Style {
+	case "cursor", "next":
+		var next string
+		switch v := lookup(doc, p.NextPath).(type) {
+		case string:
+			next = v
+		case json.Number:
+			next = v.String()
+		}
+		if next == "" {
+			return nil, nil
+		}
+		if p.Param == "" || strings.Contains(next, "://") || strings.HasPrefix(next, "/") || strings.HasPrefix(next, "?") {
+			return current.Parse(next)
+		}
+		q.Set(p.Param, next)
+	case "offset", "page":
+		if size, err := strconv.Atoi(q.Get(p.SizeParam)); err == nil && size > 0 && count < size {
+			return nil, nil
+		}
+		n := p.Start
+		if v := q.Get(p.Param); v != "" {
+			var err error
+			if n, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("cannot page on from %s=%s: %w", p.Param, v, err)
+			}
+		}
+		if p.Style == "offset" {
+			n += count
+		} else {
+			n++
+		}
+		q.Set(p.Param, strconv.Itoa(n))
+	default:
+		return nil, nil
+	}
+	next := *current
+	next.RawQuery = q.Encode()
+	return &next, nil
+}
+
+func lookup(doc interface{}, path []string) interface{} {
+	for _, key := range path {
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		doc = obj[key]
+	}
+	return doc
+}
+
+// nextLink returns the rel="next" target of RFC 8288 Link headers.
+func nextLink(header http.Header) string {
+	for _, v := range header.Values("Link") {
+		for _, link := range strings.Split(v, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(value, "\"")) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+`
+
+const errorsTemplate = `package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Exit codes of the CLI; responses only fail the command when --fail-on
+// matches their status.
+const (
+	ExitError        = 1 // usage and other errors
+	ExitNetworkError = 3 // no response: connection, DNS, TLS or timeout
+	ExitClientError  = 4 // other 4xx responses
+	ExitServerError  = 5 // 5xx responses
+	ExitAuthError    = 6 // 401 and 403 responses
+	ExitNotFound     = 7 // 404 responses
+)
+
+// ErrorSchemas make the models of an operation's error responses, keyed by
+// status code, range (4XX) or "default".
+type ErrorSchemas map[string]func() interface{}
+
+// APIError is a response whose status the command fails on. Body is the
+// decoded JSON, or else the text, of the response; Model is the body decoded
+// into the error schema the spec declares for the status, if it fits one.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	URL        string
+	Body       interface{}
+	Model      interface{}
+}
+
+func (e *APIError) Error() string {
+	if msg := e.message(); msg != "" {
+		return e.Status + ": " + msg
+	}
+	return e.Status
+}
+
+// message finds the reason in RFC 7807 problem details (title, detail) or
+// the usual message and error fields.
+func (e *APIError) message() string {
+	obj, ok := e.Body.(map[string]interface{})
+	if !ok {
+		s, _ := e.Body.(string)
+		if len(s) > 200 || strings.Contains(s, "\n") {
+			return ""
+		}
+		return s
+	}
+	title, _ := obj["title"].(string)
+	detail, _ := obj["detail"].(string)
+	if strings.EqualFold(title, http.StatusText(e.StatusCode)) {
+		// the status line says as much
+		title = ""
+	}
+	switch {
+	case title != "" && detail != "":
+		return title + ": " + detail
+	case detail != "":
+		return detail
+	case title != "":
+		return title
+	}
+	for _, key := range []string{"message", "error_description", "error"} {
+		switch v := obj[key].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case map[string]interface{}:
+			if msg, ok := v["message"].(string); ok && msg != "" {
+				return msg
+			}
+		}
+	}
+	return ""
+}
+
+// NetworkError is a request that got no response.
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string { return e.Err.Error() }
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+// ExitCode returns the exit code for an error returned by a command.
+func ExitCode(err error) int {
+	var apiErr *APIError
+	var netErr *NetworkError
+	switch {
+	case errors.As(err, &netErr):
+		return ExitNetworkError
+	case !errors.As(err, &apiErr):
+		return ExitError
+	case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+		return ExitAuthError
+	case apiErr.StatusCode == http.StatusNotFound:
+		return ExitNotFound
+	case apiErr.StatusCode >= 400 && apiErr.StatusCode < 500:
+		return ExitClientError
+	case apiErr.StatusCode >= 500:
+		return ExitServerError
+	}
+	return ExitError
+}
+
+// ReportError prints an error returned by a command to stderr, unless it is
+// an APIError that was printed with the response, and returns its exit code.
+func ReportError(err error) int {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	return ExitCode(err)
+}
+
+// validFailOn checks a --fail-on value: none, a class such as 4xx or a
+// status code.
+func validFailOn(s string) error {
+	code := strings.ToLower(s)
+	if code == "none" {
+		return nil
+	}
+	if len(code) == 3 && code[1:] == "xx" {
+		code = code[:1] + "00"
+	}
+	n, err := strconv.Atoi(code)
+	switch {
+	case err != nil || n < 100 || n > 599:
+		return fmt.Errorf("--fail-on %s: use none, a class such as 4xx or a status code", s)
+	case n >= 200 && n < 300:
+		return fmt.Errorf("--fail-on %s: successful responses cannot fail", s)
+	}
+	return nil
+}
+
+// fails reports whether --fail-on makes a response with status fail the
+// command; by default every 4xx and 5xx response does.
+func (o PrintOptions) fails(status int) bool {
+	if o.FailOn == nil {
+		return status >= 400
+	}
+	for _, f := range o.FailOn {
+		f = strings.ToLower(f)
+		if strings.HasSuffix(f, "xx") && f[0] == byte('0'+status/100) || f == strconv.Itoa(status) {
+			return true
+		}
+	}
+	return false
+}
+
+// apiError decodes a failed response, printing it to stderr.
+func apiError(resp *http.Response, body []byte, errs ErrorSchemas, opts PrintOptions) error {
+	e := &APIError{StatusCode: resp.StatusCode, Status: resp.Status, Method: resp.Request.Method, URL: resp.Request.URL.String()}
+	e.Body = string(bytes.TrimSpace(body))
+	if strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		if doc, err := decodeJSON(body); err == nil {
+			e.Body = doc
+		}
+		if model := errs.model(resp.StatusCode); model != nil && json.Unmarshal(body, model) == nil {
+			e.Model = model
+		}
+	}
+
+	switch opts.Format {
+	case "json", "yaml", "ndjson":
+		out := map[string]interface{}{
+			"status":  e.StatusCode,
+			"message": e.Error(),
+			"method":  e.Method,
+			"url":     e.URL,
+			"body":    e.Body,
+		}
+		marshal := prettyJSON
+		if opts.Format == "ndjson" {
+			marshal = marshalJSON
+		}
+		data, err := marshal(map[string]interface{}{"error": out})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, string(data))
+	default:
+		fmt.Fprintln(os.Stderr, "Request failed:", e.Error())
+		fmt.Fprintf(os.Stderr, "%-15s: %s\n", "URL", e.URL)
+		fmt.Fprintf(os.Stderr, "%-15s: %s\n", "METHOD", e.Method)
+		if s, ok := e.Body.(string); ok && s != "" {
+			fmt.Fprintln(os.Stderr, "----------------")
+			fmt.Fprintln(os.Stderr, s)
+		} else if !ok {
+			data, err := prettyJSON(e.Body)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, "----------------")
+			fmt.Fprintln(os.Stderr, string(data))
+		}
+	}
+	return e
+}
+
+func (errs ErrorSchemas) model(status int) interface{} {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", "default"} {
+		if newModel, ok := errs[key]; ok {
+			return newModel()
+		}
+	}
+	return nil
+}
+`
+
+const outputTemplate = `package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PrintOptions are the --output, --query, --columns and --fail-on flags.
+type PrintOptions struct {
+	Format  string
+	Query   string
+	Columns []string
+	FailOn  []string
+}
+
+var Formats = []string{"pretty", "json", "yaml", "ndjson", "raw", "table", "csv"}
+
+// Validate checks the options before a request is sent.
+func (o PrintOptions) Validate() error {
+	if !slices.Contains(Formats, o.Format) {
+		return fmt.Errorf("unknown output format %q; use one of %s", o.Format, strings.Join(Formats, ", "))
+	}
+	if o.Query != "" {
+		if _, err := ParseQuery(o.Query); err != nil {
+			return fmt.Errorf("--query: %w", err)
+		}
+	}
+	for _, column := range o.Columns {
+		if _, err := ParseQuery(column); err != nil {
+			return fmt.Errorf("--columns %s: %w", column, err)
+		}
+	}
+	for _, f := range o.FailOn {
+		if err := validFailOn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printBody prints a response body in the chosen format, after decoding it
+// into target when there is a model for it and applying the query.
+func printBody(body []byte, contentType string, target interface{}, opts PrintOptions) error {
+	if !strings.Contains(contentType, "json") {
+		if opts.Query != "" {
+			return fmt.Errorf("--query needs a JSON response, not %s", contentType)
+		}
+		if opts.Format == "pretty" {
+			fmt.Println("Response body:\n" + string(body))
+			return nil
+		}
+		_, err := os.Stdout.Write(body)
+		return err
+	}
+	if opts.Format == "raw" && opts.Query == "" {
+		_, err := os.Stdout.Write(body)
+		return err
+	}
+
+	data := body
+	var columns []string
+	if target != nil {
+		// decoding into the model checks the response and prints its
+		// fields the way the model names them
+		if err := json.Unmarshal(body, target); err != nil {
+			return err
+		}
+		var err error
+		if data, err = marshalJSON(target); err != nil {
+			return err
+		}
+		columns = modelColumns(target)
+	}
+	doc, err := decodeJSON(data)
+	if err != nil {
+		return err
+	}
+	if opts.Query != "" {
+		q, err := ParseQuery(opts.Query)
+		if err != nil {
+			return fmt.Errorf("--query: %w", err)
+		}
+		if doc, err = q.Apply(doc); err != nil {
+			return fmt.Errorf("--query: %w", err)
+		}
+		if data, err = marshalJSON(doc); err != nil {
+			return err
+		}
+		columns = nil
+	}
+	if len(opts.Columns) > 0 {
+		columns = opts.Columns
+	}
+
+	switch opts.Format {
+	case "json", "pretty":
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return err
+		}
+		if opts.Format == "pretty" {
+			fmt.Println("Response body:")
+		}
+		fmt.Println(buf.String())
+	case "yaml":
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return err
+		}
+		blockStyle(&node)
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "ndjson", "raw":
+		items, ok := doc.([]interface{})
+		if !ok {
+			items = []interface{}{doc}
+		}
+		for _, item := range items {
+			if s, ok := item.(string); ok && opts.Format == "raw" {
+				fmt.Println(s)
+				continue
+			}
+			line, err := marshalJSON(item)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(line))
+		}
+	case "table", "csv":
+		return printRows(doc, columns, opts.Format)
+	}
+	return nil
+}
+
+// printRows prints a list, or a single object, with a column per field path.
+// Columns default to the fields of the model, or else to every field found.
+func printRows(doc interface{}, columns []string, format string) error {
+	rows, ok := doc.([]interface{})
+	if !ok && doc != nil {
+		rows = []interface{}{doc}
+	}
+	if len(rows) == 0 {
+		if format == "table" {
+			fmt.Println("No data found")
+		}
+		return nil
+	}
+	headers := columns
+	if len(columns) == 0 {
+		columns = rowColumns(rows)
+		headers = columns
+		if len(columns) == 0 {
+			// a list of plain values
+			columns, headers = []string{"."}, []string{"value"}
+		}
+	}
+	queries := make([]*Query, len(columns))
+	for i, column := range columns {
+		q, err := ParseQuery(column)
+		if err != nil {
+			return fmt.Errorf("--columns %s: %w", column, err)
+		}
+		queries[i] = q
+	}
+
+	records := [][]string{headers}
+	for _, row := range rows {
+		record := make([]string, len(queries))
+		for i, q := range queries {
+			v, err := q.Apply(row)
+			if err != nil {
+				return fmt.Errorf("--columns %s: %w", columns[i], err)
+			}
+			if record[i], err = cell(v); err != nil {
+				return err
+			}
+		}
+		records = append(records, record)
+	}
+
+	if format == "csv" {
+		w := csv.NewWriter(os.Stdout)
+		return w.WriteAll(records)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, record := range records {
+		for i, v := range record {
+			record[i] = strings.Join(strings.Fields(v), " ")
+		}
+		fmt.Fprintln(w, strings.Join(record, "\t"))
+	}
+	return w.Flush()
+}
+
+// rowColumns returns the fields of the objects in rows, sorted.
+func rowColumns(rows []interface{}) []string {
+	seen := map[string]interface{}{}
+	for _, row := range rows {
+		if obj, ok := row.(map[string]interface{}); ok {
+			for k := range obj {
+				seen[k] = true
+			}
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// modelColumns returns the JSON names of the fields of a model, or of the
+// items of a list of models, in declaration order.
+func modelColumns(target interface{}) []string {
+	t := reflect.TypeOf(target)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) {
+		return nil
+	}
+	var columns []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch {
+		case name == "-" || !f.IsExported():
+		case f.Anonymous && name == "":
+			columns = append(columns, modelColumns(reflect.New(f.Type).Interface())...)
+		case name == "":
+			columns = append(columns, f.Name)
+		default:
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+func cell(v interface{}) (string, error) {
+	switch x := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return x, nil
+	case json.Number:
+		return x.String(), nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	}
+	data, err := marshalJSON(v)
+	return string(data), err
+}
+
+// blockStyle drops the flow style and quoting of YAML decoded from JSON;
+// strings that need quotes keep them.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		blockStyle(child)
+	}
+}
+
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func prettyJSON(v interface{}) ([]byte, error) {
+	data, err := marshalJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+`
 
-	// Print Headers
-	var headers []string
-	for i := 0; i < t.NumField(); i++ {
-		headers = append(headers, t.Field(i).Name)
+const queryTemplate = `package utils
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Query is a parsed --query expression. The language is a small subset
+// common to jq and JMESPath, not a full implementation of either:
+//
+//	pipeline   = or { "|" or }
+//	or         = and { ("or" | "||") and }
+//	and        = comparison { ("and" | "&&") comparison }
+//	comparison = term [ ("==" | "!=" | "<" | "<=" | ">" | ">=") term ]
+//	term       = ( "." [ name ] | "@" | name | function | literal
+//	             | "(" pipeline ")" | "[" pipeline "]" | object ) { step }
+//	step       = "." name | "." object | "[]" | "[*]" | "[" int "]"
+//	             | "[" [ int ] ":" [ int ] "]" | "[" string "]" | "[?" or "]"
+//	function   = ("select" | "map") "(" pipeline ")"
+//	             | ("length" | "keys") [ "(" pipeline ")" ] | "not"
+//	object     = "{" [ key [ ":" or ] { "," key [ ":" or ] } ] "}"
+//	literal    = "json string" | 'raw string' | number | ` + "`" + `json` + "`" + ` | true | false | null
+//
+// For example:
+//
+//	.items[] | select(.price > 10) | .name
+//	items[?price > 10].name
+//	.items[0:2] | map({id, owner: .owner.login})
+//	length(items)
+//	keys(@)
+//
+// Expressions that iterate ([], [*], [?...], select) give a list of results.
+type Query struct {
+	root  queryNode
+	multi bool
+}
+
+type queryNode func(v interface{}) ([]interface{}, error)
+
+type queryParser struct {
+	src   string
+	pos   int
+	depth int // nesting of expressions whose results are collected
+	multi bool
+}
+
+func ParseQuery(expr string) (*Query, error) {
+	p := &queryParser{src: expr}
+	root, err := p.pipeline()
+	if err != nil {
+		return nil, err
 	}
-	fmt.Println(strings.Join(headers, "\t"))
+	if p.skipSpace(); p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	return &Query{root: root, multi: p.multi}, nil
+}
 
-	// Print Rows
-	for i := 0; i < v.Len(); i++ {
-		item := v.Index(i)
-		if item.Kind() == reflect.Ptr {
-			item = item.Elem()
+// Apply runs the query on a decoded JSON document.
+func (q *Query) Apply(doc interface{}) (interface{}, error) {
+	out, err := q.root(doc)
+	if err != nil {
+		return nil, err
+	}
+	if q.multi {
+		if out == nil {
+			out = []interface{}{}
 		}
-		var row []string
-		for j := 0; j < item.NumField(); j++ {
-			row = append(row, fmt.Sprintf("%v", item.Field(j).Interface()))
+		return out, nil
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out[0], nil
+}
+
+func (p *queryParser) pipeline() (queryNode, error) {
+	node, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace(); strings.HasPrefix(p.src[p.pos:], "|") && !strings.HasPrefix(p.src[p.pos:], "||"); p.skipSpace() {
+		p.pos++
+		next, err := p.or()
+		if err != nil {
+			return nil, err
 		}
-		fmt.Println(strings.Join(row, "\t"))
+		node = chain(node, next)
 	}
+	return node, nil
 }
 
-func CSVPrint(target interface{}) {
-	v := reflect.ValueOf(target)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
+func (p *queryParser) or() (queryNode, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeWord("or") || p.consume("||") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(v interface{}) ([]interface{}, error) {
+			a, err := first(l, v)
+			if err != nil || truthy(a) {
+				return []interface{}{true}, err
+			}
+			b, err := first(right, v)
+			return []interface{}{truthy(b)}, err
+		}
 	}
+	return left, nil
+}
 
-	if v.Kind() != reflect.Slice {
-		fmt.Println("CSV output only supported for lists")
-		return
+func (p *queryParser) and() (queryNode, error) {
+	left, err := p.comparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeWord("and") || p.consume("&&") {
+		right, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(v interface{}) ([]interface{}, error) {
+			a, err := first(l, v)
+			if err != nil || !truthy(a) {
+				return []interface{}{false}, err
+			}
+			b, err := first(right, v)
+			return []interface{}{truthy(b)}, err
+		}
 	}
+	return left, nil
+}
 
-	if v.Len() == 0 {
-		return
+func (p *queryParser) comparison() (queryNode, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if !p.consume(op) {
+			continue
+		}
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}) ([]interface{}, error) {
+			a, err := first(left, v)
+			if err != nil {
+				return nil, err
+			}
+			b, err := first(right, v)
+			return []interface{}{compare(op, a, b)}, err
+		}, nil
 	}
+	return left, nil
+}
 
-	t := v.Index(0).Type()
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+func (p *queryParser) term() (queryNode, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	var node queryNode = identity
+	switch c := p.src[p.pos]; {
+	case c == '@':
+		p.pos++
+	case c == '.':
+		p.pos++
+		name, ok, err := p.fieldName()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			node = field(name)
+		}
+	case c == '(':
+		p.pos++
+		inner, err := p.pipeline()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.errorf("missing )")
+		}
+		node = inner
+	case c == '[' && p.collects():
+		p.pos++
+		inner, err := p.nested(p.pipeline)
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume("]") {
+			return nil, p.errorf("missing ]")
+		}
+		node = func(v interface{}) ([]interface{}, error) {
+			out, err := inner(v)
+			if out == nil {
+				out = []interface{}{}
+			}
+			return []interface{}{out}, err
+		}
+	case c == '[':
+		// a path starting with an index, slice or filter
+	case c == '{':
+		var err error
+		if node, err = p.object(); err != nil {
+			return nil, err
+		}
+	case c == '"' || c == '\'' || c == '\x60' || c == '-' || (c >= '0' && c <= '9'):
+		v, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		node = func(interface{}) ([]interface{}, error) { return []interface{}{v}, nil }
+	case isIdentStart(c):
+		var err error
+		if node, err = p.function(p.ident()); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
 	}
+	return p.steps(node)
+}
 
-	// Print Headers
-	var headers []string
-	for i := 0; i < t.NumField(); i++ {
-		headers = append(headers, t.Field(i).Name)
+// function returns the jq builtin or keyword name, or else the field name
+// starting a JMESPath-style path.
+func (p *queryParser) function(name string) (queryNode, error) {
+	switch name {
+	case "true", "false", "null":
+		var v interface{}
+		if name != "null" {
+			v = name == "true"
+		}
+		return func(interface{}) ([]interface{}, error) { return []interface{}{v}, nil }, nil
+	case "not":
+		return func(v interface{}) ([]interface{}, error) { return []interface{}{!truthy(v)}, nil }, nil
+	case "length", "keys":
+		// jq filters, or JMESPath functions of an argument
+		var fn queryNode = length
+		if name == "keys" {
+			fn = keys
+		}
+		if p.peek() != '(' {
+			return fn, nil
+		}
+		p.pos++
+		arg, err := p.nested(p.pipeline)
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.errorf("missing )")
+		}
+		return chain(arg, fn), nil
+	case "select", "map":
+		if !p.consume("(") {
+			return nil, p.errorf("%s needs an argument in parentheses", name)
+		}
+		arg, err := p.nested(p.pipeline)
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.errorf("missing )")
+		}
+		if name == "map" {
+			each := chain(iterate, arg)
+			return func(v interface{}) ([]interface{}, error) {
+				out, err := each(v)
+				if out == nil {
+					out = []interface{}{}
+				}
+				return []interface{}{out}, err
+			}, nil
+		}
+		p.iterates()
+		return func(v interface{}) ([]interface{}, error) {
+			ok, err := first(arg, v)
+			if err != nil || !truthy(ok) {
+				return nil, err
+			}
+			return []interface{}{v}, nil
+		}, nil
+	}
+	return field(name), nil
+}
+
+func length(v interface{}) ([]interface{}, error) {
+	switch x := v.(type) {
+	case nil:
+		return []interface{}{0}, nil
+	case string:
+		return []interface{}{len([]rune(x))}, nil
+	case []interface{}:
+		return []interface{}{len(x)}, nil
+	case map[string]interface{}:
+		return []interface{}{len(x)}, nil
+	}
+	return nil, fmt.Errorf("%s has no length", kind(v))
+}
+
+func keys(v interface{}) ([]interface{}, error) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		keys := []interface{}{}
+		for _, k := range sortedKeys(x) {
+			keys = append(keys, k)
+		}
+		return []interface{}{keys}, nil
+	case []interface{}:
+		keys := []interface{}{}
+		for i := range x {
+			keys = append(keys, i)
+		}
+		return []interface{}{keys}, nil
+	}
+	return nil, fmt.Errorf("%s has no keys", kind(v))
+}
+
+// steps parses the .field and [...] steps following a term.
+func (p *queryParser) steps(node queryNode) (queryNode, error) {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '.':
+			p.pos++
+			if p.peek() == '{' {
+				// a JMESPath multiselect hash
+				obj, err := p.object()
+				if err != nil {
+					return nil, err
+				}
+				node = chain(node, obj)
+				continue
+			}
+			name, ok, err := p.fieldName()
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				node = chain(node, field(name))
+			} else if p.pos >= len(p.src) || p.src[p.pos] != '[' {
+				return nil, p.errorf("expected a field name after .")
+			}
+		case '[':
+			step, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			node = chain(node, step)
+		default:
+			return node, nil
+		}
+	}
+	return node, nil
+}
+
+// bracket parses [], [*], [n], [from:to], ["key"] and [?condition].
+func (p *queryParser) bracket() (queryNode, error) {
+	p.pos++
+	var step queryNode
+	switch {
+	case p.consume("]"):
+		p.iterates()
+		return iterate, nil
+	case p.consume("*"):
+		p.iterates()
+		step = iterate
+	case p.consume("?"):
+		cond, err := p.nested(p.or)
+		if err != nil {
+			return nil, err
+		}
+		p.iterates()
+		step = func(v interface{}) ([]interface{}, error) {
+			items, err := iterate(v)
+			if err != nil {
+				return nil, err
+			}
+			var out []interface{}
+			for _, item := range items {
+				ok, err := first(cond, item)
+				if err != nil {
+					return nil, err
+				}
+				if truthy(ok) {
+					out = append(out, item)
+				}
+			}
+			return out, nil
+		}
+	case p.peek() == '"' || p.peek() == '\'':
+		key, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		step = field(key.(string))
+	default:
+		from, hasFrom, err := p.integer()
+		if err != nil {
+			return nil, err
+		}
+		if p.consume(":") {
+			to, hasTo, err := p.integer()
+			if err != nil {
+				return nil, err
+			}
+			step = slice(from, hasFrom, to, hasTo)
+		} else if hasFrom {
+			step = index(from)
+		} else {
+			return nil, p.errorf("expected an index, slice or ? filter")
+		}
+	}
+	if !p.consume("]") {
+		return nil, p.errorf("missing ]")
+	}
+	return step, nil
+}
+
+// object parses {key, key: expression, "key": expression}.
+func (p *queryParser) object() (queryNode, error) {
+	p.pos++
+	var keys []string
+	var values []queryNode
+	for !p.consume("}") {
+		if len(keys) > 0 && !p.consume(",") {
+			return nil, p.errorf("expected , or }")
+		}
+		p.skipSpace()
+		var key string
+		switch c := p.peek(); {
+		case c == '"' || c == '\'':
+			v, err := p.literal()
+			if err != nil {
+				return nil, err
+			}
+			key = v.(string)
+		case isIdentStart(c):
+			key = p.ident()
+		default:
+			return nil, p.errorf("expected an object key")
+		}
+		value := field(key)
+		if p.consume(":") {
+			var err error
+			if value, err = p.nested(p.or); err != nil {
+				return nil, err
+			}
+		}
+		keys, values = append(keys, key), append(values, value)
+	}
+	return func(v interface{}) ([]interface{}, error) {
+		obj := map[string]interface{}{}
+		for i, key := range keys {
+			x, err := first(values[i], v)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = x
+		}
+		return []interface{}{obj}, nil
+	}, nil
+}
+
+// literal parses a "JSON string", a 'raw string', a number or a JMESPath
+// JSON literal in backquotes.
+func (p *queryParser) literal() (interface{}, error) {
+	start := p.pos
+	switch c := p.src[p.pos]; c {
+	case '"':
+		for p.pos++; p.pos < len(p.src) && p.src[p.pos] != '"'; p.pos++ {
+			if p.src[p.pos] == '\\' {
+				p.pos++
+			}
+		}
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("unterminated string")
+		}
+		p.pos++
+		var s string
+		if err := json.Unmarshal([]byte(p.src[start:p.pos]), &s); err != nil {
+			return nil, p.errorf("bad string %s", p.src[start:p.pos])
+		}
+		return s, nil
+	case '\'', '\x60':
+		var b strings.Builder
+		for p.pos++; p.pos < len(p.src) && p.src[p.pos] != c; p.pos++ {
+			if p.src[p.pos] == '\\' && p.pos+1 < len(p.src) && p.src[p.pos+1] == c {
+				p.pos++
+			}
+			b.WriteByte(p.src[p.pos])
+		}
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("unterminated literal")
+		}
+		p.pos++
+		if c == '\'' {
+			return b.String(), nil
+		}
+		v, err := decodeJSON([]byte(b.String()))
+		if err != nil {
+			// bare words in backquotes are strings
+			return b.String(), nil
+		}
+		return v, nil
+	}
+	for p.pos < len(p.src) && strings.IndexByte("+-.0123456789eE", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	n := p.src[start:p.pos]
+	if _, err := strconv.ParseFloat(n, 64); err != nil {
+		return nil, p.errorf("bad number %s", n)
+	}
+	return json.Number(n), nil
+}
+
+func (p *queryParser) fieldName() (string, bool, error) {
+	switch c := p.peek(); {
+	case isIdentStart(c):
+		return p.ident(), true, nil
+	case c == '"':
+		s, err := p.literal()
+		if err != nil {
+			return "", false, err
+		}
+		return s.(string), true, nil
+	}
+	return "", false, nil
+}
+
+func (p *queryParser) integer() (int, bool, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		return 0, false, nil
+	}
+	n, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		return 0, false, p.errorf("bad index %s", p.src[start:p.pos])
+	}
+	return n, true, nil
+}
+
+func (p *queryParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.src) && (isIdentStart(p.src[p.pos]) || p.src[p.pos] >= '0' && p.src[p.pos] <= '9') {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// collects reports whether the [ at the current position starts a jq array
+// construction such as [.items[].id] rather than an index or filter.
+func (p *queryParser) collects() bool {
+	rest := strings.TrimLeft(p.src[p.pos+1:], " \t\n")
+	return rest != "" && (strings.IndexByte(".({[", rest[0]) >= 0 || isIdentStart(rest[0]))
+}
+
+func (p *queryParser) nested(parse func() (queryNode, error)) (queryNode, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	return parse()
+}
+
+func (p *queryParser) iterates() {
+	if p.depth == 0 {
+		p.multi = true
+	}
+}
+
+func (p *queryParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *queryParser) skipSpace() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\n\r", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *queryParser) consume(s string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) consumeWord(w string) bool {
+	p.skipSpace()
+	end := p.pos + len(w)
+	if !strings.HasPrefix(p.src[p.pos:], w) || end < len(p.src) && isIdentStart(p.src[end]) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("at %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func identity(v interface{}) ([]interface{}, error) {
+	return []interface{}{v}, nil
+}
+
+func chain(a, b queryNode) queryNode {
+	return func(v interface{}) ([]interface{}, error) {
+		in, err := a(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []interface{}
+		for _, x := range in {
+			results, err := b(x)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, results...)
+		}
+		return out, nil
+	}
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func first(node queryNode, v interface{}) (interface{}, error) {
+	out, err := node(v)
+	if err != nil || len(out) == 0 {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+func field(name string) queryNode {
+	return func(v interface{}) ([]interface{}, error) {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			return []interface{}{x[name]}, nil
+		case nil:
+			return []interface{}{nil}, nil
+		}
+		return nil, fmt.Errorf("cannot get %q of %s", name, kind(v))
+	}
+}
+
+func iterate(v interface{}) ([]interface{}, error) {
+	switch x := v.(type) {
+	case []interface{}:
+		return x, nil
+	case map[string]interface{}:
+		var out []interface{}
+		for _, k := range sortedKeys(x) {
+			out = append(out, x[k])
+		}
+		return out, nil
+	case nil:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("cannot iterate over %s", kind(v))
+}
+
+func index(i int) queryNode {
+	return func(v interface{}) ([]interface{}, error) {
+		switch x := v.(type) {
+		case []interface{}:
+			j := i
+			if j < 0 {
+				j += len(x)
+			}
+			if j < 0 || j >= len(x) {
+				return []interface{}{nil}, nil
+			}
+			return []interface{}{x[j]}, nil
+		case nil:
+			return []interface{}{nil}, nil
+		}
+		return nil, fmt.Errorf("cannot index %s", kind(v))
 	}
-	fmt.Println(strings.Join(headers, ","))
+}
 
-	// Print Rows
-	for i := 0; i < v.Len(); i++ {
-		item := v.Index(i)
-		if item.Kind() == reflect.Ptr {
-			item = item.Elem()
+func slice(from int, hasFrom bool, to int, hasTo bool) queryNode {
+	return func(v interface{}) ([]interface{}, error) {
+		x, ok := v.([]interface{})
+		if !ok {
+			if v == nil {
+				return []interface{}{nil}, nil
+			}
+			return nil, fmt.Errorf("cannot slice %s", kind(v))
 		}
-		var row []string
-		for j := 0; j < item.NumField(); j++ {
-			val := fmt.Sprintf("%v", item.Field(j).Interface())
-			if strings.Contains(val, ",") {
-				val = "\"" + val + "\""
+		clamp := func(i int, set bool, dflt int) int {
+			if !set {
+				return dflt
 			}
-			row = append(row, val)
+			if i < 0 {
+				i += len(x)
+			}
+			return max(0, min(i, len(x)))
+		}
+		start, end := clamp(from, hasFrom, 0), clamp(to, hasTo, len(x))
+		if start > end {
+			start = end
+		}
+		return []interface{}{x[start:end]}, nil
+	}
+}
+
+func truthy(v interface{}) bool {
+	return v != nil && v != false
+}
+
+func compare(op string, a, b interface{}) bool {
+	a, b = normalize(a), normalize(b)
+	switch op {
+	case "==":
+		return reflect.DeepEqual(a, b)
+	case "!=":
+		return !reflect.DeepEqual(a, b)
+	}
+	var c int
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if !ok {
+			return false
+		}
+		c = cmp.Compare(x, y)
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return false
+		}
+		c = strings.Compare(x, y)
+	default:
+		return false
+	}
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+// normalize turns the numbers of a document into float64s so that 1 and
+// 1.0 compare equal.
+func normalize(v interface{}) interface{} {
+	switch x := v.(type) {
+	case json.Number:
+		f, _ := x.Float64()
+		return f
+	case int:
+		return float64(x)
+	case []interface{}:
+		out := make([]interface{}, len(x))
+		for i, item := range x {
+			out[i] = normalize(item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(x))
+		for k, item := range x {
+			out[k] = normalize(item)
 		}
-		fmt.Println(strings.Join(row, ","))
+		return out
+	}
+	return v
+}
+
+func kind(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "a string"
+	case json.Number, int, float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case []interface{}:
+		return "an array"
+	}
+	return "an object"
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
+	return keys
 }
 `
 
@@ -346,5 +2687,13 @@ var (
 	EndpointTmpl = template.Must(template.New("endpoint").Parse(endpointTemplate))
 	TagTmpl      = template.Must(template.New("tag").Parse(tagTemplate))
 	RootTmpl     = template.Must(template.New("root").Parse(rootTemplate))
+	ServersTmpl  = template.Must(template.New("servers").Parse(serversTemplate))
+	AuthTmpl     = template.Must(template.New("auth").Parse(authTemplate))
+	LoginTmpl    = template.Must(template.New("login").Parse(loginTemplate))
 	UtilTmpl     = template.Must(template.New("util").Parse(utilTemplate))
+	BodyTmpl     = template.Must(template.New("body").Parse(bodyTemplate))
+	PaginateTmpl = template.Must(template.New("paginate").Parse(paginateTemplate))
+	OutputTmpl   = template.Must(template.New("output").Parse(outputTemplate))
+	ErrorsTmpl   = template.Must(template.New("errors").Parse(errorsTemplate))
+	QueryTmpl    = template.Must(template.New("query").Parse(queryTemplate))
 )
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readAll(t *testing.T, r io.Reader) string {
+	t.Helper()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestJSONBodyFlagsOverrideBody(t *testing.T) {
+	b, err := NewJSONBody(`{"customer":{"id":1,"email":"a@x"},"express":false}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set(2, "customer", "id"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set("rome", "shipping", "city"); err != nil {
+		t.Fatal(err)
+	}
+	r, err := b.Reader(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"customer":{"email":"a@x","id":2},"express":false,"shipping":{"city":"rome"}}`
+	if got := readAll(t, r); got != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+}
+
+func TestJSONBodyRequire(t *testing.T) {
+	b, _ := NewJSONBody(`{"customer":{"id":1}}`)
+	// shipping is optional, so its city is only required when it is set
+	if err := b.Require([]string{"customer", "id"}, []string{"shipping", "city"}); err != nil {
+		t.Errorf("Require() = %v", err)
+	}
+	b.Set("1", "shipping", "zip")
+	if err := b.Require([]string{"shipping", "city"}); err == nil || !strings.Contains(err.Error(), "shipping.city") {
+		t.Errorf("Require() = %v, want missing shipping.city", err)
+	}
+
+	empty, _ := NewJSONBody("")
+	if _, err := empty.Reader(true); !errors.Is(err, ErrBodyRequired) {
+		t.Errorf("Reader(true) = %v, want ErrBodyRequired", err)
+	}
+	if r, err := empty.Reader(false); r != nil || err != nil {
+		t.Errorf("Reader(false) = %v, %v, want no body", r, err)
+	}
+
+	raw, _ := NewJSONBody("not json")
+	if r, err := raw.Reader(true); err != nil || readAll(t, r) != "not json" {
+		t.Errorf("raw --body was not sent as given: %v", err)
+	}
+	if err := raw.Set(1, "qty"); err == nil {
+		t.Error("Set() merged a flag into a body that is not JSON")
+	}
+}
+
+func TestParseObjects(t *testing.T) {
+	fields := Fields{Types: map[string]string{"name": "string", "qty": "integer", "tags": "array:string"}, Required: []string{"name"}}
+	items, err := ParseObjects([]string{"name=a,qty=2,tags=x,y", `{"name":"b"}`}, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := items[0].(map[string]interface{})
+	if first["name"] != "a" || first["qty"] != int64(2) || len(first["tags"].([]interface{})) != 2 {
+		t.Errorf("items[0] = %v", first)
+	}
+	if items[1].(map[string]interface{})["name"] != "b" {
+		t.Errorf("items[1] = %v", items[1])
+	}
+	if _, err := ParseObject("qty=2", fields); err == nil {
+		t.Error("ParseObject() accepted an object without its required name")
+	}
+	if _, err := ParseObject("name=a,qty=two", fields); err == nil {
+		t.Error("ParseObject() accepted a non-integer qty")
+	}
+}
+
+func TestMultipartForm(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewForm("title=old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Set("title", "new")
+	f.SetFiles("file", path)
+	if err := f.Require("file", "title"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := f.Reader(false, true); err == nil {
+		t.Error("a file was accepted in a url-encoded body")
+	}
+	r, contentType, err := f.Reader(true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	form, err := multipart.NewReader(r, params["boundary"]).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := form.Value["title"]; len(got) != 1 || got[0] != "new" {
+		t.Errorf("title = %v, want [new]", got)
+	}
+	files := form.File["file"]
+	if len(files) != 1 || files[0].Filename != "report.txt" || files[0].Header.Get("Content-Type") != "text/plain; charset=utf-8" {
+		t.Fatalf("file = %+v", files)
+	}
+	file, err := files[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if got := readAll(t, file); got != "hello" {
+		t.Errorf("file content = %q", got)
+	}
+
+	empty, _ := NewForm("")
+	if err := empty.Require("file"); err == nil {
+		t.Error("Require() accepted a form without the file")
+	}
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{&NetworkError{Err: errors.New("connection refused")}, ExitNetworkError},
+		{fmt.Errorf("get: %w", &NetworkError{Err: errors.New("timeout")}), ExitNetworkError},
+		{errors.New("bad flag"), ExitError},
+		{&APIError{StatusCode: 401}, ExitAuthError},
+		{&APIError{StatusCode: 403}, ExitAuthError},
+		{&APIError{StatusCode: 404}, ExitNotFound},
+		{&APIError{StatusCode: 400}, ExitClientError},
+		{fmt.Errorf("page 2: %w", &APIError{StatusCode: 429}), ExitClientError},
+		{&APIError{StatusCode: 500}, ExitServerError},
+		{&APIError{StatusCode: 503}, ExitServerError},
+		{&APIError{StatusCode: 302}, ExitError},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+type problem struct {
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
+type message struct {
+	Message string `json:"message"`
+}
+
+func response(status int, contentType, body string) *http.Response {
+	req, _ := http.NewRequest("GET", "http://api.example.com/things", nil)
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     http.Header{"Content-Type": {contentType}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+func TestResponsePrintFailures(t *testing.T) {
+	errs := ErrorSchemas{
+		"404": func() interface{} { return new(problem) },
+		"4XX": func() interface{} { return new(message) },
+	}
+	opts := PrintOptions{Format: "json", FailOn: []string{"4xx", "5xx"}}
+
+	err := ResponsePrint(response(404, "application/problem+json", `{"title":"Gone","detail":"no such thing"}`), nil, errs, opts)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("ResponsePrint() = %v, want an APIError", err)
+	}
+	if p, ok := apiErr.Model.(*problem); !ok || p.Detail != "no such thing" {
+		t.Errorf("Model = %#v, want the 404 problem", apiErr.Model)
+	}
+	if got, want := err.Error(), "404 Not Found: Gone: no such thing"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if ExitCode(err) != ExitNotFound {
+		t.Errorf("ExitCode() = %d, want %d", ExitCode(err), ExitNotFound)
+	}
+
+	err = ResponsePrint(response(429, "application/json", `{"message":"slow down"}`), nil, errs, opts)
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("ResponsePrint() = %v, want an APIError", err)
+	}
+	if m, ok := apiErr.Model.(*message); !ok || m.Message != "slow down" {
+		t.Errorf("Model = %#v, want the 4XX message", apiErr.Model)
+	}
+	if got, want := err.Error(), "429 Too Many Requests: slow down"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if ExitCode(err) != ExitClientError {
+		t.Errorf("ExitCode() = %d, want %d", ExitCode(err), ExitClientError)
+	}
+
+	err = ResponsePrint(response(502, "text/plain", "upstream down"), nil, errs, opts)
+	if got, want := err.Error(), "502 Bad Gateway: upstream down"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if ExitCode(err) != ExitServerError {
+		t.Errorf("ExitCode() = %d, want %d", ExitCode(err), ExitServerError)
+	}
+
+	// statuses --fail-on lets through print like a success
+	for _, failOn := range [][]string{{"5xx"}, {"none"}} {
+		opts := PrintOptions{Format: "raw", FailOn: failOn}
+		if err := ResponsePrint(response(404, "application/json", `{}`), nil, errs, opts); err != nil {
+			t.Errorf("--fail-on %v: ResponsePrint() = %v", failOn, err)
+		}
+	}
+	opts = PrintOptions{Format: "raw", FailOn: []string{"404"}}
+	if err := ResponsePrint(response(404, "application/json", `{}`), nil, errs, opts); ExitCode(err) != ExitNotFound {
+		t.Errorf("--fail-on 404: ResponsePrint() = %v", err)
+	}
+}
+
+func TestValidFailOn(t *testing.T) {
+	for _, s := range []string{"none", "4xx", "5XX", "404", "302"} {
+		if err := validFailOn(s); err != nil {
+			t.Errorf("validFailOn(%q) = %v", s, err)
+		}
+	}
+	for _, s := range []string{"2xx", "200", "9xx", "4x", "abc", ""} {
+		if err := validFailOn(s); err == nil {
+			t.Errorf("validFailOn(%q) accepted it", s)
+		}
+	}
+}
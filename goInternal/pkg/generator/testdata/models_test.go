@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPetUnion(t *testing.T) {
+	var pets []Pet
+	if err := json.Unmarshal([]byte(`[{"id":"1","kind":"dog","barks":true},{"id":"2","kind":"cat","lives":9}]`), &pets); err != nil {
+		t.Fatal(err)
+	}
+	if pets[0].Dog == nil || pets[0].Dog.Id != "1" || !*pets[0].Dog.Barks {
+		t.Errorf("pets[0] = %+v, want a barking dog", pets[0])
+	}
+	if pets[1].Cat == nil || *pets[1].Cat.Lives != 9 {
+		t.Errorf("pets[1] = %+v, want a cat", pets[1])
+	}
+	data, err := json.Marshal(pets[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"id":"2","kind":"cat","lives":9}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	var pet Pet
+	if err := json.Unmarshal([]byte(`{"id":"3","kind":"fish"}`), &pet); err == nil || !strings.Contains(err.Error(), "fish") {
+		t.Errorf("unknown kind error = %v", err)
+	}
+}
+
+func TestAdditionalProperties(t *testing.T) {
+	var o Owner
+	if err := json.Unmarshal([]byte(`{"name":"amy","size":"small","legs":4}`), &o); err != nil {
+		t.Fatal(err)
+	}
+	if *o.Size != SizeSmall || o.AdditionalProperties["legs"] != 4 {
+		t.Errorf("Owner = %+v", o)
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"legs":4,"name":"amy","size":"small"}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+}
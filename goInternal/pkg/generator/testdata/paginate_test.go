@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// paginate runs PaginatedPrint against handler and returns the printed
+// items and the request URIs the server saw.
+func paginate(t *testing.T, p Pagination, maxItems int, path string, handler func(w http.ResponseWriter, r *http.Request)) (string, []string) {
+	t.Helper()
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.URL.RequestURI())
+		if len(seen) > 10 {
+			http.Error(w, "pagination did not stop", http.StatusTeapot)
+			return
+		}
+		handler(w, r)
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest("GET", srv.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = out
+	err = PaginatedPrint(srv.Client(), req, p, maxItems, nil, nil, PrintOptions{Format: "raw"})
+	os.Stdout = stdout
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Seek(0, io.SeekStart)
+	printed, err := io.ReadAll(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(printed), seen
+}
+
+func assertPages(t *testing.T, printed string, seen []string, wantPrinted string, wantSeen ...string) {
+	t.Helper()
+	if printed != wantPrinted {
+		t.Errorf("printed %s, want %s", printed, wantPrinted)
+	}
+	if fmt.Sprint(seen) != fmt.Sprint(wantSeen) {
+		t.Errorf("requested %v, want %v", seen, wantSeen)
+	}
+}
+
+var cursor = Pagination{Style: "cursor", Param: "cursor", NextPath: []string{"meta", "next"}, ItemsPath: []string{"data"}}
+
+func TestPaginateCursorRunsOut(t *testing.T) {
+	printed, seen := paginate(t, cursor, 0, "/items", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			fmt.Fprint(w, `{"data":[1,2],"meta":{"next":"b"}}`)
+		case "b":
+			fmt.Fprint(w, `{"data":[3],"meta":{"next":""}}`)
+		}
+	})
+	assertPages(t, printed, seen, "[1,2,3]", "/items", "/items?cursor=b")
+}
+
+func TestPaginateStopsOnEmptyPage(t *testing.T) {
+	printed, seen := paginate(t, cursor, 0, "/items", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "" {
+			fmt.Fprint(w, `{"data":[1],"meta":{"next":"b"}}`)
+			return
+		}
+		// a next cursor on an empty page is not followed
+		fmt.Fprint(w, `{"data":[],"meta":{"next":"c"}}`)
+	})
+	assertPages(t, printed, seen, "[1]", "/items", "/items?cursor=b")
+}
+
+func TestPaginateStopsOnRepeatedCursor(t *testing.T) {
+	printed, seen := paginate(t, cursor, 0, "/items", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[1],"meta":{"next":"same"}}`)
+	})
+	assertPages(t, printed, seen, "[1,1]", "/items", "/items?cursor=same")
+}
+
+func TestPaginateOffsetStopsOnShortPage(t *testing.T) {
+	offset := Pagination{Style: "offset", Param: "offset", SizeParam: "limit"}
+	printed, seen := paginate(t, offset, 0, "/items?limit=2", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("offset") {
+		case "":
+			fmt.Fprint(w, `[1,2]`)
+		case "2":
+			fmt.Fprint(w, `[3]`)
+		}
+	})
+	assertPages(t, printed, seen, "[1,2,3]", "/items?limit=2", "/items?limit=2&offset=2")
+}
+
+func TestPaginatePageNumbers(t *testing.T) {
+	page := Pagination{Style: "page", Param: "page", Start: 1, ItemsPath: []string{"results"}}
+	printed, seen := paginate(t, page, 0, "/items", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			fmt.Fprint(w, `{"results":[1]}`)
+		case "2":
+			fmt.Fprint(w, `{"results":[2]}`)
+		default:
+			fmt.Fprint(w, `{"results":[]}`)
+		}
+	})
+	assertPages(t, printed, seen, "[1,2]", "/items", "/items?page=2", "/items?page=3")
+}
+
+func TestPaginateLinkHeader(t *testing.T) {
+	link := Pagination{Style: "link"}
+	printed, seen := paginate(t, link, 0, "/items", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/items" {
+			w.Header().Set("Link", `</items/2>; rel="next", </items>; rel="first"`)
+			fmt.Fprint(w, `[1]`)
+			return
+		}
+		w.Header().Set("Link", `</items>; rel="first"`)
+		fmt.Fprint(w, `[2]`)
+	})
+	assertPages(t, printed, seen, "[1,2]", "/items", "/items/2")
+}
+
+func TestPaginateMaxItems(t *testing.T) {
+	printed, seen := paginate(t, cursor, 3, "/items", func(w http.ResponseWriter, r *http.Request) {
+		next := r.URL.Query().Get("cursor") + "x"
+		fmt.Fprintf(w, `{"data":[1,2],"meta":{"next":%q}}`, next)
+	})
+	assertPages(t, printed, seen, "[1,2,1]", "/items", "/items?cursor=x")
+}
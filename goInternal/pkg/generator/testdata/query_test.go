@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const queryDoc = `{
+	"total": 3,
+	"next": null,
+	"items": [
+		{"id": 1, "name": "ant", "price": 5, "tags": ["a"], "owner": {"login": "amy"}},
+		{"id": 2, "name": "bee", "price": 12, "tags": ["a", "b"], "owner": {"login": "bob"}},
+		{"id": 3, "name": "cat", "price": 20, "tags": [], "active": true}
+	]
+}`
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{".", `{"items":[{"id":1,"name":"ant","owner":{"login":"amy"},"price":5,"tags":["a"]},{"id":2,"name":"bee","owner":{"login":"bob"},"price":12,"tags":["a","b"]},{"active":true,"id":3,"name":"cat","price":20,"tags":[]}],"next":null,"total":3}`},
+		{"@.total", `3`},
+		{".total", `3`},
+		{"total", `3`},
+		{".missing", `null`},
+		{".next.page", `null`},
+		{`.["total"]`, `3`},
+
+		// jq
+		{".items[].name", `["ant","bee","cat"]`},
+		{".items[] | .id", `[1,2,3]`},
+		{".items[] | select(.price > 10) | .name", `["bee","cat"]`},
+		{".items[] | select(.active) | .name", `["cat"]`},
+		{".items[] | select(.active | not) | .id", `[1,2]`},
+		{".items[] | select(.price >= 12 and .name != \"cat\") | .id", `[2]`},
+		{".items[] | select(.id == 1 or .id == 3) | .id", `[1,3]`},
+		{"[.items[].id]", `[1,2,3]`},
+		{".items | map(.owner.login)", `["amy","bob",null]`},
+		{".items[0:2] | map({id, owner: .owner.login})", `[{"id":1,"owner":"amy"},{"id":2,"owner":"bob"}]`},
+		{".items | length", `3`},
+		{".items[0] | keys", `["id","name","owner","price","tags"]`},
+		{".items[-1].name", `"cat"`},
+		{".items[5]", `null`},
+		{".items[1:][].id", `[2,3]`},
+		{".items[0].name | length", `3`},
+
+		// JMESPath
+		{"items[*].name", `["ant","bee","cat"]`},
+		{"items[?price > `10`].name", `["bee","cat"]`},
+		{"items[?name == 'bee'].id", `[2]`},
+		{"items[?length(tags) > `1`].id", `[2]`},
+		{"items[?owner.login == `amy`].price", `[5]`},
+		{"length(items)", `3`},
+		{"keys(@)", `["items","next","total"]`},
+		{"keys(items[0].owner)", `["login"]`},
+		{"items[0].{n: name, p: price}", `{"n":"ant","p":5}`},
+		{"items[?price < `0`]", `[]`},
+		{"items[].{id: id, login: owner.login}", `[{"id":1,"login":"amy"},{"id":2,"login":"bob"},{"id":3,"login":null}]`},
+	}
+	doc, err := decodeJSON([]byte(queryDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			q, err := ParseQuery(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+			out, err := q.Apply(doc)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			got, err := json.Marshal(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string // from ParseQuery, or else from Apply
+	}{
+		{"", "unexpected end of expression"},
+		{".items[", "expected an index"},
+		{".items[0", "missing ]"},
+		{"(.total", "missing )"},
+		{"select .x", "needs an argument"},
+		{".items | map(.id", "missing )"},
+		{`.name == "ant`, "unterminated string"},
+		{"{id", "expected , or }"},
+		{".total )", "unexpected"},
+		{".total.x", `cannot get "x" of a number`},
+		{".total[]", "cannot iterate over a number"},
+		{"length(total[0])", "cannot index a number"},
+		{"keys(total)", "a number has no keys"},
+	}
+	doc, err := decodeJSON([]byte(queryDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			q, err := ParseQuery(tt.expr)
+			if err == nil {
+				_, err = q.Apply(doc)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
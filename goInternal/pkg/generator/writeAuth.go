@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"CommandPost/goInternal/pkg/oauth"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writeAuth writes the login support for the spec's OAuth2 schemes: a copy
+// of the oauth package, the auth package that caches and refreshes tokens
+// and the login and logout commands. It reports whether anything was
+// written.
+func writeAuth(outputDir, moduleName string, schemes map[string]AuthScheme) (bool, error) {
+	var names []string
+	for _, name := range sortedKeys(schemes) {
+		if canLogin(schemes[name]) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return false, nil
+	}
+
+	if err := copyOAuthSources(filepath.Join(outputDir, "oauth")); err != nil {
+		return false, err
+	}
+
+	var literal strings.Builder
+	var help strings.Builder
+	literal.WriteString("[]Scheme{\n")
+	help.WriteString("Each scheme reads its client from the config file (or the matching\nenvironment variable):\n")
+	for _, name := range names {
+		s := schemes[name]
+		prefix := authKey(s, "")
+		literal.WriteString(fmt.Sprintf("\t{Name: %q, Issuer: %q, KeyPrefix: %q, Flows: []Flow{\n", s.Name, s.Issuer, prefix))
+		grants := make([]string, len(s.Flows))
+		for i, f := range s.Flows {
+			grants[i] = f.Grant
+			scopes := make([]string, len(f.Scopes))
+			for j, scope := range f.Scopes {
+				scopes[j] = fmt.Sprintf("%q", scope)
+			}
+			literal.WriteString(fmt.Sprintf("\t\t{Grant: %q, AuthURL: %q, TokenURL: %q, RefreshURL: %q, DeviceAuthURL: %q, Scopes: []string{%s}},\n",
+				f.Grant, f.AuthURL, f.TokenURL, f.RefreshURL, f.DeviceAuthURL, strings.Join(scopes, ", ")))
+		}
+		literal.WriteString("\t}},\n")
+		help.WriteString(fmt.Sprintf("\n  %s (%s)\n    %sclient_id, %sclient_secret, %sscope, %sredirect_uri\n",
+			s.Name, strings.Join(grants, ", "), prefix, prefix, prefix, prefix))
+	}
+	literal.WriteString("}")
+
+	data := struct {
+		ModuleName  string
+		Schemes     string
+		SchemeNames []string
+		LoginHelp   string
+	}{
+		ModuleName:  moduleName,
+		Schemes:     literal.String(),
+		SchemeNames: names,
+		LoginHelp:   strings.ReplaceAll(help.String(), "`", ""),
+	}
+
+	if err := os.MkdirAll(filepath.Join(outputDir, "auth"), 0755); err != nil {
+		return false, err
+	}
+	var buf bytes.Buffer
+	if err := AuthTmpl.Execute(&buf, data); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "auth", "auth.go"), buf.Bytes(), 0644); err != nil {
+		return false, err
+	}
+
+	buf.Reset()
+	if err := LoginTmpl.Execute(&buf, data); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(filepath.Join(outputDir, "cmd", "login.go"), buf.Bytes(), 0644)
+}
+
+func copyOAuthSources(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return fs.WalkDir(oauth.Sources, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		src, err := oauth.Sources.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		code := append([]byte("// Code generated from CommandPost's oauth package. DO NOT EDIT.\n\n"), src...)
+		return os.WriteFile(filepath.Join(dir, path), code, 0644)
+	})
+}
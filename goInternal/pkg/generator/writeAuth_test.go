@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteAuth(t *testing.T) {
+	out := generateTestdata(t, "oauth.yaml", Config{})
+	assertContains(t, out, "auth/auth.go",
+		`{Name: "petstore_auth", Issuer: "", KeyPrefix: "petstore_auth_", Flows: []Flow{`,
+		`{Grant: "authorization_code", AuthURL: "http://127.0.0.1:18095/authorize", TokenURL: "http://127.0.0.1:18095/token", RefreshURL: "", DeviceAuthURL: "", Scopes: []string{"read", "write"}},`,
+		`{Grant: "device_code", AuthURL: "", TokenURL: "http://127.0.0.1:18095/token", RefreshURL: "", DeviceAuthURL: "http://127.0.0.1:18095/device", Scopes: []string{"read"}},`,
+		`{Grant: "client_credentials", AuthURL: "", TokenURL: "http://127.0.0.1:18095/token", RefreshURL: "", DeviceAuthURL: "", Scopes: []string{"read"}},`,
+		// cached tokens are scoped to the scheme, server and client
+		`return name + " " + server + " " + clientID`,
+	)
+	assertContains(t, out, "main.go", "rootCmd.AddCommand(cmd.NewLoginCmd(), cmd.NewLogoutCmd())")
+	assertContains(t, out, "cmd/login.go", `Use:   "login [scheme]"`)
+	assertContains(t, out, "oauth/pkce.go", "// Code generated from CommandPost's oauth package. DO NOT EDIT.")
+	assertContains(t, out, "config/config.go",
+		`v.SetDefault("petstore_auth_client_id", "")`,
+		`v.SetDefault("petstore_auth_redirect_uri", "")`,
+		`v.SetDefault("basic_username", "")`,
+		`v.SetDefault("basic_password", "")`,
+	)
+	assertContains(t, out, "cmd/getbasic.go", "req.SetBasicAuth(cfg.BasicUsername, cfg.BasicPassword)")
+	assertContains(t, out, "cmd/getme.go", `auth.AccessToken(req.Context(), "petstore_auth", tokenServer, cfg.OAuthClients["petstore_auth"], []string{"read"})`)
+}
+
+func TestWriteAuthWithoutOAuth2(t *testing.T) {
+	out := generateTestdata(t, "swagger2.yaml", Config{})
+	for _, rel := range []string{"auth", "oauth", "cmd/login.go"} {
+		if _, err := os.Stat(filepath.Join(out, rel)); !os.IsNotExist(err) {
+			t.Errorf("%s was written for a spec without OAuth2 schemes", rel)
+		}
+	}
+	if code := emitted(t, out, "main.go"); strings.Contains(code, "NewLoginCmd") {
+		t.Error("main.go adds the login command")
+	}
+}
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"go/format"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,57 +10,56 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
-func writeConfig(outputDir string, schemes map[string]AuthScheme, servers openapi3.Servers, specPath string) error {
+// writeConfig writes the config package. Relative server URLs were resolved
+// against the spec's location when it was loaded; any still relative have
+// to be set as base_url by the user.
+func writeConfig(outputDir string, schemes map[string]AuthScheme, servers openapi3.Servers, overrides map[string]openapi3.Servers) error {
 	var structFields strings.Builder
 	var viperDefaults strings.Builder
 	var structAssigns strings.Builder
 
-	for _, s := range schemes {
-		fieldName := s.Name
-		if len(fieldName) > 0 {
-			fieldName = strings.ToUpper(fieldName[:1]) + fieldName[1:]
-		}
-		fieldName += "Auth"
-
-		envName := strings.ReplaceAll(strings.ToLower(s.Name), "-", "_") + "_auth"
-
-		structFields.WriteString(fmt.Sprintf("\t%s string\n", fieldName))
-		viperDefaults.WriteString(fmt.Sprintf("\tv.SetDefault(\"%s\", \"\")\n", envName))
-		structAssigns.WriteString(fmt.Sprintf("\t\t%s: v.GetString(\"%s\"),\n", fieldName, envName))
+	var oauthClients strings.Builder
+	field := func(name, key string) {
+		structFields.WriteString(fmt.Sprintf("\t%s string\n", name))
+		viperDefaults.WriteString(fmt.Sprintf("\tv.SetDefault(%q, \"\")\n", key))
+		structAssigns.WriteString(fmt.Sprintf("\t\t%s: v.GetString(%q),\n", name, key))
 	}
-
-	serverMapCode := ""
-	defaultBaseURL := specPath[:strings.LastIndex(specPath, "/openapi.json")]
-	if len(servers) > 0 {
-		if !strings.HasPrefix(servers[0].URL, "http") {
-			defaultBaseURL = specPath[:strings.LastIndex(specPath, "/openapi.json")]
-		} else {
-			defaultBaseURL = servers[0].URL
-		}
-		serverMapEntries := []string{}
-		for _, s := range servers {
-			name := strings.ToLower(s.Description)
-			if name == "" {
-				name = s.URL
-			}
-			name = strings.ReplaceAll(name, " ", "-")
-			var url string
-			if !strings.HasPrefix(s.URL, "http") {
-				url = specPath[:strings.LastIndex(specPath, "/openapi.json")]
-			} else {
-				url = s.URL
+	for _, name := range sortedKeys(schemes) {
+		s := schemes[name]
+		switch {
+		case s.Type == "http" && s.Scheme == "basic":
+			field(authField(s, "Username"), authKey(s, "username"))
+			field(authField(s, "Password"), authKey(s, "password"))
+		case canLogin(s):
+			// A token set here is used as-is instead of the one from login.
+			field(authField(s, "Auth"), authKey(s, "auth"))
+			oauthClients.WriteString(fmt.Sprintf("\t\t\t%q: {\n", s.Name))
+			for _, f := range []struct{ name, key string }{
+				{"ID", "client_id"}, {"Secret", "client_secret"}, {"Scope", "scope"},
+				{"RedirectURI", "redirect_uri"}, {"Username", "username"}, {"Password", "password"},
+			} {
+				viperDefaults.WriteString(fmt.Sprintf("\tv.SetDefault(%q, \"\")\n", authKey(s, f.key)))
+				oauthClients.WriteString(fmt.Sprintf("\t\t\t\t%s: v.GetString(%q),\n", f.name, authKey(s, f.key)))
 			}
-			serverMapEntries = append(serverMapEntries, fmt.Sprintf("\"%s\": \"%s\"", name, url))
+			oauthClients.WriteString("\t\t\t},\n")
+		default:
+			field(authField(s, "Auth"), authKey(s, "auth"))
 		}
-		serverMapCode = fmt.Sprintf("\tserverMap := map[string]string{%s}\n", strings.Join(serverMapEntries, ", "))
+	}
+
+	var commandServerEntries strings.Builder
+	for _, key := range sortedKeys(overrides) {
+		commandServerEntries.WriteString(fmt.Sprintf("\t%q: %s,\n", key, serversLiteral(overrides[key])))
 	}
 
 	configCode := fmt.Sprintf(`
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -69,17 +69,51 @@ import (
 
 type Config struct {
 	BaseURL string
+	Env string
+	ServerVars map[string]string
 	Output string
 	Timeout time.Duration
+	OAuthClients map[string]OAuthClient
 %s
 }
 
+// OAuthClient is the OAuth2 client registration the login command and
+// token refresh use for a security scheme.
+type OAuthClient struct {
+	ID string
+	Secret string
+	Scope string
+	RedirectURI string
+	Username string
+	Password string
+}
+
+type ServerVariable struct {
+	Name string
+	Default string
+	Enum []string
+	Description string
+}
+
+type Server struct {
+	Name string
+	URL string
+	Description string
+	Variables []ServerVariable
+}
+
+// Servers are the API's servers; the first one is the default.
+var Servers = %s
+
+// CommandServers are the servers of commands whose operation or path
+// overrides the API's servers, keyed by "<tag> <command>".
+var CommandServers = map[string][]Server{
+%s}
+
 func Load(appName string, env string) *Config {
 	_ = godotenv.Load()
 	v := viper.New()
 
-	%s
-	v.SetDefault("base_url", "%s")
 	v.SetDefault("output", "json")
 	v.SetDefault("timeout", "30s")
 %s
@@ -98,27 +132,125 @@ func Load(appName string, env string) *Config {
 
 	_ = v.ReadInConfig()
 
-	baseUrl := v.GetString("base_url")
-	if env != "" {
-		if url, ok := serverMap[strings.ToLower(env)]; ok {
-			baseUrl = url
-		}
-	}
-
 	timeout, _ := time.ParseDuration(v.GetString("timeout"))
 	if timeout == 0 {
 		timeout = 30 * time.Second
 	}
 
 	return &Config{
-		BaseURL: baseUrl,
+		BaseURL: v.GetString("base_url"),
+		Env: env,
+		ServerVars: v.GetStringMapString("server_vars"),
 		Output: v.GetString("output"),
 		Timeout: timeout,
+		OAuthClients: map[string]OAuthClient{
+%s		},
 %s
 	}
 }
-`, structFields.String(), serverMapCode, defaultBaseURL, viperDefaults.String(), structAssigns.String())
+
+// ServerURL returns the base URL for a command with the given servers. A
+// configured base_url is used unless --env picks a server; otherwise the
+// server named by --env (or the first one) is expanded with the variables
+// from --server-var, the server_vars config and the spec's defaults.
+func (c *Config) ServerURL(servers []Server, vars []string) (string, error) {
+	if c.BaseURL != "" && c.Env == "" {
+		return c.BaseURL, nil
+	}
+	server, err := SelectServer(servers, c.Env)
+	if err != nil {
+		return "", err
+	}
+	values := make(map[string]string)
+	for name, value := range c.ServerVars {
+		values[name] = value
+	}
+	for _, kv := range vars {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return "", fmt.Errorf("invalid --server-var %%q, expected name=value", kv)
+		}
+		if server.Variable(name) == nil {
+			return "", fmt.Errorf("server %%s has no variable %%q%%s", server.Name, name, server.variableList())
+		}
+		values[name] = value
+	}
+	return server.Expand(values)
+}
+
+// SelectServer finds a server by name or by its number in the list.
+func SelectServer(servers []Server, name string) (Server, error) {
+	if len(servers) == 0 {
+		return Server{}, fmt.Errorf("the API defines no servers; set base_url in the config file")
+	}
+	if name == "" {
+		return servers[0], nil
+	}
+	names := make([]string, len(servers))
+	for i, s := range servers {
+		if strings.EqualFold(s.Name, name) || strconv.Itoa(i+1) == name {
+			return s, nil
+		}
+		names[i] = s.Name
+	}
+	return Server{}, fmt.Errorf("unknown server %%q (available: %%s)", name, strings.Join(names, ", "))
+}
+
+func (s Server) Variable(name string) *ServerVariable {
+	for i := range s.Variables {
+		if s.Variables[i].Name == name {
+			return &s.Variables[i]
+		}
+	}
+	return nil
+}
+
+func (s Server) variableList() string {
+	if len(s.Variables) == 0 {
+		return ""
+	}
+	names := make([]string, len(s.Variables))
+	for i, v := range s.Variables {
+		names[i] = v.Name
+	}
+	return " (variables: " + strings.Join(names, ", ") + ")"
+}
+
+// Expand substitutes the server's variables, checking values against the
+// variable's enum.
+func (s Server) Expand(values map[string]string) (string, error) {
+	url := s.URL
+	for _, v := range s.Variables {
+		value := values[v.Name]
+		if value == "" {
+			value = v.Default
+		}
+		if len(v.Enum) > 0 && !contains(v.Enum, value) {
+			return "", fmt.Errorf("server variable %%s must be one of %%s, got %%q", v.Name, strings.Join(v.Enum, ", "), value)
+		}
+		if value == "" {
+			return "", fmt.Errorf("server variable %%s has no default; set it with --server-var %%s=<value>", v.Name, v.Name)
+		}
+		url = strings.ReplaceAll(url, "{"+v.Name+"}", value)
+	}
+	return url, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+`, structFields.String(), serversLiteral(servers), commandServerEntries.String(), viperDefaults.String(), oauthClients.String(), structAssigns.String())
+
+	code, err := format.Source([]byte(configCode))
+	if err != nil {
+		return fmt.Errorf("generated config is not valid Go: %w", err)
+	}
 
 	path := filepath.Join(outputDir, "config", "config.go")
-	return os.WriteFile(path, []byte(configCode), 0644)
+	return os.WriteFile(path, code, 0644)
 }
@@ -10,8 +10,8 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
-func writeEndpointCmd(outputDir string, moduleName string, goName string, cliName string, op *openapi3.Operation, path, method string,
-	schemes map[string]AuthScheme, globalSecurity *openapi3.SecurityRequirements) error {
+func writeEndpointCmd(outputDir string, moduleName string, info CommandInfo, op *openapi3.Operation, params openapi3.Parameters, path, method string,
+	servers string, schemes map[string]AuthScheme, globalSecurity *openapi3.SecurityRequirements) error {
 	// Build parameter-driven code pieces from the operation parameters
 	var imports = map[string]bool{
 		"github.com/spf13/cobra":             true,
@@ -27,12 +27,15 @@ func writeEndpointCmd(outputDir string, moduleName string, goName string, cliNam
 	if respModel != "" {
 		imports[fmt.Sprintf("%s/models", moduleName)] = true
 	}
+	errorSchemas := detectErrorModels(op)
+	if errorSchemas != "" {
+		imports[fmt.Sprintf("%s/models", moduleName)] = true
+	}
 
 	var varDecls strings.Builder
 	var flagsSetup strings.Builder
 	var pathReplacements strings.Builder
 	var queryBuild strings.Builder
-	var schemaBodyBuild strings.Builder
 	var validationBuild strings.Builder
 
 	var headerBuild strings.Builder
@@ -44,29 +47,47 @@ func writeEndpointCmd(outputDir string, moduleName string, goName string, cliNam
 		security = op.Security
 	}
 
-	// Find the first matching scheme
-
-	authCode := findScheme(security, schemes, &cookieBuild, &queryBuild)
+	authCode := securityCode(security, schemes, moduleName, imports)
 
-	generateBodyFlagsFromSchema(op, &flagsSetup, &varDecls, &schemaBodyBuild, &validationBuild, imports)
+	bodyHandling, headerHandling := buildRequestBody(method, op, imports, &varDecls, &flagsSetup, &validationBuild)
 
-	bodyHandling, headerHandling, _ := buildBodyHeaderHandling(method, op, imports, &varDecls, &flagsSetup, &schemaBodyBuild, &validationBuild)
+	buildPathParams(params, &varDecls, &flagsSetup, &pathReplacements, &queryBuild, &headerBuild, &cookieBuild, &validationBuild, imports)
 
-	buildPathParams(*op, &varDecls, &flagsSetup, &pathReplacements, &queryBuild, &headerBuild, &cookieBuild, &validationBuild, imports)
+	pg, err := detectPagination(op, method, params)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+	}
+	var paginationLiteral, itemModel string
+	if pg != nil {
+		paginationLiteral, itemModel = pg.literal(), pg.ItemModel
+		if itemModel != "" {
+			imports[fmt.Sprintf("%s/models", moduleName)] = true
+		}
+		varDecls.WriteString("\tvar paginateAll bool\n\tvar paginateMaxItems int\n")
+		flagsSetup.WriteString("\tcmd.Flags().BoolVar(&paginateAll, \"all\", false, \"Fetch every page and print the combined results\")\n")
+		flagsSetup.WriteString("\tcmd.Flags().IntVar(&paginateMaxItems, \"max-items\", 0, \"Fetch pages until this many items were read\")\n")
+		if pg.SizeParam != "" {
+			imports["strconv"] = true
+			varDecls.WriteString("\tvar paginatePageSize int\n")
+			flagsSetup.WriteString(fmt.Sprintf("\tcmd.Flags().IntVar(&paginatePageSize, \"page-size\", 0, \"Items per page (sets %s)\")\n", pg.SizeParam))
+			queryBuild.WriteString(fmt.Sprintf("\tif paginatePageSize > 0 { q.Set(%q, strconv.Itoa(paginatePageSize)) }\n", pg.SizeParam))
+		}
+	}
 
 	var importList []string
 	for imp := range imports {
 		importList = append(importList, imp)
 	}
 
-	writePkgUtil(outputDir)
-
-	err := buildCmdCode(CmdConfig{
+	err = buildCmdCode(CmdConfig{
 		Method:           strings.ToUpper(method),
-		GoName:           goName,
-		CommandName:      cliName,
+		GoName:           info.GoName,
+		CommandName:      info.CLIName,
+		Aliases:          info.Aliases,
+		Hidden:           info.Hidden,
 		ModuleName:       moduleName,
 		Path:             path,
+		Servers:          servers,
 		OutputDir:        outputDir,
 		Short:            op.Summary,
 		VarDecls:         varDecls.String(),
@@ -82,6 +103,9 @@ func writeEndpointCmd(outputDir string, moduleName string, goName string, cliNam
 		Validation:       validationBuild.String(),
 		ResponseModel:    respModel,
 		IsArray:          isArray,
+		ErrorSchemas:     errorSchemas,
+		Pagination:       paginationLiteral,
+		ItemModel:        itemModel,
 	})
 	return err
 }
@@ -102,27 +126,67 @@ func detectResponseModel(op *openapi3.Operation) (string, bool) {
 			continue
 		}
 		schemaRef := content.Schema
-		if schemaRef.Ref != "" {
-			parts := strings.Split(schemaRef.Ref, "/")
-			return toGoName(parts[len(parts)-1]), false
+		if name, ok := modelTypeName(schemaRef.Ref); ok {
+			return name, false
 		}
 		// If it's an array of references
-		if schemaRef.Value != nil && schemaRef.Value.Type != nil && len(*schemaRef.Value.Type) > 0 && (*schemaRef.Value.Type)[0] == "array" {
-			if schemaRef.Value.Items != nil && schemaRef.Value.Items.Ref != "" {
-				parts := strings.Split(schemaRef.Value.Items.Ref, "/")
-				return toGoName(parts[len(parts)-1]), true
+		if schemaRef.Value != nil && schemaType(schemaRef.Value) == "array" {
+			if schemaRef.Value.Items != nil {
+				if name, ok := modelTypeName(schemaRef.Value.Items.Ref); ok {
+					return name, true
+				}
 			}
 		}
 	}
 	return "", false
 }
 
+// detectErrorModels returns the utils.ErrorSchemas literal making the models
+// of op's 4xx, 5xx and default responses, or "" when none has one. Problem
+// details (application/problem+json) count as JSON.
+func detectErrorModels(op *openapi3.Operation) string {
+	if op.Responses == nil {
+		return ""
+	}
+	var entries []string
+	responses := op.Responses.Map()
+	for _, code := range sortedKeys(responses) {
+		key := strings.ToUpper(code)
+		if key == "DEFAULT" {
+			key = "default"
+		} else if !strings.HasPrefix(key, "4") && !strings.HasPrefix(key, "5") {
+			continue
+		}
+		resp := responses[code]
+		if resp == nil || resp.Value == nil {
+			continue
+		}
+		for _, mediaType := range sortedKeys(resp.Value.Content) {
+			content := resp.Value.Content[mediaType]
+			if !strings.Contains(mediaType, "json") || content.Schema == nil {
+				continue
+			}
+			if name, ok := modelTypeName(content.Schema.Ref); ok {
+				entries = append(entries, fmt.Sprintf("%q: func() interface{} { return new(models.%s) }", key, name))
+			}
+			break
+		}
+	}
+	if entries == nil {
+		return ""
+	}
+	return "utils.ErrorSchemas{" + strings.Join(entries, ", ") + "}"
+}
+
 type CmdConfig struct {
 	Method           string
 	GoName           string
 	CommandName      string
+	Aliases          []string
+	Hidden           bool
 	ModuleName       string
 	Path             string
+	Servers          string
 	OutputDir        string
 	Short            string
 	VarDecls         string
@@ -138,6 +202,9 @@ type CmdConfig struct {
 	Validation       string
 	ResponseModel    string
 	IsArray          bool
+	ErrorSchemas     string
+	Pagination       string
+	ItemModel        string
 }
 
 func buildCmdCode(cfg CmdConfig) error {
@@ -150,9 +217,9 @@ func buildCmdCode(cfg CmdConfig) error {
 	return os.WriteFile(pathFile, buf.Bytes(), 0644)
 }
 
-func buildPathParams(op openapi3.Operation, varDecls *strings.Builder, flagsSetup *strings.Builder, pathReplacements *strings.Builder, queryBuild *strings.Builder,
+func buildPathParams(params openapi3.Parameters, varDecls *strings.Builder, flagsSetup *strings.Builder, pathReplacements *strings.Builder, queryBuild *strings.Builder,
 	headerBuild *strings.Builder, cookieBuild *strings.Builder, validationBuild *strings.Builder, imports map[string]bool) {
-	for _, pRef := range op.Parameters {
+	for _, pRef := range params {
 		if pRef == nil || pRef.Value == nil {
 			continue
 		}
@@ -210,7 +277,7 @@ func buildPathParams(op openapi3.Operation, varDecls *strings.Builder, flagsSetu
 			flagsSetup.WriteString(fmt.Sprintf("\tcmd.MarkFlagRequired(\"%s\")\n", name))
 		}
 		if p.Schema != nil && p.Schema.Value != nil {
-			generateEnumCheck(validationBuild, varName, p.Schema.Value.Enum, imports)
+			generateEnumCheck(validationBuild, varName, name, goType, p.Schema.Value.Enum, imports)
 		}
 		switch in {
 		case "path":
@@ -273,60 +340,3 @@ func buildPathParams(op openapi3.Operation, varDecls *strings.Builder, flagsSetu
 		}
 	}
 }
-
-func findScheme(security *openapi3.SecurityRequirements, schemes map[string]AuthScheme, cookieBuild *strings.Builder,
-	queryBuild *strings.Builder) string {
-	var selectedScheme *AuthScheme
-	var authCode string
-	if security != nil {
-		found := false
-		for _, req := range *security {
-			for name := range req {
-				if s, ok := schemes[name]; ok {
-					selectedScheme = &s
-					found = true
-					break
-				}
-			}
-			if found {
-				break
-			}
-		}
-	}
-
-	if selectedScheme != nil {
-		// e.g. "PetstoreAuth"
-		fieldName := selectedScheme.Name
-		if len(fieldName) > 0 {
-			fieldName = strings.ToUpper(fieldName[:1]) + fieldName[1:]
-		}
-		fieldName += "Auth"
-
-		if selectedScheme.Type == "http" && selectedScheme.Scheme == "bearer" {
-			authCode = fmt.Sprintf(`
-		if cfg.%s != "" {
-			req.Header.Set("Authorization", "Bearer "+cfg.%s)
-		}
-		`, fieldName, fieldName)
-		} else if selectedScheme.Type == "apiKey" {
-			switch selectedScheme.In {
-			case "header":
-				authCode = fmt.Sprintf(`
-		if cfg.%s != "" {
-			req.Header.Set("%s", cfg.%s)}`, fieldName, selectedScheme.HeaderName, fieldName)
-			case "query":
-				queryBuild.WriteString(fmt.Sprintf(`
-		if cfg.%s != "" {
-			q.Set("%s", cfg.%s)
-		}`, fieldName, selectedScheme.HeaderName, fieldName))
-			case "cookie":
-				cookieBuild.WriteString(fmt.Sprintf(`
-		if cfg.%s != "" {
-			req.AddCookie(&http.Cookie{Name: "%s", Value: cfg.%s})
-		}
-`, fieldName, selectedScheme.HeaderName, fieldName))
-			}
-		}
-	}
-	return authCode
-}
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"go/format"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,16 +10,67 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
+type modelWriter struct {
+	decls        []string
+	idents       map[string]bool
+	external     map[string]string
+	imports      map[string]bool
+	strictHelper bool
+}
+
 func writeModels(outputDir string, doc *openapi3.T) error {
-	var b strings.Builder
-	b.WriteString("package models\n\n")
+	w := &modelWriter{
+		idents:   map[string]bool{"decodeStrict": true},
+		external: make(map[string]string),
+		imports:  make(map[string]bool),
+	}
 
-	for name, schemaRef := range doc.Components.Schemas {
-		if schemaRef.Value == nil {
+	var schemas openapi3.Schemas
+	if doc.Components != nil {
+		schemas = doc.Components.Schemas
+	}
+	names := sortedKeys(schemas)
+
+	// Reserve every component name first so inline types never take one.
+	owners := make(map[string]string)
+	for _, name := range names {
+		typeName := schemaTypeName(name)
+		if other, ok := owners[typeName]; ok {
+			return fmt.Errorf("schemas %s and %s both generate the Go type %s", other, name, typeName)
+		}
+		owners[typeName] = name
+		w.idents[typeName] = true
+	}
+
+	for _, name := range names {
+		ref := schemas[name]
+		if ref == nil || (ref.Value == nil && ref.Ref == "") {
 			continue
 		}
-		structCode := generateStruct(name, schemaRef.Value)
-		b.WriteString(structCode + "\n")
+		if err := w.declare(schemaTypeName(name), ref); err != nil {
+			return fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("package models\n\n")
+	if len(w.imports) > 0 {
+		b.WriteString("import (\n")
+		for _, imp := range sortedKeys(w.imports) {
+			b.WriteString(fmt.Sprintf("\t%q\n", imp))
+		}
+		b.WriteString(")\n\n")
+	}
+	for _, decl := range w.decls {
+		b.WriteString(decl + "\n")
+	}
+	if w.strictHelper {
+		b.WriteString(decodeStrictHelper)
+	}
+
+	code, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return fmt.Errorf("generated models are not valid Go: %w", err)
 	}
 
 	modelsDir := filepath.Join(outputDir, "models")
@@ -26,59 +78,219 @@ func writeModels(outputDir string, doc *openapi3.T) error {
 		return err
 	}
 
-	return os.WriteFile(filepath.Join(modelsDir, "models.go"), []byte(b.String()), 0644)
+	return os.WriteFile(filepath.Join(modelsDir, "models.go"), code, 0644)
+}
+
+// declare emits the named Go type for a schema, ahead of the inline types
+// declared while generating it.
+func (w *modelWriter) declare(name string, ref *openapi3.SchemaRef) error {
+	slot := len(w.decls)
+	w.decls = append(w.decls, "")
+
+	s := ref.Value
+	var code string
+	var err error
+	switch {
+	case ref.Ref != "":
+		var target string
+		target, err = w.goType(name, ref)
+		code = fmt.Sprintf("type %s = %s\n", name, target)
+	case len(s.OneOf) > 0:
+		code, err = w.declareUnion(name, s, s.OneOf)
+	case len(s.AnyOf) > 0:
+		code, err = w.declareUnion(name, s, s.AnyOf)
+	case len(s.AllOf) > 0:
+		code, err = w.declareAllOf(name, s)
+	case len(s.Enum) > 0 && isScalar(s):
+		code, err = w.declareEnum(name, s)
+	case isStruct(s):
+		code, err = w.declareStruct(name, s, nil)
+	default:
+		var target string
+		target, err = w.goType(name, ref)
+		code = fmt.Sprintf("type %s %s\n", name, target)
+	}
+	if err != nil {
+		return err
+	}
+
+	if s != nil && s.Description != "" {
+		line, _, _ := strings.Cut(strings.TrimSpace(s.Description), "\n")
+		code = fmt.Sprintf("// %s %s\n", name, line) + code
+	}
+	w.decls[slot] = code
+	return nil
 }
 
-func generateStruct(name string, schema *openapi3.Schema) string {
+func (w *modelWriter) declareStruct(name string, s *openapi3.Schema, embeds []string) (string, error) {
 	var b strings.Builder
-	typeName := toGoName(name)
-	b.WriteString(fmt.Sprintf("type %s struct {\n", typeName))
+	b.WriteString(fmt.Sprintf("type %s struct {\n", name))
+	for _, e := range embeds {
+		b.WriteString("\t" + e + "\n")
+	}
 
-	for propName, propRef := range schema.Properties {
-		if propRef.Value == nil {
-			continue
+	used := map[string]bool{"AdditionalProperties": hasExtraProperties(s)}
+	for _, e := range embeds {
+		used[e] = true
+	}
+	required := make(map[string]bool)
+	for _, r := range s.Required {
+		required[r] = true
+	}
+
+	props := sortedKeys(s.Properties)
+	for _, prop := range props {
+		propRef := s.Properties[prop]
+		field := fieldName(used, prop)
+		goType, err := w.goType(name+field, propRef)
+		if err != nil {
+			return "", fmt.Errorf("property %s: %w", prop, err)
+		}
+
+		var propSchema *openapi3.Schema
+		if propRef != nil {
+			propSchema = propRef.Value
+		}
+		optional := !required[prop]
+		if (optional || isNullable(propSchema) || goType == name) && pointerable(propRef) && !strings.HasPrefix(goType, "[]") {
+			goType = "*" + goType
 		}
-		fieldName := toGoName(propName)
-		goType := mapSchemaToGoType(propRef)
-		b.WriteString(fmt.Sprintf("\t%s %s `json:\"%s\"`\n", fieldName, goType, propName))
+		tag := prop
+		if optional {
+			tag += ",omitempty"
+		}
+		b.WriteString(fmt.Sprintf("\t%s %s `json:%q`\n", field, goType, tag))
 	}
 
+	var extraType string
+	if hasExtraProperties(s) {
+		var err error
+		if extraType, err = w.mapType(name+"Extra", s); err != nil {
+			return "", err
+		}
+		b.WriteString(fmt.Sprintf("\tAdditionalProperties %s `json:\"-\"`\n", extraType))
+	}
 	b.WriteString("}\n")
-	return b.String()
+
+	if extraType != "" {
+		b.WriteString(w.extraPropertiesMethods(name, strings.TrimPrefix(extraType, "map[string]"), props))
+	}
+	return b.String(), nil
 }
 
-func mapSchemaToGoType(ref *openapi3.SchemaRef) string {
-	if ref == nil {
-		return "interface{}"
+// extraPropertiesMethods keeps the properties that are not declared on the
+// struct in its AdditionalProperties map when decoding and encoding.
+func (w *modelWriter) extraPropertiesMethods(name, valueType string, known []string) string {
+	w.imports["encoding/json"] = true
+	w.imports["fmt"] = true
+	quoted := make([]string, len(known))
+	for i, k := range known {
+		quoted[i] = fmt.Sprintf("%q", k)
+	}
+	return fmt.Sprintf(`
+func (m *%[1]s) UnmarshalJSON(data []byte) error {
+	type plain %[1]s
+	if err := json.Unmarshal(data, (*plain)(m)); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, known := range []string{%[3]s} {
+		delete(fields, known)
+	}
+	m.AdditionalProperties = nil
+	if len(fields) == 0 {
+		return nil
+	}
+	m.AdditionalProperties = make(map[string]%[2]s, len(fields))
+	for k, raw := range fields {
+		var v %[2]s
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("%%s: %%w", k, err)
+		}
+		m.AdditionalProperties[k] = v
 	}
+	return nil
+}
 
-	if ref.Ref != "" {
-		parts := strings.Split(ref.Ref, "/")
-		return toGoName(parts[len(parts)-1])
+func (m %[1]s) MarshalJSON() ([]byte, error) {
+	type plain %[1]s
+	data, err := json.Marshal(plain(m))
+	if err != nil || len(m.AdditionalProperties) == 0 {
+		return data, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range m.AdditionalProperties {
+		if _, ok := fields[k]; ok {
+			continue
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		fields[k] = raw
 	}
+	return json.Marshal(fields)
+}
+`, name, valueType, strings.Join(quoted, ", "))
+}
 
-	schema := ref.Value
-	if schema == nil || schema.Type == nil || len(*schema.Type) == 0 {
-		return "interface{}"
+// declareAllOf embeds the component structs an allOf refers to and merges
+// the properties of its inline members into one struct.
+func (w *modelWriter) declareAllOf(name string, s *openapi3.Schema) (string, error) {
+	if len(s.AllOf) == 1 && s.AllOf[0].Ref != "" && len(s.Properties) == 0 {
+		target, err := w.goType(name, s.AllOf[0])
+		return fmt.Sprintf("type %s = %s\n", name, target), err
 	}
 
-	switch (*schema.Type)[0] {
-	case "string":
-		return "string"
-	case "integer":
-		return "int"
-	case "number":
-		return "float64"
-	case "boolean":
-		return "bool"
-	case "array":
-		if schema.Items != nil {
-			return "[]" + mapSchemaToGoType(schema.Items)
+	merged := &openapi3.Schema{
+		Properties:           openapi3.Schemas{},
+		AdditionalProperties: s.AdditionalProperties,
+	}
+	var embeds []string
+	var merge func(members openapi3.SchemaRefs) bool
+	merge = func(members openapi3.SchemaRefs) bool {
+		for _, member := range members {
+			if member == nil || member.Value == nil {
+				continue
+			}
+			v := member.Value
+			if len(v.OneOf) > 0 || len(v.AnyOf) > 0 {
+				return false
+			}
+			if typeName, ok := modelTypeName(member.Ref); ok && isStruct(v) && !hasCustomJSON(v) {
+				embeds = append(embeds, typeName)
+				continue
+			}
+			if !merge(v.AllOf) {
+				return false
+			}
+			for k, p := range v.Properties {
+				merged.Properties[k] = p
+			}
+			merged.Required = append(merged.Required, v.Required...)
 		}
-		return "[]interface{}"
-	case "object":
-		return "map[string]interface{}"
-	default:
-		return "interface{}"
+		return true
+	}
+
+	if !merge(s.AllOf) {
+		// A oneOf or anyOf member decides fields at runtime, so the
+		// combination has no fixed struct shape.
+		return fmt.Sprintf("type %s map[string]interface{}\n", name), nil
+	}
+	for k, p := range s.Properties {
+		merged.Properties[k] = p
+	}
+	merged.Required = append(merged.Required, s.Required...)
+	merged.Description = s.Description
+
+	if len(embeds) == 0 && len(merged.Properties) == 0 {
+		return fmt.Sprintf("type %s map[string]interface{}\n", name), nil
 	}
+	return w.declareStruct(name, merged, embeds)
 }
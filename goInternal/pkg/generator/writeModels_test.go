@@ -0,0 +1,23 @@
+package generator
+
+import "testing"
+
+func TestWriteModels(t *testing.T) {
+	out := generateTestdata(t, "models.yaml", Config{})
+	assertContains(t, out, "models/models.go",
+		// allOf embeds the referenced struct
+		"type Dog struct {\n\tBase\n",
+		// required fields are plain, optional ones pointers
+		"Id      string     `json:\"id\"`",
+		"Created *time.Time `json:\"created,omitempty\"`",
+		"Name                 *string           `json:\"name,omitempty\"`",
+		// oneOf becomes a union decoded by discriminator
+		"type Pet struct {\n\tDog *Dog\n\tCat *Cat\n}",
+		"switch probe.Kind {",
+		// enums and inline objects get named types
+		"SizeSmall Size = \"small\"",
+		"Address              *OwnerAddress",
+		"AdditionalProperties map[string]int    `json:\"-\"`",
+	)
+	testGeneratedPackage(t, out, "models", "models_test.go")
+}
@@ -4,14 +4,28 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"text/template"
 )
 
 func writePkgUtil(OutputDir string) error {
-	var buf bytes.Buffer
-	if err := UtilTmpl.Execute(&buf, nil); err != nil {
-		return err
+	files := map[string]*template.Template{
+		"utils.go":    UtilTmpl,
+		"body.go":     BodyTmpl,
+		"paginate.go": PaginateTmpl,
+		"output.go":   OutputTmpl,
+		"query.go":    QueryTmpl,
+		"errors.go":   ErrorsTmpl,
 	}
+	for name, tmpl := range files {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, nil); err != nil {
+			return err
+		}
 
-	pathFile := filepath.Join(OutputDir, "utils", "utils.go")
-	return os.WriteFile(pathFile, buf.Bytes(), 0644)
+		pathFile := filepath.Join(OutputDir, "utils", name)
+		if err := os.WriteFile(pathFile, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -14,9 +14,21 @@ func writeRootCmd(outputDir string, moduleName string) error {
 		ModuleName: moduleName,
 	}
 
+	if err := writePkgUtil(outputDir); err != nil {
+		return err
+	}
+
 	if err := RootTmpl.Execute(&buf, data); err != nil {
 		return err
 	}
 	path := filepath.Join(outputDir, "cmd", "root.go")
-	return os.WriteFile(path, buf.Bytes(), 0644)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	buf.Reset()
+	if err := ServersTmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outputDir, "cmd", "servers.go"), buf.Bytes(), 0644)
 }
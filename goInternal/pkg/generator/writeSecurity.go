@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// securityCode returns the code that authenticates req. Each security
+// requirement is an alternative whose schemes all apply together; the first
+// one with every credential configured is used. An empty requirement makes
+// authentication optional, and no requirements (security: []) mean none.
+// Requirements naming a scheme the generator does not support are skipped.
+func securityCode(security *openapi3.SecurityRequirements, schemes map[string]AuthScheme, moduleName string, imports map[string]bool) string {
+	if security == nil {
+		return ""
+	}
+	var cases strings.Builder
+	var options []string
+	optional := false
+	for _, requirement := range *security {
+		if len(requirement) == 0 {
+			optional = true
+			continue
+		}
+		var conds, hints []string
+		var apply strings.Builder
+		for _, name := range sortedKeys(requirement) {
+			s, ok := schemes[name]
+			if !ok {
+				conds = nil
+				break
+			}
+			cond, code, hint := schemeCode(s, requirement[name], moduleName, imports)
+			conds = append(conds, cond)
+			hints = append(hints, hint)
+			apply.WriteString(code)
+		}
+		if conds == nil {
+			continue
+		}
+		cases.WriteString(fmt.Sprintf("\t\t\tcase %s:\n%s", strings.Join(conds, " && "), apply.String()))
+		options = append(options, strings.Join(hints, " and "))
+	}
+	if cases.Len() == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+	if imports[moduleName+"/auth"] {
+		// tokens are cached for the API's server, whatever servers the
+		// operation overrides it with
+		code.WriteString("\t\t\ttokenServer, _ := cfg.ServerURL(config.Servers, ServerVars)\n")
+	}
+	code.WriteString("\t\t\tswitch {\n")
+	code.WriteString(cases.String())
+	if !optional {
+		imports["errors"] = true
+		msg := "missing credentials; set " + options[0]
+		if len(options) > 1 {
+			msg = "missing credentials; set one of:\n  " + strings.Join(options, "\n  ")
+		}
+		code.WriteString(fmt.Sprintf("\t\t\tdefault:\n\t\t\t\treturn errors.New(%q)\n", msg))
+	}
+	code.WriteString("\t\t\t}\n")
+	return code.String()
+}
+
+// schemeCode returns the condition under which s has credentials, the code
+// applying them and a hint naming the config keys that provide them.
+func schemeCode(s AuthScheme, scopes []string, moduleName string, imports map[string]bool) (cond, code, hint string) {
+	setting := func(suffix string) string {
+		key := authKey(s, suffix)
+		return fmt.Sprintf("%s (%s_%s)", key, strings.ToUpper(moduleName), strings.ToUpper(key))
+	}
+	field := authField(s, "Auth")
+
+	switch {
+	case s.Type == "http" && s.Scheme == "basic":
+		username, password := authField(s, "Username"), authField(s, "Password")
+		cond = fmt.Sprintf("(cfg.%s != \"\" || cfg.%s != \"\")", username, password)
+		code = fmt.Sprintf("\t\t\t\treq.SetBasicAuth(cfg.%s, cfg.%s)\n", username, password)
+		hint = setting("username") + " and " + setting("password")
+	case canLogin(s):
+		imports[moduleName+"/auth"] = true
+		scopeList := make([]string, len(scopes))
+		for i, scope := range scopes {
+			scopeList[i] = fmt.Sprintf("%q", scope)
+		}
+		cond = fmt.Sprintf("(cfg.%s != \"\" || auth.Available(%q, tokenServer, cfg.OAuthClients[%q]))", field, s.Name, s.Name)
+		code = fmt.Sprintf(`				if cfg.%s != "" {
+					req.Header.Set("Authorization", "Bearer "+cfg.%s)
+				} else {
+					token, err := auth.AccessToken(req.Context(), %q, tokenServer, cfg.OAuthClients[%q], []string{%s})
+					if err != nil {
+						return err
+					}
+					req.Header.Set("Authorization", "Bearer "+token)
+				}
+`, field, field, s.Name, s.Name, strings.Join(scopeList, ", "))
+		hint = fmt.Sprintf("%s or a login ('%s login %s')", setting("auth"), moduleName, s.Name)
+	case s.Type == "apiKey":
+		cond = fmt.Sprintf("cfg.%s != \"\"", field)
+		switch s.In {
+		case "query":
+			code = fmt.Sprintf("\t\t\t\tq.Set(%q, cfg.%s)\n\t\t\t\treq.URL.RawQuery = q.Encode()\n", s.HeaderName, field)
+		case "cookie":
+			code = fmt.Sprintf("\t\t\t\treq.AddCookie(&http.Cookie{Name: %q, Value: cfg.%s})\n", s.HeaderName, field)
+		default:
+			code = fmt.Sprintf("\t\t\t\treq.Header.Set(%q, cfg.%s)\n", s.HeaderName, field)
+		}
+		hint = setting("auth")
+	default:
+		// bearer tokens, and OAuth2 schemes without a flow to log in with
+		cond = fmt.Sprintf("cfg.%s != \"\"", field)
+		code = fmt.Sprintf("\t\t\t\treq.Header.Set(\"Authorization\", \"Bearer \"+cfg.%s)\n", field)
+		hint = setting("auth")
+	}
+	return cond, code, hint
+}
@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+// authSwitch returns the generated switch that authenticates a command's
+// request, or "" when it has none.
+func authSwitch(t *testing.T, out, cmd string) string {
+	t.Helper()
+	code := emitted(t, out, "cmd/"+cmd+".go")
+	start := strings.Index(code, "\t\t\tswitch {\n")
+	if start < 0 {
+		return ""
+	}
+	end := strings.Index(code[start:], "\n\t\t\t}\n")
+	return code[start : start+end+5]
+}
+
+func TestSecurityCode(t *testing.T) {
+	out := generateTestdata(t, "security.yaml", Config{})
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		// requirements are tried in order; the schemes of one all apply
+		{"andor", `			switch {
+			case (cfg.BasicUsername != "" || cfg.BasicPassword != "") && cfg.KeyAuth != "":
+				req.SetBasicAuth(cfg.BasicUsername, cfg.BasicPassword)
+				req.Header.Set("X-API-Key", cfg.KeyAuth)
+			case (cfg.OaAuth != "" || auth.Available("oa", tokenServer, cfg.OAuthClients["oa"])):
+				if cfg.OaAuth != "" {
+					req.Header.Set("Authorization", "Bearer "+cfg.OaAuth)
+				} else {
+					token, err := auth.AccessToken(req.Context(), "oa", tokenServer, cfg.OAuthClients["oa"], []string{"read"})
+					if err != nil {
+						return err
+					}
+					req.Header.Set("Authorization", "Bearer "+token)
+				}
+			default:
+				return errors.New("missing credentials; set one of:\n  basic_username (TESTCLI_BASIC_USERNAME) and basic_password (TESTCLI_BASIC_PASSWORD) and key_auth (TESTCLI_KEY_AUTH)\n  oa_auth (TESTCLI_OA_AUTH) or a login ('testcli login oa')")
+			}`},
+		// an empty requirement makes authentication optional
+		{"optional", `			switch {
+			case cfg.TokAuth != "":
+				req.Header.Set("Authorization", "Bearer "+cfg.TokAuth)
+			}`},
+		// API keys can go in the query
+		{"query", `			switch {
+			case cfg.QkeyAuth != "":
+				q.Set("api_key", cfg.QkeyAuth)
+				req.URL.RawQuery = q.Encode()
+			default:
+				return errors.New("missing credentials; set qkey_auth (TESTCLI_QKEY_AUTH)")
+			}`},
+		// the operation's scopes are requested
+		{"write", `			switch {
+			case (cfg.OaAuth != "" || auth.Available("oa", tokenServer, cfg.OAuthClients["oa"])):
+				if cfg.OaAuth != "" {
+					req.Header.Set("Authorization", "Bearer "+cfg.OaAuth)
+				} else {
+					token, err := auth.AccessToken(req.Context(), "oa", tokenServer, cfg.OAuthClients["oa"], []string{"read", "write"})
+					if err != nil {
+						return err
+					}
+					req.Header.Set("Authorization", "Bearer "+token)
+				}
+			default:
+				return errors.New("missing credentials; set oa_auth (TESTCLI_OA_AUTH) or a login ('testcli login oa')")
+			}`},
+		// security: [] turns authentication off
+		{"public", ""},
+		// requirements with unsupported schemes are skipped
+		{"tls", ""},
+	}
+	for _, tt := range tests {
+		if got := authSwitch(t, out, tt.cmd); got != tt.want {
+			t.Errorf("%s authenticates with\n%s\nwant\n%s", tt.cmd, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// serversLiteral renders servers as a []Server literal for the generated
+// config package.
+func serversLiteral(servers openapi3.Servers) string {
+	var b strings.Builder
+	b.WriteString("[]Server{\n")
+	used := make(map[string]bool)
+	for _, s := range servers {
+		if s == nil {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("\t{Name: %q, URL: %q, Description: %q", serverName(used, s), s.URL, s.Description))
+		if names := serverVariableNames(s); len(names) > 0 {
+			b.WriteString(", Variables: []ServerVariable{\n")
+			for _, name := range names {
+				v := s.Variables[name]
+				if v == nil {
+					v = &openapi3.ServerVariable{}
+				}
+				enum := make([]string, len(v.Enum))
+				for i, e := range v.Enum {
+					enum[i] = fmt.Sprintf("%q", e)
+				}
+				b.WriteString(fmt.Sprintf("\t\t{Name: %q, Default: %q, Description: %q", name, v.Default, v.Description))
+				if len(enum) > 0 {
+					b.WriteString(fmt.Sprintf(", Enum: []string{%s}", strings.Join(enum, ", ")))
+				}
+				b.WriteString("},\n")
+			}
+			b.WriteString("\t}")
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// serverName is the name --env selects a server by: its description as a
+// slug, or its URL when it has none.
+func serverName(used map[string]bool, s *openapi3.Server) string {
+	base := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s.Description)), " ", "-")
+	if base == "" {
+		base = s.URL
+	}
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	used[name] = true
+	return name
+}
+
+// serverVariableNames lists the variables in the order they appear in the
+// URL, followed by any that are declared but unused.
+func serverVariableNames(s *openapi3.Server) []string {
+	seen := make(map[string]bool)
+	var names []string
+	if inURL, err := s.ParameterNames(); err == nil {
+		for _, name := range inURL {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	var rest []string
+	for name := range s.Variables {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
+}
+
+// commandServers returns the servers an operation overrides the API's
+// servers with, if any. Operation servers take precedence over path ones.
+func commandServers(pathItem *openapi3.PathItem, op *openapi3.Operation) openapi3.Servers {
+	if op.Servers != nil && len(*op.Servers) > 0 {
+		return *op.Servers
+	}
+	if len(pathItem.Servers) > 0 {
+		return pathItem.Servers
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package generator
+
+import "testing"
+
+func TestWriteServers(t *testing.T) {
+	out := generateTestdata(t, "servers.yaml", Config{})
+	assertContains(t, out, "config/config.go",
+		`{Name: "local-echo", URL: "http://{host}:{port}/{version}", Description: "Local echo", Variables: []ServerVariable{`,
+		`{Name: "port", Default: "18080", Description: "", Enum: []string{"18080", "18081"}},`,
+		`{Name: "version", Default: "v1", Description: "API version"},`,
+		`{Name: "production", URL: "https://{region}.api.example.com", Description: "Production", Variables: []ServerVariable{`,
+		// path-level servers apply to all of the path's operations, and an
+		// operation's own servers replace them
+		"\"files list-files\": []Server{\n\t\t{Name: \"http://127.0.0.1:18080/files-path\", URL: \"http://127.0.0.1:18080/files-path\", Description: \"\"},",
+		"\"files upload-file\": []Server{\n\t\t{Name: \"upload-host\", URL: \"http://127.0.0.1:18080/upload\", Description: \"Upload host\"},",
+	)
+	assertContains(t, out, "cmd/listthings.go", "cfg.ServerURL(config.Servers, ServerVars)")
+	assertContains(t, out, "cmd/listfiles.go", `cfg.ServerURL(config.CommandServers["files list-files"], ServerVars)`)
+	assertContains(t, out, "cmd/uploadfile.go", `cfg.ServerURL(config.CommandServers["files upload-file"], ServerVars)`)
+}
+
+func TestWriteGoMod(t *testing.T) {
+	out := generateTestdata(t, "servers.yaml", Config{})
+	// every module the generated code imports is required
+	assertContains(t, out, "go.mod",
+		"module testcli\n",
+		"github.com/joho/godotenv v1.5.1",
+		"github.com/spf13/cobra v1.8.0",
+		"github.com/spf13/viper v1.19.0",
+		"gopkg.in/yaml.v3 v3.0.1",
+	)
+}
@@ -0,0 +1,52 @@
+package pkg
+
+import (
+	"CommandPost/goInternal/pkg/secrets"
+	"CommandPost/goInternal/pkg/variables"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ApplyVariables substitutes {{name}} placeholders in the URL, headers,
+// body, text form fields and signing credentials of req. It fails with
+// secrets.ErrLocked when req uses a secret the locked vault cannot open.
+func ApplyVariables(req RequestData, resolved []variables.Resolved) (RequestData, error) {
+	values := variables.Values(resolved)
+	var locked []string
+	substitute := func(s string) string {
+		locked = append(locked, variables.LockedIn(s, resolved)...)
+		return variables.Substitute(s, values)
+	}
+
+	req.URL = substitute(req.URL)
+	req.Body = substitute(req.Body)
+
+	headers := make(map[string]string, len(req.Headers))
+	for k, v := range req.Headers {
+		headers[substitute(k)] = substitute(v)
+	}
+	req.Headers = headers
+
+	if req.FormData != nil {
+		formData := make(map[string]FormDataPart, len(req.FormData))
+		for k, v := range req.FormData {
+			if !v.IsFile {
+				v.Value = substitute(v.Value)
+			}
+			formData[k] = v
+		}
+		req.FormData = formData
+	}
+
+	if req.Signing != nil {
+		cfg := req.Signing.Map(substitute)
+		req.Signing = &cfg
+	}
+
+	if len(locked) > 0 {
+		slices.Sort(locked)
+		return req, fmt.Errorf("%w; unlock it to use %s", secrets.ErrLocked, strings.Join(slices.Compact(locked), ", "))
+	}
+	return req, nil
+}
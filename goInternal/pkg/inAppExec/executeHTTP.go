@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"CommandPost/goInternal/pkg/signing"
 	"bytes"
 	"io"
 	"mime/multipart"
@@ -10,9 +11,9 @@ import (
 )
 
 func ExecuteHTTP(reqDat RequestData) (ResponseData, error) {
-	var bodyReader io.Reader
+	var body []byte
 	if reqDat.Body != "" {
-		bodyReader = bytes.NewBufferString(reqDat.Body)
+		body = []byte(reqDat.Body)
 	} else if len(reqDat.FormData) > 0 {
 		mimeWriter := &bytes.Buffer{}
 		multipartWriter := multipart.NewWriter(mimeWriter)
@@ -24,7 +25,7 @@ func ExecuteHTTP(reqDat RequestData) (ResponseData, error) {
 			}
 		}
 		multipartWriter.Close()
-		bodyReader = mimeWriter
+		body = mimeWriter.Bytes()
 	}
 
 	if reqDat.Method == "GET" {
@@ -32,18 +33,45 @@ func ExecuteHTTP(reqDat RequestData) (ResponseData, error) {
 		reqDat.FormData = nil
 	}
 
-	req, err := http.NewRequest(reqDat.Method, reqDat.URL, bodyReader)
+	signer, err := signing.New(reqDat.Signing)
 	if err != nil {
 		return ResponseData{
 			StatusCode: 0,
-			Body:       "Error creating request: " + err.Error(),
+			Body:       "Error signing request: " + err.Error(),
 			Headers:    map[string]string{"Content-Type": "text/plain"},
 			TimeMs:     0,
 		}, nil
 	}
 
-	for k, v := range reqDat.Headers {
-		req.Header.Set(k, v)
+	newRequest := func() (*http.Request, error) {
+		var bodyReader io.Reader
+		if body != nil {
+			bodyReader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequest(reqDat.Method, reqDat.URL, bodyReader)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range reqDat.Headers {
+			req.Header.Set(k, v)
+		}
+		// Signing comes last so the signature covers the final headers and body.
+		if signer != nil {
+			if err := signer.Sign(req, body); err != nil {
+				return nil, err
+			}
+		}
+		return req, nil
+	}
+
+	req, err := newRequest()
+	if err != nil {
+		return ResponseData{
+			StatusCode: 0,
+			Body:       "Error creating request: " + err.Error(),
+			Headers:    map[string]string{"Content-Type": "text/plain"},
+			TimeMs:     0,
+		}, nil
 	}
 
 	client := &http.Client{
@@ -52,6 +80,14 @@ func ExecuteHTTP(reqDat RequestData) (ResponseData, error) {
 
 	start := time.Now()
 	resp, err := client.Do(req)
+	if challenger, ok := signer.(signing.Challenger); ok && err == nil {
+		if retry, cerr := challenger.Challenge(resp); cerr == nil && retry {
+			if next, nerr := newRequest(); nerr == nil {
+				resp.Body.Close()
+				resp, err = client.Do(next)
+			}
+		}
+	}
 	if err != nil {
 		return ResponseData{
 			StatusCode: 0,
@@ -72,7 +108,7 @@ func ExecuteHTTP(reqDat RequestData) (ResponseData, error) {
 		resHeaders[name] = strings.Join(header, ", ")
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return ResponseData{}, err
 	}
@@ -80,8 +116,8 @@ func ExecuteHTTP(reqDat RequestData) (ResponseData, error) {
 	return ResponseData{
 		StatusCode: resp.StatusCode,
 		Headers:    resHeaders,
-		Body:       string(body),
+		Body:       string(respBody),
 		TimeMs:     duration.Milliseconds(),
-		Size:       len(body),
+		Size:       len(respBody),
 	}, nil
 }
@@ -1,69 +1,41 @@
 package pkg
 
 import (
-	"CommandPost/goInternal/pkg/generator"
-	"net/url"
-	"strings"
+	"CommandPost/goInternal/pkg/spec"
+	"sort"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
-func ParseSpec(specPath string) (SpecDetails, error) {
-	loader := openapi3.NewLoader()
-
-	var doc *openapi3.T
-	var err error
-	var op *openapi3.Operation
-	var method string
-	var endpointDefs []EndpointDef
-
-	if generator.IsURL(specPath) {
-		parsedURL, err := url.Parse(specPath)
-		if err != nil {
-			return SpecDetails{}, err
-		}
-		doc, err = loader.LoadFromURI(parsedURL)
-	} else {
-		doc, err = loader.LoadFromFile(specPath)
-	}
-
+// ParseSpec lists every operation in the spec, in path then method order.
+func ParseSpec(loader *spec.Loader, specPath string) (SpecDetails, error) {
+	doc, err := loader.Load(specPath)
 	if err != nil {
 		return SpecDetails{}, err
 	}
 
 	baseURL := ""
 	if len(doc.Servers) > 0 {
-		baseURL = doc.Servers[0].URL
+		baseURL = spec.DefaultServerURL(doc.Servers[0])
 	}
 
-	if generator.IsURL(specPath) && !strings.HasPrefix(baseURL, "http") {
-		if specURL, err := url.Parse(specPath); err == nil {
-			if relURL, err := url.Parse(baseURL); err == nil {
-				baseURL = specURL.ResolveReference(relURL).String()
-			}
+	endpointDefs := []EndpointDef{}
+	paths := doc.Paths.Map()
+	for _, path := range sortedKeys(paths) {
+		pathItem := paths[path]
+		if pathItem == nil {
+			continue
 		}
-	}
-
-	for path, pathItem := range doc.Paths.Map() {
-		ops := map[string]*openapi3.Operation{
-			"get":    pathItem.Get,
-			"post":   pathItem.Post,
-			"put":    pathItem.Put,
-			"delete": pathItem.Delete,
-			"patch":  pathItem.Patch,
-		}
-
-		for method, op = range ops {
-			if op == nil {
-				continue
-			}
-
+		ops := pathItem.Operations()
+		for _, method := range sortedKeys(ops) {
+			op := ops[method]
 			endpointDefs = append(endpointDefs, EndpointDef{
-				Method:      strings.ToUpper(method),
+				Method:      method,
 				Path:        path,
 				Summary:     op.Summary,
 				Description: op.Description,
 				Tags:        op.Tags,
+				Parameters:  parameterDefs(pathItem.Parameters, op.Parameters),
 			})
 		}
 	}
@@ -72,3 +44,35 @@ func ParseSpec(specPath string) (SpecDetails, error) {
 		Endpoints: endpointDefs,
 	}, nil
 }
+
+// parameterDefs merges path-level parameters into the operation's; an
+// operation parameter with the same location and name replaces it.
+func parameterDefs(pathParams, opParams openapi3.Parameters) []ParameterDef {
+	var defs []ParameterDef
+	for _, pRef := range pathParams {
+		if pRef == nil || pRef.Value == nil || opParams.GetByInAndName(pRef.Value.In, pRef.Value.Name) != nil {
+			continue
+		}
+		defs = append(defs, parameterDef(pRef.Value))
+	}
+	for _, pRef := range opParams {
+		if pRef == nil || pRef.Value == nil {
+			continue
+		}
+		defs = append(defs, parameterDef(pRef.Value))
+	}
+	return defs
+}
+
+func parameterDef(p *openapi3.Parameter) ParameterDef {
+	return ParameterDef{Name: p.Name, In: p.In, Description: p.Description, Required: p.Required}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
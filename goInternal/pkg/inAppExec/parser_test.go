@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"CommandPost/goInternal/pkg/spec"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const parserSpec = `openapi: 3.0.3
+info: {title: parser, version: "1"}
+servers: [{url: "https://api.example.com"}]
+paths:
+  /users/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: string}}
+      - {name: X-Tenant, in: header, description: tenant, schema: {type: string}}
+    get:
+      parameters:
+        - {name: X-Tenant, in: header, required: true, schema: {type: string}}
+        - {name: fields, in: query, schema: {type: string}}
+      responses: {"200": {description: ok}}
+    head:
+      responses: {"200": {description: ok}}
+    delete:
+      responses: {"204": {description: gone}}
+  /health:
+    options:
+      responses: {"200": {description: ok}}
+    trace:
+      responses: {"200": {description: ok}}
+`
+
+func TestParseSpec(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	if err := os.WriteFile(specPath, []byte(parserSpec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		details, err := ParseSpec(spec.NewLoader(), specPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if details.BaseURL != "https://api.example.com" {
+			t.Errorf("BaseURL = %q", details.BaseURL)
+		}
+		var got []string
+		for _, e := range details.Endpoints {
+			got = append(got, e.Method+" "+e.Path)
+		}
+		want := []string{"OPTIONS /health", "TRACE /health", "DELETE /users/{id}", "GET /users/{id}", "HEAD /users/{id}"}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("endpoints = %v, want %v", got, want)
+		}
+
+		wantParams := []ParameterDef{
+			{Name: "id", In: "path", Required: true},
+			{Name: "X-Tenant", In: "header", Required: true},
+			{Name: "fields", In: "query"},
+		}
+		if params := details.Endpoints[3].Parameters; !reflect.DeepEqual(params, wantParams) {
+			t.Errorf("GET parameters = %+v, want %+v", params, wantParams)
+		}
+		wantHead := []ParameterDef{
+			{Name: "id", In: "path", Required: true},
+			{Name: "X-Tenant", In: "header", Description: "tenant"},
+		}
+		if params := details.Endpoints[4].Parameters; !reflect.DeepEqual(params, wantHead) {
+			t.Errorf("HEAD parameters = %+v, want %+v", params, wantHead)
+		}
+	}
+}
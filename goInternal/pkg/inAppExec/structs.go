@@ -1,11 +1,21 @@
 package pkg
 
+import "CommandPost/goInternal/pkg/signing"
+
 type EndpointDef struct {
-	Method      string   `json:"method"`
-	Path        string   `json:"path"`
-	Summary     string   `json:"summary"`
-	Description string   `json:"description"`
-	Tags        []string `json:"tags"`
+	Method      string         `json:"method"`
+	Path        string         `json:"path"`
+	Summary     string         `json:"summary"`
+	Description string         `json:"description"`
+	Tags        []string       `json:"tags"`
+	Parameters  []ParameterDef `json:"parameters,omitempty"` // path-level and operation parameters
+}
+
+type ParameterDef struct {
+	Name        string `json:"name"`
+	In          string `json:"in"` // path, query, header or cookie
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
 }
 
 type SpecDetails struct {
@@ -19,12 +29,29 @@ type FormDataPart struct {
 }
 
 type RequestData struct {
-	Method   string                  `json:"method"`
-	URL      string                  `json:"url"`
-	Headers  map[string]string       `json:"headers"`
-	Body     string                  `json:"body"`
-	FormData map[string]FormDataPart `json:"formData"`
-	Timeout  int                     `json:"timeout"`
+	Name        string                  `json:"name,omitempty"`
+	Folder      string                  `json:"folder,omitempty"`
+	Method      string                  `json:"method"`
+	URL         string                  `json:"url"`
+	Headers     map[string]string       `json:"headers"`
+	Body        string                  `json:"body"`
+	FormData    map[string]FormDataPart `json:"formData"`
+	Timeout     int                     `json:"timeout"`
+	Variables   map[string]string       `json:"variables,omitempty"`   // request-level {{variables}}, highest priority
+	Environment string                  `json:"environment,omitempty"` // environment whose variables are substituted
+	Collection  string                  `json:"collection,omitempty"`  // collection whose variables are substituted
+	Profile     string                  `json:"profile,omitempty"`     // environment credential profile whose token is sent, "" for the default
+	Signing     *signing.Config         `json:"signing,omitempty"`     // signature applied just before sending
+}
+
+// WithoutSecrets returns a copy of r fit for history, collections and synced
+// files: literal signing secrets are dropped, {{variable}} references kept.
+func (r RequestData) WithoutSecrets() RequestData {
+	if r.Signing != nil {
+		cfg := r.Signing.WithoutSecrets()
+		r.Signing = &cfg
+	}
+	return r
 }
 
 type ResponseData struct {
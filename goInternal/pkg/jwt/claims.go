@@ -0,0 +1,58 @@
+package jwt
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ValidateTimes checks the exp, nbf and iat claims against the current
+// time, allowing leeway for clock skew.
+func ValidateTimes(claims map[string]any, leeway time.Duration) error {
+	now := time.Now()
+	if exp, ok := NumericDate(claims, "exp"); ok && now.After(exp.Add(leeway)) {
+		return ErrExpired
+	}
+	if nbf, ok := NumericDate(claims, "nbf"); ok && now.Add(leeway).Before(nbf) {
+		return ErrNotYetValid
+	}
+	if iat, ok := NumericDate(claims, "iat"); ok && now.Add(leeway).Before(iat) {
+		return ErrNotYetValid
+	}
+	return nil
+}
+
+// NumericDate reads a NumericDate claim (seconds since the epoch).
+func NumericDate(claims map[string]any, name string) (time.Time, bool) {
+	switch v := claims[name].(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(int64(f), 0), true
+	case float64:
+		return time.Unix(int64(v), 0), true
+	case int64:
+		return time.Unix(v, 0), true
+	case int:
+		return time.Unix(int64(v), 0), true
+	}
+	return time.Time{}, false
+}
+
+// Audience returns the aud claim, which may be a string or an array.
+func Audience(claims map[string]any) []string {
+	switch v := claims["aud"].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		aud := make([]string, 0, len(v))
+		for _, a := range v {
+			if s, ok := a.(string); ok {
+				aud = append(aud, s)
+			}
+		}
+		return aud
+	}
+	return nil
+}
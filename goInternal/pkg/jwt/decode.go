@@ -0,0 +1,42 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Decode splits raw into header, claims and signature without verifying
+// anything.
+func Decode(raw string) (Token, error) {
+	raw = strings.TrimSpace(raw)
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return Token{}, ErrMalformed
+	}
+
+	tok := Token{Raw: raw, signingInput: parts[0] + "." + parts[1]}
+	if err := decodeSegment(parts[0], &tok.Header); err != nil {
+		return Token{}, fmt.Errorf("invalid header: %w", err)
+	}
+	if err := decodeSegment(parts[1], &tok.Claims); err != nil {
+		return Token{}, fmt.Errorf("invalid claims: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Token{}, fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	tok.Signature = sig
+	return tok, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(seg, "="))
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
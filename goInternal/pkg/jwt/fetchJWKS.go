@@ -0,0 +1,36 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// FetchJWKS downloads the JSON Web Key Set published at url.
+func FetchJWKS(ctx context.Context, url string) (JWKS, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return JWKS{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return JWKS{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return JWKS{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return JWKS{}, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	var keys JWKS
+	if err := json.Unmarshal(body, &keys); err != nil {
+		return JWKS{}, fmt.Errorf("%s: invalid JWKS: %w", url, err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,51 @@
+package jwt
+
+import (
+	"regexp"
+	"time"
+)
+
+// Info is a decoded token as shown in the JWT tool, with the expiry worked
+// out relative to now.
+type Info struct {
+	Token
+	ExpiresAt string `json:"expiresAt,omitempty"`
+	ExpiresIn int64  `json:"expiresIn"` // seconds until exp, negative once expired
+	Expired   bool   `json:"expired"`
+	HasExpiry bool   `json:"hasExpiry"`
+}
+
+var tokenPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+
+// Inspect decodes raw and computes its expiry.
+func Inspect(raw string) (Info, error) {
+	tok, err := Decode(raw)
+	if err != nil {
+		return Info{}, err
+	}
+	info := Info{Token: tok}
+	if exp, ok := NumericDate(tok.Claims, "exp"); ok {
+		info.HasExpiry = true
+		info.ExpiresAt = exp.Format(time.RFC3339)
+		info.ExpiresIn = int64(time.Until(exp).Seconds())
+		info.Expired = info.ExpiresIn <= 0
+	}
+	return info, nil
+}
+
+// Find decodes every distinct JWT that appears in text, such as the
+// headers or body of a request or response.
+func Find(text string) []Info {
+	seen := map[string]bool{}
+	found := make([]Info, 0)
+	for _, raw := range tokenPattern.FindAllString(text, -1) {
+		if seen[raw] {
+			continue
+		}
+		seen[raw] = true
+		if info, err := Inspect(raw); err == nil {
+			found = append(found, info)
+		}
+	}
+	return found
+}
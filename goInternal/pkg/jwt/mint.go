@@ -0,0 +1,47 @@
+package jwt
+
+import (
+	"crypto"
+	"maps"
+	"strings"
+	"time"
+)
+
+// MintOptions describes a test token. ExpiresIn, when positive, sets iat
+// and exp unless Claims already has them.
+type MintOptions struct {
+	Alg       string         `json:"alg"`
+	Kid       string         `json:"kid,omitempty"`
+	Claims    map[string]any `json:"claims"`
+	ExpiresIn int            `json:"expiresIn"` // seconds
+}
+
+// Mint signs opts.Claims with keyMaterial: the secret itself for HS*, or a
+// PEM private key for the asymmetric algorithms.
+func Mint(opts MintOptions, keyMaterial string) (string, error) {
+	key, err := signingKey(opts.Alg, keyMaterial)
+	if err != nil {
+		return "", err
+	}
+	claims := maps.Clone(opts.Claims)
+	if claims == nil {
+		claims = map[string]any{}
+	}
+	if opts.ExpiresIn > 0 {
+		now := time.Now()
+		if _, ok := claims["iat"]; !ok {
+			claims["iat"] = now.Unix()
+		}
+		if _, ok := claims["exp"]; !ok {
+			claims["exp"] = now.Add(time.Duration(opts.ExpiresIn) * time.Second).Unix()
+		}
+	}
+	return Sign(opts.Alg, opts.Kid, claims, key)
+}
+
+func signingKey(alg string, material string) (crypto.PrivateKey, error) {
+	if strings.HasPrefix(alg, "HS") {
+		return []byte(material), nil
+	}
+	return ParsePrivateKey(material)
+}
@@ -0,0 +1,79 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Sign mints a compact JWS over claims. key must suit alg: a []byte secret
+// for HS*, an *rsa.PrivateKey for RS* and PS*, an *ecdsa.PrivateKey for ES*
+// and an ed25519.PrivateKey for EdDSA.
+func Sign(alg string, kid string, claims map[string]any, key crypto.PrivateKey) (string, error) {
+	header, err := json.Marshal(Header{Alg: alg, Typ: "JWT", Kid: kid})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var sig []byte
+	switch alg {
+	case "HS256", "HS384", "HS512":
+		secret, ok := key.([]byte)
+		if !ok || len(secret) == 0 {
+			return "", fmt.Errorf("%s needs a shared secret", alg)
+		}
+		h, _ := hashFor(alg)
+		mac := hmac.New(h.New, secret)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+		priv, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("%s needs an RSA private key", alg)
+		}
+		h, _ := hashFor(alg)
+		digest := hashBytes(h, []byte(input))
+		if alg[0] == 'P' {
+			sig, err = rsa.SignPSS(rand.Reader, priv, h, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			sig, err = rsa.SignPKCS1v15(rand.Reader, priv, h, digest)
+		}
+		if err != nil {
+			return "", err
+		}
+	case "ES256", "ES384", "ES512":
+		priv, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("%s needs an EC private key", alg)
+		}
+		h, _ := hashFor(alg)
+		r, s, err := ecdsa.Sign(rand.Reader, priv, hashBytes(h, []byte(input)))
+		if err != nil {
+			return "", err
+		}
+		size := (priv.Curve.Params().BitSize + 7) / 8
+		sig = make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+	case "EdDSA":
+		priv, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("EdDSA needs an Ed25519 private key")
+		}
+		sig = ed25519.Sign(priv, []byte(input))
+	default:
+		return "", fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
@@ -0,0 +1,43 @@
+package jwt
+
+import "errors"
+
+var (
+	ErrMalformed        = errors.New("token is not a JWS compact serialization")
+	ErrInvalidSignature = errors.New("token signature is invalid")
+	ErrExpired          = errors.New("token has expired")
+	ErrNotYetValid      = errors.New("token is not valid yet")
+)
+
+type Header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// Token is a decoded but not necessarily verified JWT.
+type Token struct {
+	Raw          string         `json:"raw"`
+	Header       Header         `json:"header"`
+	Claims       map[string]any `json:"claims"`
+	Signature    []byte         `json:"-"`
+	signingInput string
+}
+
+// JWK is a public key from a JSON Web Key Set (RFC 7517). Only the members
+// needed for RSA, EC and OKP (Ed25519) verification keys are kept.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
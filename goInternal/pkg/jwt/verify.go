@@ -0,0 +1,103 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"fmt"
+	"math/big"
+)
+
+// Verify checks tok's signature with key, which must suit the token's alg:
+// a []byte secret for HS*, an *rsa.PublicKey for RS* and PS*, an
+// *ecdsa.PublicKey for ES* and an ed25519.PublicKey for EdDSA. The "none"
+// algorithm is always rejected.
+func Verify(tok Token, key crypto.PublicKey) error {
+	input := []byte(tok.signingInput)
+	switch tok.Header.Alg {
+	case "HS256", "HS384", "HS512":
+		secret, ok := key.([]byte)
+		if !ok {
+			return fmt.Errorf("%s needs a shared secret", tok.Header.Alg)
+		}
+		h, err := hashFor(tok.Header.Alg)
+		if err != nil {
+			return err
+		}
+		mac := hmac.New(h.New, secret)
+		mac.Write(input)
+		if !hmac.Equal(mac.Sum(nil), tok.Signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s needs an RSA key", tok.Header.Alg)
+		}
+		h, err := hashFor(tok.Header.Alg)
+		if err != nil {
+			return err
+		}
+		digest := hashBytes(h, input)
+		if tok.Header.Alg[0] == 'P' {
+			err = rsa.VerifyPSS(pub, h, digest, tok.Signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			err = rsa.VerifyPKCS1v15(pub, h, digest, tok.Signature)
+		}
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	case "ES256", "ES384", "ES512":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s needs an EC key", tok.Header.Alg)
+		}
+		h, err := hashFor(tok.Header.Alg)
+		if err != nil {
+			return err
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(tok.Signature) != 2*size {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(tok.Signature[:size])
+		s := new(big.Int).SetBytes(tok.Signature[size:])
+		if !ecdsa.Verify(pub, hashBytes(h, input), r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case "EdDSA":
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("EdDSA needs an Ed25519 key")
+		}
+		if !ed25519.Verify(pub, input, tok.Signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported signing algorithm %q", tok.Header.Alg)
+	}
+}
+
+func hashFor(alg string) (crypto.Hash, error) {
+	switch alg[len(alg)-3:] {
+	case "256":
+		return crypto.SHA256, nil
+	case "384":
+		return crypto.SHA384, nil
+	case "512":
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("unsupported signing algorithm %q", alg)
+}
+
+func hashBytes(h crypto.Hash, data []byte) []byte {
+	hh := h.New()
+	hh.Write(data)
+	return hh.Sum(nil)
+}
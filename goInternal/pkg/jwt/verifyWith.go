@@ -0,0 +1,62 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"strings"
+)
+
+const (
+	KeySecret = "secret"
+	KeyPEM    = "pem"
+	KeyJWKS   = "jwks"
+)
+
+// VerifyResult reports whether a token checked out against the supplied
+// key, and why not.
+type VerifyResult struct {
+	Valid          bool   `json:"valid"`
+	SignatureValid bool   `json:"signatureValid"`
+	Error          string `json:"error,omitempty"`
+}
+
+// VerifyWith checks raw's signature and time claims with key, interpreted
+// as keyType: a shared secret, a PEM key or certificate, or a JWKS URL.
+func VerifyWith(ctx context.Context, raw string, keyType string, key string) VerifyResult {
+	tok, err := Decode(raw)
+	if err != nil {
+		return VerifyResult{Error: err.Error()}
+	}
+
+	var pub crypto.PublicKey
+	switch keyType {
+	case KeySecret:
+		pub = []byte(key)
+	case KeyPEM:
+		pub, err = ParsePublicKey(key)
+	case KeyJWKS:
+		var keys JWKS
+		if keys, err = FetchJWKS(ctx, strings.TrimSpace(key)); err == nil {
+			jwk, ok := keys.Find(tok.Header)
+			if !ok {
+				err = fmt.Errorf("no key in the set matches kid %q", tok.Header.Kid)
+			} else {
+				pub, err = jwk.PublicKey()
+			}
+		}
+	default:
+		err = fmt.Errorf("unknown key type %q", keyType)
+	}
+	if err != nil {
+		return VerifyResult{Error: err.Error()}
+	}
+
+	if err := Verify(tok, pub); err != nil {
+		return VerifyResult{Error: err.Error()}
+	}
+	if err := ValidateTimes(tok.Claims, 0); err != nil {
+		return VerifyResult{SignatureValid: true, Error: err.Error()}
+	}
+	return VerifyResult{Valid: true, SignatureValid: true}
+}
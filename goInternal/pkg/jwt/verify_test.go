@@ -0,0 +1,123 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	claims := map[string]any{"sub": "alice"}
+
+	sign := func(alg string, key crypto.PrivateKey) string {
+		t.Helper()
+		raw, err := Sign(alg, "", claims, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+	unsigned := func(alg string) string {
+		enc := base64.RawURLEncoding.EncodeToString
+		return enc([]byte(`{"alg":"`+alg+`","typ":"JWT"}`)) + "." + enc([]byte(`{"sub":"alice"}`)) + "."
+	}
+	truncate := func(raw string) string {
+		return raw[:len(raw)-4]
+	}
+	tamper := func(raw string) string {
+		parts := strings.Split(raw, ".")
+		parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"mallory"}`))
+		return strings.Join(parts, ".")
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+		key  crypto.PublicKey
+		err  string // "" for a valid token
+	}{
+		{name: "HS256", raw: sign("HS256", []byte("secret")), key: []byte("secret")},
+		{name: "HS512", raw: sign("HS512", []byte("secret")), key: []byte("secret")},
+		{name: "RS256", raw: sign("RS256", rsaKey), key: &rsaKey.PublicKey},
+		{name: "PS384", raw: sign("PS384", rsaKey), key: &rsaKey.PublicKey},
+		{name: "ES256", raw: sign("ES256", ecKey), key: &ecKey.PublicKey},
+		{name: "EdDSA", raw: sign("EdDSA", edKey), key: edPub},
+
+		{name: "wrong secret", raw: sign("HS256", []byte("secret")), key: []byte("guess"), err: ErrInvalidSignature.Error()},
+		{name: "tampered HS256", raw: tamper(sign("HS256", []byte("secret"))), key: []byte("secret"), err: ErrInvalidSignature.Error()},
+		{name: "tampered RS256", raw: tamper(sign("RS256", rsaKey)), key: &rsaKey.PublicKey, err: ErrInvalidSignature.Error()},
+		{name: "tampered ES256", raw: tamper(sign("ES256", ecKey)), key: &ecKey.PublicKey, err: ErrInvalidSignature.Error()},
+		{name: "tampered EdDSA", raw: tamper(sign("EdDSA", edKey)), key: edPub, err: ErrInvalidSignature.Error()},
+		{name: "truncated ES256", raw: truncate(sign("ES256", ecKey)), key: &ecKey.PublicKey, err: ErrInvalidSignature.Error()},
+
+		// An HS256 token keyed with the RSA public key must not pass as
+		// RS256: the public key is never used as an HMAC secret.
+		{name: "HS256 signed with the RSA public key", raw: sign("HS256", rsaPEM), key: &rsaKey.PublicKey, err: "needs a shared secret"},
+		{name: "HS256 signed with the RSA key DER", raw: sign("HS256", der), key: &rsaKey.PublicKey, err: "needs a shared secret"},
+		{name: "RS256 against a secret", raw: sign("RS256", rsaKey), key: []byte("secret"), err: "needs an RSA key"},
+		{name: "ES256 against an RSA key", raw: sign("ES256", ecKey), key: &rsaKey.PublicKey, err: "needs an EC key"},
+
+		{name: "none", raw: unsigned("none"), key: []byte("secret"), err: "unsupported signing algorithm"},
+		{name: "None", raw: unsigned("None"), key: []byte(""), err: "unsupported signing algorithm"},
+		{name: "NONE", raw: unsigned("NONE"), key: nil, err: "unsupported signing algorithm"},
+		{name: "empty alg", raw: unsigned(""), key: []byte("secret"), err: "unsupported signing algorithm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok, err := Decode(tt.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = Verify(tok, tt.key)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Verify() = %v, want valid", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Verify() = %v, want %q", err, tt.err)
+			}
+		})
+	}
+
+	t.Run("VerifyWith PEM rejects HS256", func(t *testing.T) {
+		res := VerifyWith(context.Background(), sign("HS256", rsaPEM), KeyPEM, string(rsaPEM))
+		if res.Valid || res.SignatureValid {
+			t.Errorf("VerifyWith() = %+v, want invalid", res)
+		}
+	})
+	t.Run("signature error", func(t *testing.T) {
+		tok, _ := Decode(sign("HS256", []byte("secret")))
+		if err := Verify(tok, []byte("guess")); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Verify() = %v, want ErrInvalidSignature", err)
+		}
+	})
+}
@@ -9,6 +9,9 @@ func buildUrl(params AuthUrlParams, additionalParams url.Values) string {
 	q.Set("redirect_uri", params.redirectURI)
 	q.Set("scope", params.scope)
 	q.Set("state", params.state)
+	if params.nonce != "" {
+		q.Set("nonce", params.nonce)
+	}
 	q.Set("code_challenge", params.codeChallenge)
 	q.Set("code_challenge_method", "S256")
 	for k, v := range additionalParams {
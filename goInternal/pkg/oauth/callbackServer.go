@@ -2,38 +2,67 @@ package oauth
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
+	"html"
 	"net"
 	"net/http"
 )
 
-func StartCallbackServer(listenAddr string) (string, chan string, chan error, func(), error) {
-	codeChan := make(chan string)
-	errChan := make(chan error)
+// AppName is the application named on the page the browser lands on after
+// the authorization redirect.
+var AppName = "CommandPost"
+
+// StartCallbackServer listens on listenAddr for the authorization redirect
+// on path. Only the first redirect carrying the expected state is
+// accepted; error redirects from the authorization server are delivered as
+// *AuthorizationError.
+func StartCallbackServer(listenAddr string, path string, state string) (string, chan callbackResult, func(), error) {
+	if path == "" {
+		path = "/callback"
+	}
+	results := make(chan callbackResult, 1)
+	deliver := func(r callbackResult) {
+		select {
+		case results <- r:
+		default:
+		}
+	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
-		code := r.URL.Query().Get("code")
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if subtle.ConstantTimeCompare([]byte(q.Get("state")), []byte(state)) != 1 {
+			writeCallbackPage(w, http.StatusBadRequest, "Authorization failed", "The response did not match the login that "+AppName+" started.")
+			return
+		}
+		if code := q.Get("error"); code != "" {
+			authErr := &AuthorizationError{Code: code, Description: q.Get("error_description"), URI: q.Get("error_uri")}
+			writeCallbackPage(w, http.StatusBadRequest, "Authorization failed", authErr.Error())
+			deliver(callbackResult{err: authErr})
+			return
+		}
+		code := q.Get("code")
 		if code == "" {
-			http.Error(w, "No code received", http.StatusBadRequest)
+			writeCallbackPage(w, http.StatusBadRequest, "Authorization failed", "No code received.")
+			deliver(callbackResult{err: fmt.Errorf("authorization server redirected without a code")})
 			return
 		}
-		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte("<html><body><h1>Authorization complete</h1><p>You may close this window and return to CommandPost.</p></body></html>"))
-		codeChan <- code
+		writeCallbackPage(w, http.StatusOK, "Authorization complete", "You may close this window and return to "+AppName+".")
+		deliver(callbackResult{code: code})
 	})
 
 	ln, err := net.Listen("tcp", listenAddr)
 	if err != nil {
-		return "", nil, nil, nil, err
+		return "", nil, nil, err
 	}
 
-	addr := fmt.Sprintf("http://%s/callback", ln.Addr().String())
+	addr := fmt.Sprintf("http://%s%s", ln.Addr().String(), path)
 
 	server := &http.Server{Handler: mux}
 	go func() {
 		if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
-			errChan <- err
+			deliver(callbackResult{err: err})
 		}
 	}()
 
@@ -41,5 +70,11 @@ func StartCallbackServer(listenAddr string) (string, chan string, chan error, fu
 		server.Shutdown(context.Background())
 	}
 
-	return addr, codeChan, errChan, cleanup, nil
+	return addr, results, cleanup, nil
+}
+
+func writeCallbackPage(w http.ResponseWriter, status int, title string, message string) {
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<html><body><h1>%s</h1><p>%s</p></body></html>", html.EscapeString(title), html.EscapeString(message))
 }
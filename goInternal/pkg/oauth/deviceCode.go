@@ -0,0 +1,81 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// deviceCodeFlow implements the RFC 8628 device authorization grant: it
+// requests a user code, hands it to onDeviceCode and polls the token
+// endpoint until the user approves, denies or the code expires.
+func deviceCodeFlow(ctx context.Context, cfg Config, onDeviceCode func(DeviceAuthorization)) (Token, error) {
+	if cfg.DeviceAuthURL == "" {
+		return Token{}, fmt.Errorf("device code grant requires a device authorization URL")
+	}
+
+	data := url.Values{}
+	if cfg.Scope != "" {
+		data.Set("scope", cfg.Scope)
+	}
+	body, err := postForm(ctx, cfg, cfg.DeviceAuthURL, data)
+	if err != nil {
+		return Token{}, err
+	}
+	var device DeviceAuthorization
+	if err := json.Unmarshal(body, &device); err != nil {
+		return Token{}, fmt.Errorf("failed to parse device authorization response: %w", err)
+	}
+	if device.DeviceCode == "" || device.UserCode == "" {
+		return Token{}, fmt.Errorf("device authorization response is missing device_code or user_code")
+	}
+
+	if onDeviceCode != nil {
+		onDeviceCode(device)
+	}
+
+	interval := time.Duration(device.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	expiresIn := time.Duration(device.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = 10 * time.Minute
+	}
+	deadline := time.Now().Add(expiresIn)
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return Token{}, flowCanceled(ctx)
+		case <-time.After(interval):
+		}
+
+		poll := url.Values{}
+		poll.Set("grant_type", grantTypeDeviceCode)
+		poll.Set("device_code", device.DeviceCode)
+		token, err := requestToken(ctx, cfg, poll)
+		if err == nil {
+			return token, nil
+		}
+		if ctx.Err() != nil {
+			return Token{}, flowCanceled(ctx)
+		}
+
+		var oauthErr *tokenError
+		if !errors.As(err, &oauthErr) {
+			return Token{}, err
+		}
+		switch oauthErr.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return Token{}, err
+		}
+	}
+	return Token{}, fmt.Errorf("device code expired before the login was approved")
+}
@@ -0,0 +1,115 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProviderMetadata is the subset of the OpenID Connect discovery document
+// (and RFC 8414 authorization server metadata) CommandPost uses.
+type ProviderMetadata struct {
+	Issuer                      string   `json:"issuer"`
+	AuthorizationEndpoint       string   `json:"authorization_endpoint"`
+	TokenEndpoint               string   `json:"token_endpoint"`
+	UserinfoEndpoint            string   `json:"userinfo_endpoint,omitempty"`
+	JwksURI                     string   `json:"jwks_uri,omitempty"`
+	DeviceAuthorizationEndpoint string   `json:"device_authorization_endpoint,omitempty"`
+	IntrospectionEndpoint       string   `json:"introspection_endpoint,omitempty"`
+	RevocationEndpoint          string   `json:"revocation_endpoint,omitempty"`
+	EndSessionEndpoint          string   `json:"end_session_endpoint,omitempty"`
+	ScopesSupported             []string `json:"scopes_supported,omitempty"`
+	GrantTypesSupported         []string `json:"grant_types_supported,omitempty"`
+}
+
+const metadataTTL = time.Hour
+
+var (
+	metadataMu    sync.Mutex
+	metadataCache = map[string]cachedMetadata{}
+)
+
+type cachedMetadata struct {
+	metadata ProviderMetadata
+	fetched  time.Time
+}
+
+// Discover fetches issuer's .well-known/openid-configuration. Documents
+// are cached for an hour per issuer.
+func Discover(ctx context.Context, issuer string) (ProviderMetadata, error) {
+	issuer = strings.TrimRight(strings.TrimSpace(issuer), "/")
+	if issuer == "" {
+		return ProviderMetadata{}, fmt.Errorf("issuer URL is required for discovery")
+	}
+
+	metadataMu.Lock()
+	cached, ok := metadataCache[issuer]
+	metadataMu.Unlock()
+	if ok && time.Since(cached.fetched) < metadataTTL {
+		return cached.metadata, nil
+	}
+
+	var metadata ProviderMetadata
+	if err := getJSON(ctx, issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return ProviderMetadata{}, fmt.Errorf("discovery failed: %w", err)
+	}
+	if strings.TrimRight(metadata.Issuer, "/") != issuer {
+		return ProviderMetadata{}, fmt.Errorf("discovery document is for issuer %q, not %q", metadata.Issuer, issuer)
+	}
+	if metadata.TokenEndpoint == "" {
+		return ProviderMetadata{}, fmt.Errorf("discovery document has no token_endpoint")
+	}
+
+	metadataMu.Lock()
+	metadataCache[issuer] = cachedMetadata{metadata: metadata, fetched: time.Now()}
+	metadataMu.Unlock()
+	return metadata, nil
+}
+
+// fillEndpoints sets any endpoint cfg leaves empty from metadata.
+func (c *Config) fillEndpoints(metadata ProviderMetadata) {
+	if c.AuthURL == "" {
+		c.AuthURL = metadata.AuthorizationEndpoint
+	}
+	if c.TokenURL == "" {
+		c.TokenURL = metadata.TokenEndpoint
+	}
+	if c.DeviceAuthURL == "" {
+		c.DeviceAuthURL = metadata.DeviceAuthorizationEndpoint
+	}
+	if c.IntrospectURL == "" {
+		c.IntrospectURL = metadata.IntrospectionEndpoint
+	}
+	if c.RevokeURL == "" {
+		c.RevokeURL = metadata.RevocationEndpoint
+	}
+}
+
+func getJSON(ctx context.Context, endpoint string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", endpoint, resp.Status)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("%s: invalid JSON: %w", endpoint, err)
+	}
+	return nil
+}
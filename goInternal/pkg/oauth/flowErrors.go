@@ -0,0 +1,25 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+)
+
+func (e *AuthorizationError) Error() string {
+	msg := "authorization failed: " + e.Code
+	if e.Description != "" {
+		msg += ": " + e.Description
+	}
+	if e.URI != "" {
+		msg += " (" + e.URI + ")"
+	}
+	return msg
+}
+
+// flowCanceled translates why ctx ended into the error reported to the UI.
+func flowCanceled(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrFlowTimeout
+	}
+	return ErrFlowCanceled
+}
@@ -0,0 +1,57 @@
+package oauth
+
+import (
+	"CommandPost/goInternal/pkg/jwt"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ValidateIDToken verifies an OpenID Connect id_token issued to clientID:
+// its signature against the provider's JWKS, the iss, aud, azp, exp and iat
+// claims, and the nonce when one was sent with the authorization request.
+func ValidateIDToken(ctx context.Context, metadata ProviderMetadata, clientID string, nonce string, raw string) (jwt.Token, error) {
+	tok, err := jwt.Decode(raw)
+	if err != nil {
+		return jwt.Token{}, err
+	}
+	if metadata.JwksURI == "" {
+		return tok, fmt.Errorf("provider does not publish a jwks_uri")
+	}
+	key, err := signingKey(ctx, metadata.JwksURI, tok.Header)
+	if err != nil {
+		return tok, err
+	}
+	pub, err := key.PublicKey()
+	if err != nil {
+		return tok, err
+	}
+	if err := jwt.Verify(tok, pub); err != nil {
+		return tok, err
+	}
+
+	if iss, _ := tok.Claims["iss"].(string); strings.TrimRight(iss, "/") != strings.TrimRight(metadata.Issuer, "/") {
+		return tok, fmt.Errorf("id_token issuer %q does not match %q", iss, metadata.Issuer)
+	}
+	aud := jwt.Audience(tok.Claims)
+	if !slices.Contains(aud, clientID) {
+		return tok, fmt.Errorf("id_token audience %v does not include client %q", aud, clientID)
+	}
+	if azp, ok := tok.Claims["azp"].(string); ok && azp != clientID {
+		return tok, fmt.Errorf("id_token was issued to %q, not %q", azp, clientID)
+	}
+	if _, ok := jwt.NumericDate(tok.Claims, "exp"); !ok {
+		return tok, fmt.Errorf("id_token has no exp claim")
+	}
+	if err := jwt.ValidateTimes(tok.Claims, time.Minute); err != nil {
+		return tok, err
+	}
+	if nonce != "" {
+		if got, _ := tok.Claims["nonce"].(string); got != nonce {
+			return tok, fmt.Errorf("id_token nonce does not match the login request")
+		}
+	}
+	return tok, nil
+}
@@ -0,0 +1,65 @@
+package oauth
+
+import (
+	"CommandPost/goInternal/pkg/jwt"
+	"context"
+)
+
+// Identity is what the provider says about the signed-in user: the decoded
+// id_token and the userinfo response. Failures are reported per part so
+// that whatever could be decoded is still shown.
+type Identity struct {
+	IDToken       *jwt.Token     `json:"idToken,omitempty"`
+	Verified      bool           `json:"verified"`
+	VerifyError   string         `json:"verifyError,omitempty"`
+	UserInfo      map[string]any `json:"userInfo,omitempty"`
+	UserInfoError string         `json:"userInfoError,omitempty"`
+}
+
+// Inspect decodes and validates idToken and fetches userinfo with
+// accessToken, using the provider discovered from cfg.Issuer.
+func Inspect(ctx context.Context, cfg Config, idToken string, accessToken string) Identity {
+	var identity Identity
+	var metadata ProviderMetadata
+	var discoverErr error
+	if cfg.Issuer != "" {
+		metadata, discoverErr = Discover(ctx, cfg.Issuer)
+	}
+
+	if idToken != "" {
+		tok, err := jwt.Decode(idToken)
+		switch {
+		case err != nil:
+			identity.VerifyError = err.Error()
+		case cfg.Issuer == "":
+			identity.IDToken = &tok
+			identity.VerifyError = "no issuer configured, so the signature was not checked"
+		case discoverErr != nil:
+			identity.IDToken = &tok
+			identity.VerifyError = discoverErr.Error()
+		default:
+			tok, err = ValidateIDToken(ctx, metadata, cfg.ClientID, "", idToken)
+			identity.IDToken = &tok
+			identity.Verified = err == nil
+			if err != nil {
+				identity.VerifyError = err.Error()
+			}
+		}
+	}
+
+	switch {
+	case accessToken == "":
+		identity.UserInfoError = "no access token"
+	case discoverErr != nil:
+		identity.UserInfoError = discoverErr.Error()
+	case metadata.UserinfoEndpoint == "":
+		identity.UserInfoError = "provider has no userinfo endpoint"
+	default:
+		info, err := FetchUserInfo(ctx, metadata.UserinfoEndpoint, accessToken)
+		identity.UserInfo = info
+		if err != nil {
+			identity.UserInfoError = err.Error()
+		}
+	}
+	return identity
+}
@@ -0,0 +1,72 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+const (
+	TokenHintAccess  = "access_token"
+	TokenHintRefresh = "refresh_token"
+)
+
+// Introspect asks the authorization server about token (RFC 7662). The
+// response always has an "active" member; inactive tokens carry nothing
+// else.
+func Introspect(ctx context.Context, cfg Config, token string, hint string) (map[string]any, error) {
+	endpoint, err := endpointFor(ctx, &cfg, &cfg.IntrospectURL, "introspection")
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("token", token)
+	if hint != "" {
+		form.Set("token_type_hint", hint)
+	}
+	body, err := postForm(ctx, cfg, endpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]any
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse introspection response: %w", err)
+	}
+	if _, ok := result["active"].(bool); !ok {
+		return nil, fmt.Errorf("introspection response has no active member")
+	}
+	return result, nil
+}
+
+// Revoke invalidates token at the revocation endpoint (RFC 7009).
+// Revoking a token the server no longer knows about succeeds.
+func Revoke(ctx context.Context, cfg Config, token string, hint string) error {
+	endpoint, err := endpointFor(ctx, &cfg, &cfg.RevokeURL, "revocation")
+	if err != nil {
+		return err
+	}
+	form := url.Values{}
+	form.Set("token", token)
+	if hint != "" {
+		form.Set("token_type_hint", hint)
+	}
+	_, err = postForm(ctx, cfg, endpoint, form)
+	return err
+}
+
+// endpointFor returns *field, discovering it from cfg.Issuer when it was
+// not configured.
+func endpointFor(ctx context.Context, cfg *Config, field *string, name string) (string, error) {
+	if *field == "" && cfg.Issuer != "" {
+		metadata, err := Discover(ctx, cfg.Issuer)
+		if err != nil {
+			return "", err
+		}
+		cfg.fillEndpoints(metadata)
+	}
+	if *field == "" {
+		return "", fmt.Errorf("no %s endpoint configured", name)
+	}
+	return *field, nil
+}
@@ -0,0 +1,49 @@
+package oauth
+
+import (
+	"CommandPost/goInternal/pkg/jwt"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const jwksTTL = time.Hour
+
+var (
+	jwksMu    sync.Mutex
+	jwksCache = map[string]cachedJWKS{}
+)
+
+type cachedJWKS struct {
+	keys    jwt.JWKS
+	fetched time.Time
+}
+
+// signingKey returns the key from jwksURI that verifies a token with the
+// given header. Key sets are cached, and refetched once when the kid is
+// unknown so that provider key rotation is picked up.
+func signingKey(ctx context.Context, jwksURI string, h jwt.Header) (jwt.JWK, error) {
+	jwksMu.Lock()
+	cached, ok := jwksCache[jwksURI]
+	jwksMu.Unlock()
+
+	if ok && time.Since(cached.fetched) < jwksTTL {
+		if k, found := cached.keys.Find(h); found {
+			return k, nil
+		}
+	}
+
+	keys, err := jwt.FetchJWKS(ctx, jwksURI)
+	if err != nil {
+		return jwt.JWK{}, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	jwksMu.Lock()
+	jwksCache[jwksURI] = cachedJWKS{keys: keys, fetched: time.Now()}
+	jwksMu.Unlock()
+
+	if k, found := keys.Find(h); found {
+		return k, nil
+	}
+	return jwt.JWK{}, fmt.Errorf("no key in %s matches kid %q", jwksURI, h.Kid)
+}
@@ -1,71 +1,145 @@
 package oauth
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/url"
+	"strings"
+	"time"
 )
 
-func PerformOAuthFlow(clientID string, tokenEndpoint string, authEndpoint string, redirectURI string, scope string,
-	openBrowser func(string)) (string, string, int, error) {
+// PerformOAuthFlow obtains a token using the grant selected in cfg.
+// openBrowser is used by the authorization code grant, and onDeviceCode
+// receives the user code to display during the device authorization grant.
+// The flow gives up after cfg.FlowTimeout seconds or when ctx is cancelled.
+func PerformOAuthFlow(ctx context.Context, cfg Config, openBrowser func(string), onDeviceCode func(DeviceAuthorization)) (Token, error) {
+	timeout := DefaultFlowTimeout
+	if cfg.FlowTimeout > 0 {
+		timeout = time.Duration(cfg.FlowTimeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if cfg.Issuer != "" && (cfg.TokenURL == "" || cfg.AuthURL == "" || cfg.DeviceAuthURL == "") {
+		if metadata, err := Discover(ctx, cfg.Issuer); err == nil {
+			cfg.fillEndpoints(metadata)
+		} else if cfg.TokenURL == "" {
+			return Token{}, err
+		}
+	}
+
+	var token Token
+	var err error
+	switch cfg.GrantType {
+	case GrantAuthorizationCode, "":
+		token, err = authorizationCodeFlow(ctx, cfg, openBrowser)
+	case GrantClientCredentials:
+		token, err = clientCredentialsFlow(ctx, cfg)
+	case GrantPassword:
+		token, err = passwordFlow(ctx, cfg)
+	case GrantDeviceCode:
+		token, err = deviceCodeFlow(ctx, cfg, onDeviceCode)
+	default:
+		return Token{}, fmt.Errorf("unsupported grant type %q", cfg.GrantType)
+	}
+	if err != nil && ctx.Err() != nil {
+		return Token{}, flowCanceled(ctx)
+	}
+	return token, err
+}
+
+func authorizationCodeFlow(ctx context.Context, cfg Config, openBrowser func(string)) (Token, error) {
+	if openBrowser == nil {
+		return Token{}, fmt.Errorf("authorization code grant needs a browser to sign in")
+	}
 	verifier, challenge, err := generatePKCE()
 	if err != nil {
-		return "", "", 0, err
+		return Token{}, err
+	}
+	state, err := randomToken()
+	if err != nil {
+		return Token{}, err
+	}
+	var nonce string
+	if hasScope(cfg.Scope, "openid") {
+		if nonce, err = randomToken(); err != nil {
+			return Token{}, err
+		}
 	}
 
-	u, err := url.Parse(redirectURI)
+	u, err := url.Parse(cfg.RedirectURI)
 	if err != nil {
-		return "", "", 0, fmt.Errorf("invalid redirect URI: %w", err)
+		return Token{}, fmt.Errorf("invalid redirect URI: %w", err)
 	}
 	listenHost := u.Host
 	if listenHost == "" {
 		listenHost = "127.0.0.1:8090"
 	}
 
-	_, codeChan, errChan, cleanup, err := StartCallbackServer(listenHost)
+	_, results, cleanup, err := StartCallbackServer(listenHost, u.Path, state)
 	if err != nil {
-		return "", "", 0, err
+		return Token{}, err
 	}
 	defer cleanup()
 
 	authUrl := buildUrl(AuthUrlParams{
-		authEndpoint:  authEndpoint,
-		clientID:      clientID,
-		redirectURI:   redirectURI,
-		scope:         scope,
-		state:         "random_state", // randomize for production
+		authEndpoint:  cfg.AuthURL,
+		clientID:      cfg.ClientID,
+		redirectURI:   cfg.RedirectURI,
+		scope:         cfg.Scope,
+		state:         state,
+		nonce:         nonce,
 		codeChallenge: challenge,
 	}, url.Values{})
 
 	openBrowser(authUrl)
 
-	var code string
+	var result callbackResult
 	select {
-	case code = <-codeChan:
-		// Success
-	case err := <-errChan:
-		return "", "", 0, err
+	case result = <-results:
+	case <-ctx.Done():
+		return Token{}, flowCanceled(ctx)
+	}
+	if result.err != nil {
+		return Token{}, result.err
 	}
 
-	tokenBody, err := exchangeCodeForToken(ExchangeCodeForTokenParams{
-		tokenEndpoint: tokenEndpoint,
-		clientID:      clientID,
-		redirectURI:   redirectURI,
-		code:          code,
-		codeVerifier:  verifier,
-	})
-	if err != nil {
-		return "", "", 0, err
+	token, err := exchangeCodeForToken(ctx, cfg, result.code, verifier)
+	token.Nonce = nonce
+	return token, err
+}
+
+func hasScope(scope string, want string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == want {
+			return true
+		}
 	}
+	return false
+}
 
-	var tokenRes struct {
-		AccessToken  string `json:"access_token"`
-		RefreshToken string `json:"refresh_token"`
-		ExpiresIn    int    `json:"expires_in"`
+func clientCredentialsFlow(ctx context.Context, cfg Config) (Token, error) {
+	if cfg.ClientSecret == "" {
+		return Token{}, fmt.Errorf("client credentials grant requires a client secret")
 	}
-	if err := json.Unmarshal(tokenBody, &tokenRes); err != nil {
-		return "", "", 0, fmt.Errorf("failed to parse token response: %w", err)
+	data := url.Values{}
+	data.Set("grant_type", GrantClientCredentials)
+	if cfg.Scope != "" {
+		data.Set("scope", cfg.Scope)
 	}
+	return requestToken(ctx, cfg, data)
+}
 
-	return tokenRes.AccessToken, tokenRes.RefreshToken, tokenRes.ExpiresIn, nil
+func passwordFlow(ctx context.Context, cfg Config) (Token, error) {
+	if cfg.Username == "" {
+		return Token{}, fmt.Errorf("password grant requires a username")
+	}
+	data := url.Values{}
+	data.Set("grant_type", GrantPassword)
+	data.Set("username", cfg.Username)
+	data.Set("password", cfg.Password)
+	if cfg.Scope != "" {
+		data.Set("scope", cfg.Scope)
+	}
+	return requestToken(ctx, cfg, data)
 }
@@ -6,7 +6,9 @@ import (
 	"encoding/base64"
 )
 
-func generateCodeVerifier() (string, error) {
+// randomToken returns 256 bits of randomness, base64url encoded, for use
+// as a PKCE verifier, state or nonce.
+func randomToken() (string, error) {
 	b := make([]byte, 32)
 	_, err := rand.Read(b)
 	if err != nil {
@@ -15,6 +17,10 @@ func generateCodeVerifier() (string, error) {
 	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
+func generateCodeVerifier() (string, error) {
+	return randomToken()
+}
+
 func generateCodeChallenge(verifier string) string {
 	hash := sha256.Sum256([]byte(verifier))
 	return base64.RawURLEncoding.EncodeToString(hash[:])
@@ -1,27 +1,51 @@
 package oauth
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
+	"time"
 )
 
-func refresh_token(tokenEndpoint string, clientID string, refreshToken string) ([]byte, error) {
+// RefreshToken exchanges refreshToken for a new access token at the token
+// endpoint. Providers that do not rotate refresh tokens leave
+// Token.RefreshToken empty, in which case the old one stays valid.
+func RefreshToken(ctx context.Context, cfg Config, refreshToken string) (Token, error) {
+	if refreshToken == "" {
+		return Token{}, fmt.Errorf("no refresh token available")
+	}
+	if cfg.TokenURL == "" && cfg.Issuer != "" {
+		metadata, err := Discover(ctx, cfg.Issuer)
+		if err != nil {
+			return Token{}, err
+		}
+		cfg.fillEndpoints(metadata)
+	}
 	data := url.Values{}
 	data.Set("grant_type", "refresh_token")
-	data.Set("client_id", clientID)
 	data.Set("refresh_token", refreshToken)
-
-	resp, err := http.PostForm(tokenEndpoint, data)
-	if err != nil {
-		return nil, err
+	if cfg.Scope != "" {
+		data.Set("scope", cfg.Scope)
 	}
-	defer resp.Body.Close()
+	return requestToken(ctx, cfg, data)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("refresh_token: %s", resp.Status)
+// Expired reports whether a token with the given RFC 3339 expiry has
+// expired or will within leeway. Tokens without a recorded expiry never
+// expire.
+func Expired(expiresAt string, leeway time.Duration) bool {
+	if expiresAt == "" {
+		return false
+	}
+	expiry, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return false
 	}
+	return time.Now().Add(leeway).After(expiry)
+}
 
-	return io.ReadAll(resp.Body)
+// Interactive reports whether the grant needs the user in the loop, so it
+// cannot be re-run silently when a refresh fails.
+func (c Config) Interactive() bool {
+	return c.GrantType != GrantClientCredentials && c.GrantType != GrantPassword
 }
@@ -0,0 +1,9 @@
+package oauth
+
+import "embed"
+
+// Sources are the files that implement the login flows. They only use the
+// standard library, so generated CLIs ship a copy to log in the same way.
+//
+//go:embed structs.go pkce.go buildUrl.go callbackServer.go deviceCode.go discovery.go tokenRequest.go refreshToken.go exchangeCodeForToken.go flowErrors.go performOauthFlow.go
+var Sources embed.FS
@@ -1,11 +1,88 @@
 package oauth
 
-type ExchangeCodeForTokenParams struct {
-	tokenEndpoint string
-	clientID      string
-	redirectURI   string
-	code          string
-	codeVerifier  string
+import (
+	"errors"
+	"time"
+)
+
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+	GrantPassword          = "password"
+	GrantDeviceCode        = "device_code"
+
+	grantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+	ClientAuthBasic = "basic"
+	ClientAuthBody  = "body"
+
+	DefaultFlowTimeout = 5 * time.Minute
+)
+
+var (
+	ErrFlowCanceled = errors.New("login was cancelled")
+	ErrFlowTimeout  = errors.New("timed out waiting for the login to complete")
+)
+
+// Config mirrors the OAuth2 settings stored in an environment's
+// oauth2_config, so it can be decoded from that JSON directly.
+type Config struct {
+	GrantType     string `json:"grantType"`
+	Issuer        string `json:"issuer"`
+	ClientID      string `json:"clientId"`
+	ClientSecret  string `json:"clientSecret"`
+	ClientAuth    string `json:"clientAuth"`
+	AuthURL       string `json:"authUrl"`
+	TokenURL      string `json:"accessTokenUrl"`
+	DeviceAuthURL string `json:"deviceAuthUrl"`
+	IntrospectURL string `json:"introspectionUrl"`
+	RevokeURL     string `json:"revocationUrl"`
+	RedirectURI   string `json:"callbackUrl"`
+	Scope         string `json:"scope"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	HeaderPrefix  string `json:"headerPrefix"`
+	AutoRefresh   *bool  `json:"autoRefreshToken"`
+	FlowTimeout   int    `json:"flowTimeout"` // seconds to wait for the user to finish logging in
+}
+
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	Scope        string `json:"scope"`
+	IDToken      string `json:"id_token"`
+	Nonce        string `json:"-"` // nonce sent with the authorization request, for id_token validation
+}
+
+// DeviceAuthorization is the RFC 8628 device authorization response the
+// user needs in order to approve the login on another device.
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type tokenError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// AuthorizationError is an error redirect from the authorization server,
+// such as the user denying consent (RFC 6749 section 4.1.2.1).
+type AuthorizationError struct {
+	Code        string
+	Description string
+	URI         string
+}
+
+type callbackResult struct {
+	code string
+	err  error
 }
 
 type AuthUrlParams struct {
@@ -14,5 +91,6 @@ type AuthUrlParams struct {
 	redirectURI   string
 	scope         string
 	state         string
+	nonce         string
 	codeChallenge string
 }
@@ -0,0 +1,77 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// postForm sends form to endpoint with the client authenticated as cfg
+// asks: HTTP Basic (RFC 6749 section 2.3.1) or client_id/client_secret in
+// the body. Public clients without a secret always send client_id in the
+// body. OAuth error responses are returned as errors.
+func postForm(ctx context.Context, cfg Config, endpoint string, form url.Values) ([]byte, error) {
+	if cfg.ClientSecret != "" && cfg.ClientAuth != ClientAuthBody {
+		form.Del("client_id")
+	} else {
+		form.Set("client_id", cfg.ClientID)
+		if cfg.ClientSecret != "" {
+			form.Set("client_secret", cfg.ClientSecret)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if cfg.ClientSecret != "" && cfg.ClientAuth != ClientAuthBody {
+		req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr tokenError
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Code != "" {
+			return body, &oauthErr
+		}
+		return body, fmt.Errorf("%s: %s %s", endpoint, resp.Status, string(body))
+	}
+	return body, nil
+}
+
+func requestToken(ctx context.Context, cfg Config, form url.Values) (Token, error) {
+	body, err := postForm(ctx, cfg, cfg.TokenURL, form)
+	if err != nil {
+		return Token{}, err
+	}
+	var token Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return Token{}, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return Token{}, fmt.Errorf("token response did not include an access_token")
+	}
+	return token, nil
+}
+
+func (e *tokenError) Error() string {
+	if e.Description != "" {
+		return e.Code + ": " + e.Description
+	}
+	return e.Code
+}
@@ -0,0 +1,24 @@
+package secrets
+
+import "errors"
+
+const (
+	ModeKeyFile    = "keyfile"
+	ModePassphrase = "passphrase"
+)
+
+var (
+	ErrLocked          = errors.New("vault is locked")
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+)
+
+type VaultStatus struct {
+	Mode   string `json:"mode"`
+	Locked bool   `json:"locked"`
+}
+
+type VaultConfig struct {
+	Mode  string
+	Salt  []byte
+	Check string
+}
@@ -0,0 +1,53 @@
+package secrets
+
+import "sync"
+
+// Vault holds the key used to seal secret environment fields. It starts
+// locked and only seals or opens values once a cipher has been supplied.
+type Vault struct {
+	mu     sync.RWMutex
+	cipher *Cipher
+}
+
+func NewVault() *Vault {
+	return &Vault{}
+}
+
+func (v *Vault) Unlock(c *Cipher) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.cipher = c
+}
+
+func (v *Vault) Lock() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.cipher = nil
+}
+
+func (v *Vault) Locked() bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.cipher == nil
+}
+
+func (v *Vault) Seal(plaintext string) (string, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if v.cipher == nil {
+		return "", ErrLocked
+	}
+	return v.cipher.Encrypt(plaintext)
+}
+
+func (v *Vault) Open(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if v.cipher == nil {
+		return "", ErrLocked
+	}
+	return v.cipher.Decrypt(value)
+}
@@ -0,0 +1,62 @@
+package secrets
+
+import "fmt"
+
+// checkPlaintext is sealed into VaultConfig.Check so a wrong passphrase is
+// detected on unlock instead of when the first secret fails to decrypt.
+const checkPlaintext = "commandpost-vault"
+
+func NewVaultConfig(mode string, keyFile string, passphrase string) (VaultConfig, *Cipher, error) {
+	cfg := VaultConfig{Mode: mode}
+	if mode == ModePassphrase {
+		salt, err := NewSalt()
+		if err != nil {
+			return VaultConfig{}, nil, err
+		}
+		cfg.Salt = salt
+	}
+
+	c, err := cipherFor(cfg, keyFile, passphrase)
+	if err != nil {
+		return VaultConfig{}, nil, err
+	}
+	cfg.Check, err = c.Encrypt(checkPlaintext)
+	if err != nil {
+		return VaultConfig{}, nil, err
+	}
+	return cfg, c, nil
+}
+
+func (v *Vault) UnlockWith(cfg VaultConfig, keyFile string, passphrase string) error {
+	c, err := cipherFor(cfg, keyFile, passphrase)
+	if err != nil {
+		return err
+	}
+	check, err := c.Decrypt(cfg.Check)
+	if err != nil || check != checkPlaintext {
+		if cfg.Mode == ModePassphrase {
+			return ErrWrongPassphrase
+		}
+		return fmt.Errorf("key file %s does not match this database", keyFile)
+	}
+	v.Unlock(c)
+	return nil
+}
+
+func cipherFor(cfg VaultConfig, keyFile string, passphrase string) (*Cipher, error) {
+	switch cfg.Mode {
+	case ModePassphrase:
+		if passphrase == "" {
+			return nil, fmt.Errorf("passphrase is required")
+		}
+		return NewCipher(DeriveKey(passphrase, cfg.Salt))
+	case ModeKeyFile:
+		key, err := LoadOrCreateKeyFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewCipher(key)
+	default:
+		return nil, fmt.Errorf("unknown vault mode %q", cfg.Mode)
+	}
+}
@@ -0,0 +1,158 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCipherRoundTrip(t *testing.T) {
+	c, err := NewCipher(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewCipher(bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, plaintext := range []string{"token", "ünïcødé ✓", strings.Repeat("x", 4096), "enc:v1 without colon"} {
+		t.Run(plaintext[:min(len(plaintext), 16)], func(t *testing.T) {
+			sealed, err := c.Encrypt(plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !IsEncrypted(sealed) || strings.Contains(sealed, plaintext) {
+				t.Fatalf("Encrypt() = %q, not sealed", sealed)
+			}
+			again, _ := c.Encrypt(plaintext)
+			if again == sealed {
+				t.Error("Encrypt() reused a nonce")
+			}
+			if got, err := c.Decrypt(sealed); err != nil || got != plaintext {
+				t.Errorf("Decrypt() = %q, %v, want %q", got, err, plaintext)
+			}
+			if _, err := other.Decrypt(sealed); err == nil {
+				t.Error("Decrypt() with another key succeeded")
+			}
+		})
+	}
+
+	passthrough := []struct{ name, value string }{
+		{"empty", ""},
+		{"plaintext row", "legacy-token"},
+	}
+	for _, tt := range passthrough {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := c.Decrypt(tt.value); err != nil || got != tt.value {
+				t.Errorf("Decrypt(%q) = %q, %v", tt.value, got, err)
+			}
+		})
+	}
+	if got, _ := c.Encrypt(""); got != "" {
+		t.Errorf("Encrypt(\"\") = %q, want empty", got)
+	}
+	sealed, _ := c.Encrypt("x")
+	if got, _ := c.Encrypt(sealed); got != sealed {
+		t.Error("Encrypt() sealed a sealed value twice")
+	}
+	for _, bad := range []string{encPrefix + "!!!", encPrefix + "AAAA"} {
+		if _, err := c.Decrypt(bad); err == nil {
+			t.Errorf("Decrypt(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestVaultLocked(t *testing.T) {
+	c, err := NewCipher(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVault()
+	if !v.Locked() {
+		t.Fatal("new vault is unlocked")
+	}
+	if _, err := v.Seal("x"); !errors.Is(err, ErrLocked) {
+		t.Errorf("Seal() on a locked vault = %v, want ErrLocked", err)
+	}
+
+	v.Unlock(c)
+	sealed, err := v.Seal("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Lock()
+	if _, err := v.Open(sealed); !errors.Is(err, ErrLocked) {
+		t.Errorf("Open() on a locked vault = %v, want ErrLocked", err)
+	}
+	if got, err := v.Open("plain"); err != nil || got != "plain" {
+		t.Errorf("Open(plaintext) on a locked vault = %q, %v", got, err)
+	}
+	v.Unlock(c)
+	if got, err := v.Open(sealed); err != nil || got != "secret" {
+		t.Errorf("Open() = %q, %v, want secret", got, err)
+	}
+}
+
+func TestUnlockWith(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "a.key")
+
+	passCfg, passCipher, err := NewVaultConfig(ModePassphrase, "", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyCfg, keyCipher, err := NewVaultConfig(ModeKeyFile, keyFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	passSealed, _ := passCipher.Encrypt("from passphrase")
+	keySealed, _ := keyCipher.Encrypt("from key file")
+
+	tests := []struct {
+		name       string
+		cfg        VaultConfig
+		keyFile    string
+		passphrase string
+		sealed     string
+		want       string
+		err        error
+		errText    string
+	}{
+		{name: "passphrase", cfg: passCfg, passphrase: "correct horse", sealed: passSealed, want: "from passphrase"},
+		{name: "wrong passphrase", cfg: passCfg, passphrase: "battery staple", err: ErrWrongPassphrase},
+		{name: "missing passphrase", cfg: passCfg, errText: "passphrase is required"},
+		{name: "key file", cfg: keyCfg, keyFile: keyFile, sealed: keySealed, want: "from key file"},
+		{name: "other key file", cfg: keyCfg, keyFile: filepath.Join(dir, "b.key"), errText: "does not match"},
+		{name: "unknown mode", cfg: VaultConfig{Mode: "rot13"}, errText: "unknown vault mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVault()
+			err := v.UnlockWith(tt.cfg, tt.keyFile, tt.passphrase)
+			switch {
+			case tt.err != nil:
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("UnlockWith() = %v, want %v", err, tt.err)
+				}
+			case tt.errText != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Fatalf("UnlockWith() = %v, want %q", err, tt.errText)
+				}
+			case err != nil:
+				t.Fatal(err)
+			}
+			if err != nil {
+				if !v.Locked() {
+					t.Error("vault unlocked after a failed UnlockWith()")
+				}
+				return
+			}
+			if got, err := v.Open(tt.sealed); err != nil || got != tt.want {
+				t.Errorf("Open() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
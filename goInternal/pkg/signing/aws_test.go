@@ -0,0 +1,100 @@
+package signing
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAWSSignerTestSuite checks requests from the AWS Signature Version 4
+// test suite, signed with its example credentials.
+func TestAWSSignerTestSuite(t *testing.T) {
+	tests := []struct {
+		name, method, url, body string
+		headers                 map[string]string
+		signedHeaders           string
+		signature               string
+	}{
+		{
+			name: "get-vanilla", method: "GET", url: "https://example.amazonaws.com/",
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name: "get-vanilla-empty-query-key", method: "GET", url: "https://example.amazonaws.com/?Param1=value1",
+			signedHeaders: "host;x-amz-date",
+			signature:     "a67d582fa61cc504c4bae71f336f98b97f1ea3c7a6bfe1b6e45aec72011b9aeb",
+		},
+		{
+			name: "get-vanilla-query-order-key-case", method: "GET", url: "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			signedHeaders: "host;x-amz-date",
+			signature:     "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name: "post-vanilla", method: "POST", url: "https://example.amazonaws.com/",
+			signedHeaders: "host;x-amz-date",
+			signature:     "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name: "post-x-www-form-urlencoded", method: "POST", url: "https://example.amazonaws.com/",
+			headers:       map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:          "Param1=value1",
+			signedHeaders: "content-type;host;x-amz-date",
+			signature:     "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			s := &awsSigner{
+				cfg: Config{
+					Type:      TypeAWS,
+					AccessKey: "AKIDEXAMPLE",
+					SecretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+					Region:    "us-east-1",
+					Service:   "service",
+				},
+				now: func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) },
+			}
+			if err := s.Sign(req, []byte(tt.body)); err != nil {
+				t.Fatal(err)
+			}
+			want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=" + tt.signedHeaders + ", Signature=" + tt.signature
+			if got := req.Header.Get("Authorization"); got != want {
+				t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+// The test suite encodes paths once, as S3 does; every other service
+// expects the escaped path to be encoded again.
+func TestAWSCanonicalPath(t *testing.T) {
+	tests := []struct {
+		path, service, want string
+	}{
+		{path: "", service: "service", want: "/"},
+		{path: "/ሴ", service: "s3", want: "/%E1%88%B4"},
+		{path: "/ሴ", service: "service", want: "/%25E1%2588%25B4"},
+		{path: "/example space/", service: "s3", want: "/example%20space/"},
+		{path: "/example space/", service: "service", want: "/example%2520space/"},
+		{path: "/a-b_c.d~e", service: "service", want: "/a-b_c.d~e"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.service+tt.path, func(t *testing.T) {
+			u := &url.URL{Path: tt.path}
+			if got := awsCanonicalPath(u, tt.service); got != tt.want {
+				t.Errorf("awsCanonicalPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
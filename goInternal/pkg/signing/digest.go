@@ -0,0 +1,148 @@
+package signing
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// digestSigner implements HTTP Digest access authentication (RFC 7616).
+// The first request goes out unauthenticated; the server's 401 challenge
+// supplies the realm and nonce used to sign the retry.
+type digestSigner struct {
+	cfg Config
+
+	mu        sync.Mutex
+	challenge map[string]string
+	nc        int
+}
+
+func (s *digestSigner) Sign(req *http.Request, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.challenge == nil {
+		return nil
+	}
+
+	algorithm := s.challenge["algorithm"]
+	if algorithm == "" {
+		algorithm = "MD5"
+	}
+	sess := strings.HasSuffix(strings.ToLower(algorithm), "-sess")
+	h, err := hashFunc(strings.TrimSuffix(strings.ToLower(algorithm), "-sess"))
+	if err != nil {
+		return err
+	}
+	hexSum := func(s string) string { return hex.EncodeToString(sum(h, []byte(s))) }
+
+	realm, nonce := s.challenge["realm"], s.challenge["nonce"]
+	cnonce, err := randomNonce()
+	if err != nil {
+		return err
+	}
+	s.nc++
+	nc := fmt.Sprintf("%08x", s.nc)
+	uri := req.URL.RequestURI()
+
+	ha1 := hexSum(s.cfg.Username + ":" + realm + ":" + s.cfg.Password)
+	if sess {
+		ha1 = hexSum(ha1 + ":" + nonce + ":" + cnonce)
+	}
+
+	qop := ""
+	for _, q := range strings.Split(s.challenge["qop"], ",") {
+		q = strings.TrimSpace(q)
+		if q == "auth" || (q == "auth-int" && qop == "") {
+			qop = q
+		}
+	}
+	ha2 := hexSum(req.Method + ":" + uri)
+	if qop == "auth-int" {
+		ha2 = hexSum(req.Method + ":" + uri + ":" + hex.EncodeToString(sum(h, body)))
+	}
+
+	var response string
+	if qop == "" {
+		response = hexSum(ha1 + ":" + nonce + ":" + ha2)
+	} else {
+		response = hexSum(ha1 + ":" + nonce + ":" + nc + ":" + cnonce + ":" + qop + ":" + ha2)
+	}
+
+	parts := []string{
+		fmt.Sprintf(`username=%q`, s.cfg.Username),
+		fmt.Sprintf(`realm=%q`, realm),
+		fmt.Sprintf(`nonce=%q`, nonce),
+		fmt.Sprintf(`uri=%q`, uri),
+		"algorithm=" + algorithm,
+		fmt.Sprintf(`response=%q`, response),
+	}
+	if qop != "" {
+		parts = append(parts, "qop="+qop, "nc="+nc, fmt.Sprintf(`cnonce=%q`, cnonce))
+	}
+	if opaque, ok := s.challenge["opaque"]; ok {
+		parts = append(parts, fmt.Sprintf(`opaque=%q`, opaque))
+	}
+	req.Header.Set("Authorization", "Digest "+strings.Join(parts, ", "))
+	return nil
+}
+
+// Challenge takes the nonce from a 401 response. A request that was
+// already signed is only retried when the server reports the nonce as
+// stale, so wrong credentials do not loop.
+func (s *digestSigner) Challenge(resp *http.Response) (bool, error) {
+	if resp.StatusCode != http.StatusUnauthorized {
+		return false, nil
+	}
+	for _, header := range resp.Header.Values("WWW-Authenticate") {
+		if len(header) < 7 || !strings.EqualFold(header[:7], "digest ") {
+			continue
+		}
+		params := parseAuthParams(header[7:])
+		s.mu.Lock()
+		retry := s.challenge == nil || strings.EqualFold(params["stale"], "true")
+		s.challenge = params
+		s.nc = 0
+		s.mu.Unlock()
+		return retry, nil
+	}
+	return false, nil
+}
+
+// parseAuthParams splits a comma separated list of key=value or
+// key="quoted value" pairs.
+func parseAuthParams(s string) map[string]string {
+	params := map[string]string{}
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = s[eq+1:]
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			value = b.String()
+			s = s[min(i+1, len(s)):]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+		params[key] = value
+	}
+	return params
+}
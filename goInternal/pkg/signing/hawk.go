@@ -0,0 +1,66 @@
+package signing
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hawkSigner adds a Hawk Authorization header, including a payload hash
+// whenever the request has a body.
+type hawkSigner struct {
+	cfg Config
+}
+
+func (s *hawkSigner) Sign(req *http.Request, body []byte) error {
+	h, err := hashFunc(s.cfg.Algorithm)
+	if err != nil {
+		return err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := randomNonce()
+	if err != nil {
+		return err
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname = host
+		port = "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	hash := ""
+	if len(body) > 0 {
+		contentType := strings.ToLower(strings.TrimSpace(strings.Split(req.Header.Get("Content-Type"), ";")[0]))
+		payload := "hawk.1.payload\n" + contentType + "\n" + string(body) + "\n"
+		hash = base64.StdEncoding.EncodeToString(sum(h, []byte(payload)))
+	}
+
+	normalized := strings.Join([]string{
+		"hawk.1.header", ts, nonce, strings.ToUpper(req.Method), req.URL.RequestURI(),
+		strings.ToLower(hostname), port, hash, s.cfg.Ext,
+	}, "\n") + "\n"
+	mac := base64.StdEncoding.EncodeToString(hmacSum(h, []byte(s.cfg.HawkKey), normalized))
+
+	header := fmt.Sprintf(`Hawk id=%q, ts="%s", nonce="%s"`, s.cfg.HawkID, ts, nonce)
+	if hash != "" {
+		header += fmt.Sprintf(`, hash="%s"`, hash)
+	}
+	if s.cfg.Ext != "" {
+		header += fmt.Sprintf(`, ext=%q`, s.cfg.Ext)
+	}
+	header += fmt.Sprintf(`, mac="%s"`, mac)
+	req.Header.Set("Authorization", header)
+	return nil
+}
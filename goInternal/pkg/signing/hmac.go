@@ -0,0 +1,98 @@
+package signing
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hmacSigner signs a canonical string built from StringToSign, in which
+// these placeholders are replaced:
+//
+//	{method} {path} {query} {host} {timestamp} {nonce} {body_sha256} {header:Name}
+//
+// and "\n" stands for a newline. The signature is written to
+// SignatureHeader (default Authorization) using SignatureFormat, where
+// {key_id}, {signature} and {timestamp} are available.
+type hmacSigner struct {
+	cfg Config
+}
+
+func (s *hmacSigner) Sign(req *http.Request, body []byte) error {
+	h, err := hashFunc(s.cfg.Algorithm)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := randomNonce()
+	if err != nil {
+		return err
+	}
+	if s.cfg.TimestampHeader != "" {
+		req.Header.Set(s.cfg.TimestampHeader, timestamp)
+	}
+	if s.cfg.NonceHeader != "" {
+		req.Header.Set(s.cfg.NonceHeader, nonce)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	replacements := []string{
+		"{method}", req.Method,
+		"{path}", req.URL.EscapedPath(),
+		"{query}", req.URL.RawQuery,
+		"{host}", host,
+		"{timestamp}", timestamp,
+		"{nonce}", nonce,
+		"{body_sha256}", hex.EncodeToString(sum(sha256.New, body)),
+		`\n`, "\n",
+	}
+	canonical := expandHeaders(strings.NewReplacer(replacements...).Replace(s.cfg.StringToSign), req.Header)
+
+	mac := hmacSum(h, []byte(s.cfg.Secret), canonical)
+	signature := base64.StdEncoding.EncodeToString(mac)
+	if s.cfg.Encoding == "hex" {
+		signature = hex.EncodeToString(mac)
+	}
+
+	header := s.cfg.SignatureHeader
+	if header == "" {
+		header = "Authorization"
+	}
+	format := s.cfg.SignatureFormat
+	if format == "" {
+		format = "{signature}"
+	}
+	req.Header.Set(header, strings.NewReplacer(
+		"{key_id}", s.cfg.KeyID,
+		"{signature}", signature,
+		"{timestamp}", timestamp,
+	).Replace(format))
+	return nil
+}
+
+// expandHeaders replaces each {header:Name} with that request header.
+func expandHeaders(s string, headers http.Header) string {
+	var out strings.Builder
+	for {
+		i := strings.Index(s, "{header:")
+		if i < 0 {
+			out.WriteString(s)
+			return out.String()
+		}
+		j := strings.Index(s[i:], "}")
+		if j < 0 {
+			out.WriteString(s)
+			return out.String()
+		}
+		out.WriteString(s[:i])
+		out.WriteString(headers.Get(s[i+len("{header:") : i+j]))
+		s = s[i+j+1:]
+	}
+}
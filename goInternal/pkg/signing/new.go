@@ -0,0 +1,31 @@
+package signing
+
+import "fmt"
+
+// New returns the Signer for cfg, or nil when the request is not signed.
+func New(cfg *Config) (Signer, error) {
+	if cfg == nil || cfg.Type == "" {
+		return nil, nil
+	}
+	switch cfg.Type {
+	case TypeAWS:
+		if cfg.AccessKey == "" || cfg.SecretKey == "" || cfg.Region == "" || cfg.Service == "" {
+			return nil, fmt.Errorf("AWS signing needs an access key, secret key, region and service")
+		}
+		return &awsSigner{cfg: *cfg}, nil
+	case TypeHMAC:
+		if cfg.Secret == "" || cfg.StringToSign == "" {
+			return nil, fmt.Errorf("HMAC signing needs a secret and a string to sign")
+		}
+		return &hmacSigner{cfg: *cfg}, nil
+	case TypeDigest:
+		return &digestSigner{cfg: *cfg}, nil
+	case TypeHawk:
+		if cfg.HawkID == "" || cfg.HawkKey == "" {
+			return nil, fmt.Errorf("Hawk signing needs an id and a key")
+		}
+		return &hawkSigner{cfg: *cfg}, nil
+	default:
+		return nil, fmt.Errorf("unknown signing type %q", cfg.Type)
+	}
+}
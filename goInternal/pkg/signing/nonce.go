@@ -0,0 +1,14 @@
+package signing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+func randomNonce() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
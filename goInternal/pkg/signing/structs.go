@@ -0,0 +1,87 @@
+package signing
+
+import (
+	"net/http"
+	"regexp"
+)
+
+const (
+	TypeAWS    = "aws_sigv4"
+	TypeHMAC   = "hmac"
+	TypeDigest = "digest"
+	TypeHawk   = "hawk"
+)
+
+var variableRef = regexp.MustCompile(`^\{\{\s*[^{}\s]+\s*\}\}$`)
+
+// Config selects and configures the signature added to a request. Only the
+// fields of the chosen Type are used.
+type Config struct {
+	Type string `json:"type"`
+
+	// AWS Signature Version 4
+	AccessKey    string `json:"accessKey,omitempty"`
+	SecretKey    string `json:"secretKey,omitempty"`
+	SessionToken string `json:"sessionToken,omitempty"`
+	Region       string `json:"region,omitempty"`
+	Service      string `json:"service,omitempty"`
+
+	// Generic HMAC, with placeholders described in hmac.go
+	KeyID           string `json:"keyId,omitempty"`
+	Secret          string `json:"secret,omitempty"`
+	Algorithm       string `json:"algorithm,omitempty"` // sha1, sha256 or sha512 (HMAC and Hawk)
+	StringToSign    string `json:"stringToSign,omitempty"`
+	SignatureHeader string `json:"signatureHeader,omitempty"`
+	SignatureFormat string `json:"signatureFormat,omitempty"`
+	Encoding        string `json:"encoding,omitempty"` // base64 or hex
+	TimestampHeader string `json:"timestampHeader,omitempty"`
+	NonceHeader     string `json:"nonceHeader,omitempty"`
+
+	// HTTP Digest
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// Hawk
+	HawkID  string `json:"hawkId,omitempty"`
+	HawkKey string `json:"hawkKey,omitempty"`
+	Ext     string `json:"ext,omitempty"`
+}
+
+// Signer adds authentication to a request whose URL, headers and body are
+// final. body is the exact payload that will be sent.
+type Signer interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// Challenger is a Signer that can only authenticate after the server has
+// answered with a challenge, such as HTTP Digest. Challenge reports whether
+// the request should be signed again and retried.
+type Challenger interface {
+	Signer
+	Challenge(resp *http.Response) (bool, error)
+}
+
+// Map returns a copy of c with f applied to every credential and template
+// field, which is how {{variables}} are substituted.
+func (c Config) Map(f func(string) string) Config {
+	for _, p := range []*string{
+		&c.AccessKey, &c.SecretKey, &c.SessionToken, &c.Region, &c.Service,
+		&c.KeyID, &c.Secret, &c.StringToSign, &c.SignatureFormat,
+		&c.Username, &c.Password, &c.HawkID, &c.HawkKey, &c.Ext,
+	} {
+		*p = f(*p)
+	}
+	return c
+}
+
+// WithoutSecrets returns a copy of c with the secret credentials cleared
+// unless they are a {{variable}} reference, so that saved requests never
+// hold them in plaintext. Keep secrets in environment secret variables.
+func (c Config) WithoutSecrets() Config {
+	for _, p := range []*string{&c.SecretKey, &c.SessionToken, &c.Secret, &c.Password, &c.HawkKey} {
+		if !variableRef.MatchString(*p) {
+			*p = ""
+		}
+	}
+	return c
+}
@@ -0,0 +1,182 @@
+package spec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// ParseHeaders turns "Name: value" strings into headers for fetching specs.
+func ParseHeaders(lines []string) (http.Header, error) {
+	headers := http.Header{}
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid header %q, expected \"Name: value\"", line)
+		}
+		headers.Add(textproto.CanonicalMIMEHeaderKey(name), strings.TrimSpace(value))
+	}
+	return headers, nil
+}
+
+// read returns a document referenced from the spec. A spec fetched over
+// HTTP may only reference other HTTP documents, never local files.
+func (l *Loader) read(uri *url.URL) ([]byte, error) {
+	switch uri.Scheme {
+	case "http", "https":
+		return l.fetch(uri)
+	case "", "file":
+		if l.origin != "" {
+			return nil, fmt.Errorf("remote spec cannot reference local file %s", uri.Path)
+		}
+		return os.ReadFile(filepath.FromSlash(uri.Path))
+	}
+	return nil, fmt.Errorf("unsupported reference scheme %q", uri.Scheme)
+}
+
+// fetch downloads a document, revalidating any cached copy with its ETag or
+// Last-Modified date. The cached copy is also used when the host cannot be
+// reached.
+func (l *Loader) fetch(u *url.URL) ([]byte, error) {
+	target := *u
+	target.Fragment = ""
+	key := l.cacheKey(&target)
+
+	req, err := http.NewRequest(http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json, application/yaml, text/yaml, */*")
+	if origin(&target) == l.origin {
+		for name, values := range l.Headers {
+			for _, v := range values {
+				req.Header.Add(name, v)
+			}
+		}
+	}
+
+	cached, entry := l.cached(key)
+	if cached != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("failed to fetch %s: %w", target.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return cached, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch %s: %s", target.Redacted(), resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", target.Redacted(), err)
+	}
+	l.store(key, cacheEntry{
+		URL:          target.Redacted(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, data)
+	return data, nil
+}
+
+// checkRedirect drops the headers when a redirect leaves the spec's origin;
+// the client itself only drops Authorization and Cookie headers.
+func (l *Loader) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if origin(req.URL) != l.origin {
+		for name := range l.Headers {
+			req.Header.Del(name)
+		}
+	}
+	return nil
+}
+
+// cacheKey names the cache files of u. The headers sent to the spec's origin
+// are part of the key, so responses fetched with different credentials are
+// kept apart.
+func (l *Loader) cacheKey(u *url.URL) string {
+	if l.CacheDir == "" {
+		return ""
+	}
+	h := sha256.New()
+	io.WriteString(h, u.String())
+	if origin(u) == l.origin && len(l.Headers) > 0 {
+		h.Write([]byte{0})
+		l.Headers.Write(h)
+	}
+	return filepath.Join(l.CacheDir, hex.EncodeToString(h.Sum(nil)))
+}
+
+func (l *Loader) cached(key string) ([]byte, cacheEntry) {
+	var entry cacheEntry
+	if key == "" {
+		return nil, entry
+	}
+	meta, err := os.ReadFile(key + ".json")
+	if err != nil || json.Unmarshal(meta, &entry) != nil {
+		return nil, entry
+	}
+	data, err := os.ReadFile(key + ".body")
+	if err != nil {
+		return nil, entry
+	}
+	return data, entry
+}
+
+// store caches a response. Failing to cache is not an error for the caller.
+func (l *Loader) store(key string, entry cacheEntry, data []byte) {
+	if key == "" || (entry.ETag == "" && entry.LastModified == "") {
+		return
+	}
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(key), 0700); err != nil {
+		return
+	}
+	// Specs fetched with credentials may be private.
+	err = errors.Join(os.WriteFile(key+".body", data, 0600), os.WriteFile(key+".json", meta, 0600))
+	if err != nil {
+		os.Remove(key + ".body")
+		os.Remove(key + ".json")
+	}
+}
+
+func origin(u *url.URL) string {
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
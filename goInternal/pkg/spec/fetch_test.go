@@ -0,0 +1,71 @@
+package spec
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestFetchRedirectHeaders(t *testing.T) {
+	var got http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte(`{}`))
+	}))
+	defer other.Close()
+	var same *httptest.Server
+	same = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cross":
+			http.Redirect(w, r, other.URL+"/spec.json", http.StatusFound)
+		case "/local":
+			http.Redirect(w, r, same.URL+"/spec.json", http.StatusFound)
+		default:
+			got = r.Header.Clone()
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer same.Close()
+
+	tests := []struct {
+		path string
+		sent bool
+	}{
+		{"/spec.json", true},
+		{"/local", true},
+		{"/cross", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			l := &Loader{Headers: http.Header{"X-Api-Key": {"secret"}}}
+			l.client = &http.Client{CheckRedirect: l.checkRedirect}
+			u, _ := url.Parse(same.URL + tt.path)
+			l.origin = origin(u)
+			got = nil
+			if _, err := l.fetch(u); err != nil {
+				t.Fatal(err)
+			}
+			if sent := got.Get("X-Api-Key") != ""; sent != tt.sent {
+				t.Errorf("X-Api-Key sent = %v, want %v", sent, tt.sent)
+			}
+		})
+	}
+}
+
+func TestCacheKeyHeaders(t *testing.T) {
+	u, _ := url.Parse("https://api.example.com/spec.json")
+	key := func(headers http.Header) string {
+		l := &Loader{CacheDir: "cache", Headers: headers, origin: origin(u)}
+		return l.cacheKey(u)
+	}
+	none := key(nil)
+	a := key(http.Header{"Authorization": {"Bearer a"}})
+	b := key(http.Header{"Authorization": {"Bearer b"}})
+	if none == a || a == b {
+		t.Errorf("cache keys should differ by headers: %s, %s, %s", none, a, b)
+	}
+	if a != key(http.Header{"Authorization": {"Bearer a"}}) {
+		t.Error("cache key is not stable")
+	}
+}
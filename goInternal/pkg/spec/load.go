@@ -0,0 +1,167 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/oasdiff/yaml"
+)
+
+type Version string
+
+const (
+	Swagger20 Version = "2.0"
+	OpenAPI30 Version = "3.0"
+	OpenAPI31 Version = "3.1"
+)
+
+// Loader reads specs and the documents they reference. Headers are sent
+// when fetching from the host the spec itself was fetched from, so private
+// specs can be loaded without leaking credentials to other hosts.
+type Loader struct {
+	Headers  http.Header
+	Timeout  time.Duration
+	CacheDir string
+
+	client *http.Client
+	origin string
+}
+
+func NewLoader() *Loader {
+	l := &Loader{Timeout: 30 * time.Second}
+	if dir, err := os.UserCacheDir(); err == nil {
+		l.CacheDir = filepath.Join(dir, "CommandPost", "specs")
+	}
+	return l
+}
+
+// Load reads a Swagger 2.0, OpenAPI 3.0 or OpenAPI 3.1 document from a file
+// path or URL, resolves its external references and returns it as OpenAPI
+// 3.0 with relative server URLs resolved against the spec's location.
+func (l *Loader) Load(path string) (*openapi3.T, error) {
+	l.client = &http.Client{Timeout: l.Timeout, CheckRedirect: l.checkRedirect}
+	l.origin = ""
+
+	var location *url.URL
+	var data []byte
+	var err error
+	if isURL(path) {
+		if location, err = url.Parse(path); err != nil {
+			return nil, fmt.Errorf("invalid URL: %w", err)
+		}
+		l.origin = origin(location)
+		data, err = l.fetch(location)
+	} else {
+		location = &url.URL{Path: filepath.ToSlash(path)}
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec: %w", err)
+	}
+
+	doc, err := l.parse(data, location)
+	if err != nil {
+		return nil, err
+	}
+	resolveServers(doc, location)
+	return doc, nil
+}
+
+func (l *Loader) parse(data []byte, location *url.URL) (*openapi3.T, error) {
+	version, err := DetectVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(_ *openapi3.Loader, uri *url.URL) ([]byte, error) {
+		data, err := l.read(uri)
+		if err != nil || version != OpenAPI31 {
+			return data, err
+		}
+		return downgradeSchemas(data)
+	}
+
+	switch version {
+	case Swagger20:
+		var doc2 openapi2.T
+		if err := unmarshal(data, &doc2); err != nil {
+			return nil, fmt.Errorf("invalid Swagger 2.0 document: %w", err)
+		}
+		// Without consumes/produces the converter falls back to */*, which
+		// is no use as a Content-Type; JSON is what such APIs expect.
+		if len(doc2.Consumes) == 0 {
+			doc2.Consumes = []string{"application/json"}
+		}
+		if len(doc2.Produces) == 0 {
+			doc2.Produces = []string{"application/json"}
+		}
+		doc, err := openapi2conv.ToV3WithLoader(&doc2, loader, location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert Swagger 2.0 document: %w", err)
+		}
+		return doc, nil
+	case OpenAPI31:
+		if data, err = downgrade31(data); err != nil {
+			return nil, fmt.Errorf("invalid OpenAPI 3.1 document: %w", err)
+		}
+	}
+
+	doc, err := loader.LoadFromDataWithPath(data, location)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Paths == nil {
+		doc.Paths = openapi3.NewPaths()
+	}
+	return doc, nil
+}
+
+// DetectVersion reads the swagger or openapi field of a JSON or YAML
+// document.
+func DetectVersion(data []byte) (Version, error) {
+	var header struct {
+		Swagger string `json:"swagger"`
+		OpenAPI string `json:"openapi"`
+	}
+	if err := unmarshal(data, &header); err != nil {
+		return "", fmt.Errorf("spec is neither JSON nor YAML: %w", err)
+	}
+	switch {
+	case header.Swagger == "2.0":
+		return Swagger20, nil
+	case header.OpenAPI == "3" || header.OpenAPI == "3.0" || strings.HasPrefix(header.OpenAPI, "3.0."):
+		return OpenAPI30, nil
+	case header.OpenAPI == "3.1" || strings.HasPrefix(header.OpenAPI, "3.1."):
+		return OpenAPI31, nil
+	case header.Swagger != "":
+		return "", fmt.Errorf("unsupported Swagger version %q", header.Swagger)
+	case header.OpenAPI != "":
+		return "", fmt.Errorf("unsupported OpenAPI version %q", header.OpenAPI)
+	}
+	return "", fmt.Errorf("spec has no swagger or openapi version field")
+}
+
+// unmarshal decodes JSON directly, since the YAML parser rejects some
+// strings that are valid JSON, and anything else as YAML.
+func unmarshal(data []byte, v interface{}) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return json.Unmarshal(data, v)
+	}
+	return yaml.Unmarshal(data, v)
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
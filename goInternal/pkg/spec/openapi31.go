@@ -0,0 +1,193 @@
+package spec
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// downgrade31 rewrites the OpenAPI 3.1 constructs that have a 3.0
+// equivalent so the document loads as 3.0: type arrays and null types
+// become nullable, numeric exclusiveMinimum/Maximum become flags, const
+// becomes a single-value enum and contentEncoding/contentMediaType become
+// formats. Webhooks describe calls the API makes, not operations a client
+// can invoke, and are dropped.
+func downgrade31(data []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	doc["openapi"] = "3.0.3"
+	delete(doc, "webhooks")
+	delete(doc, "jsonSchemaDialect")
+	if _, ok := doc["paths"]; !ok {
+		doc["paths"] = map[string]interface{}{}
+	}
+	if components, ok := doc["components"].(map[string]interface{}); ok {
+		delete(components, "pathItems")
+	}
+	walk(doc, false)
+	return json.Marshal(doc)
+}
+
+// downgradeSchemas rewrites the schemas in a document referenced from a 3.1
+// spec, leaving the rest of it as it is.
+func downgradeSchemas(data []byte) ([]byte, error) {
+	var doc interface{}
+	if err := unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	walk(doc, false)
+	return json.Marshal(doc)
+}
+
+// schemaMaps are keywords whose value maps names to schemas, and
+// schemaLists keywords whose value is a list of schemas.
+var (
+	schemaKeys  = map[string]bool{"schema": true, "items": true, "additionalProperties": true, "not": true, "contains": true, "if": true, "then": true, "else": true}
+	schemaMaps  = map[string]bool{"properties": true, "patternProperties": true, "$defs": true, "dependentSchemas": true}
+	schemaLists = map[string]bool{"allOf": true, "oneOf": true, "anyOf": true, "prefixItems": true}
+)
+
+func walk(node interface{}, isSchema bool) {
+	switch n := node.(type) {
+	case []interface{}:
+		for _, item := range n {
+			walk(item, isSchema)
+		}
+	case map[string]interface{}:
+		if isSchema {
+			downgradeSchema(n)
+		}
+		for key, value := range n {
+			switch {
+			case strings.HasPrefix(key, "x-"), key == "example", key == "examples", key == "default", key == "enum":
+				// literal values, not part of the document structure
+			case (key == "schemas" || key == "definitions") && !isSchema:
+				walkMap(value)
+			case schemaKeys[key], isSchema && schemaLists[key]:
+				walk(value, true)
+			case isSchema && schemaMaps[key]:
+				walkMap(value)
+			default:
+				walk(value, false)
+			}
+		}
+	}
+}
+
+func walkMap(node interface{}) {
+	if m, ok := node.(map[string]interface{}); ok {
+		for _, value := range m {
+			walk(value, true)
+		}
+	}
+}
+
+func downgradeSchema(s map[string]interface{}) {
+	switch t := s["type"].(type) {
+	case []interface{}:
+		var types []interface{}
+		for _, v := range t {
+			if v == "null" {
+				s["nullable"] = true
+			} else {
+				types = append(types, v)
+			}
+		}
+		switch len(types) {
+		case 0:
+			delete(s, "type")
+		case 1:
+			s["type"] = types[0]
+		default:
+			s["type"] = types
+		}
+	case string:
+		if t == "null" {
+			delete(s, "type")
+			s["nullable"] = true
+		}
+	}
+
+	for _, bound := range []string{"Minimum", "Maximum"} {
+		key := "exclusive" + bound
+		if v, ok := s[key].(float64); ok {
+			s[strings.ToLower(bound)] = v
+			s[key] = true
+		}
+	}
+
+	if v, ok := s["const"]; ok {
+		s["enum"] = []interface{}{v}
+		delete(s, "const")
+		if _, typed := s["type"]; !typed {
+			switch v.(type) {
+			case string:
+				s["type"] = "string"
+			case float64:
+				s["type"] = "number"
+			case bool:
+				s["type"] = "boolean"
+			}
+		}
+	}
+	if examples, ok := s["examples"].([]interface{}); ok {
+		if len(examples) > 0 {
+			s["example"] = examples[0]
+		}
+		delete(s, "examples")
+	}
+
+	if _, ok := s["format"]; !ok {
+		if s["contentEncoding"] == "base64" {
+			s["format"] = "byte"
+		} else if _, ok := s["contentMediaType"]; ok && s["type"] == "string" {
+			s["format"] = "binary"
+		}
+	}
+
+	// oneOf/anyOf with a null member is 3.1's nullable; a lone remaining
+	// $ref is wrapped in allOf, the 3.0 way of adding nullable to a ref.
+	for _, key := range []string{"oneOf", "anyOf"} {
+		members, ok := s[key].([]interface{})
+		if !ok {
+			continue
+		}
+		var rest []interface{}
+		for _, m := range members {
+			if isNullSchema(m) {
+				s["nullable"] = true
+			} else {
+				rest = append(rest, m)
+			}
+		}
+		if len(rest) == len(members) {
+			continue
+		}
+		if len(rest) == 1 {
+			delete(s, key)
+			s["allOf"] = rest
+		} else {
+			s[key] = rest
+		}
+	}
+
+	if ref, ok := s["$ref"]; ok && len(s) > 1 {
+		// 3.0 ignores keywords next to $ref, so keep them by moving the
+		// reference into allOf.
+		delete(s, "$ref")
+		s["allOf"] = []interface{}{map[string]interface{}{"$ref": ref}}
+	}
+}
+
+func isNullSchema(node interface{}) bool {
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if m["type"] == "null" {
+		return true
+	}
+	types, ok := m["type"].([]interface{})
+	return ok && len(types) == 1 && types[0] == "null"
+}
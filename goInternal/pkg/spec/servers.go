@@ -0,0 +1,72 @@
+package spec
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var braces = strings.NewReplacer("%7B", "{", "%7D", "}")
+
+// resolveServers makes relative server URLs absolute against the URL the
+// spec was fetched from. A spec without servers is served from "/" of that
+// host. Specs read from files keep relative URLs, as there is no host to
+// resolve them against.
+func resolveServers(doc *openapi3.T, location *url.URL) {
+	if location == nil || location.Host == "" {
+		return
+	}
+	if len(doc.Servers) == 0 {
+		doc.Servers = openapi3.Servers{{URL: "/"}}
+	}
+	resolve := func(servers openapi3.Servers) {
+		for _, s := range servers {
+			if s != nil {
+				s.URL = ResolveServerURL(s.URL, location)
+			}
+		}
+	}
+	resolve(doc.Servers)
+	for _, item := range doc.Paths.Map() {
+		if item == nil {
+			continue
+		}
+		resolve(item.Servers)
+		for _, op := range item.Operations() {
+			if op.Servers != nil {
+				resolve(*op.Servers)
+			}
+		}
+	}
+}
+
+// ResolveServerURL resolves a relative server URL against the spec's
+// location, keeping {variables} intact.
+func ResolveServerURL(server string, location *url.URL) string {
+	if strings.Contains(server, "://") || location == nil || location.Host == "" {
+		return server
+	}
+	ref, err := url.Parse(server)
+	if err != nil {
+		return server
+	}
+	resolved := location.ResolveReference(ref)
+	resolved.User = nil
+	return strings.TrimSuffix(braces.Replace(resolved.String()), "/")
+}
+
+// DefaultServerURL returns the server's URL with each variable replaced by
+// its default value.
+func DefaultServerURL(server *openapi3.Server) string {
+	if server == nil {
+		return ""
+	}
+	u := server.URL
+	for name, v := range server.Variables {
+		if v != nil {
+			u = strings.ReplaceAll(u, "{"+name+"}", v.Default)
+		}
+	}
+	return u
+}
@@ -0,0 +1,84 @@
+package variables
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var placeholder = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// Resolve merges layers ordered from highest to lowest priority and returns
+// the effective value of every variable sorted by name.
+func Resolve(layers ...Layer) []Resolved {
+	seen := make(map[string]bool)
+	resolved := make([]Resolved, 0)
+	for _, layer := range layers {
+		for name, value := range layer.Values {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			secret := contains(layer.Secret, name)
+			resolved = append(resolved, Resolved{
+				Name:   name,
+				Value:  value,
+				Source: layer.Source,
+				Secret: secret,
+				Locked: secret && layer.Locked,
+			})
+		}
+	}
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].Name < resolved[j].Name })
+	return resolved
+}
+
+// Values maps the names of resolved variables to their values. Locked
+// secrets are left out, so their placeholders stay unresolved rather than
+// being sent empty.
+func Values(resolved []Resolved) map[string]string {
+	values := make(map[string]string, len(resolved))
+	for _, r := range resolved {
+		if !r.Locked {
+			values[r.Name] = r.Value
+		}
+	}
+	return values
+}
+
+// LockedIn returns the locked secrets that s refers to.
+func LockedIn(s string, resolved []Resolved) []string {
+	var names []string
+	for _, m := range placeholder.FindAllStringSubmatch(s, -1) {
+		for _, r := range resolved {
+			if r.Name == m[1] && r.Locked {
+				names = append(names, r.Name)
+			}
+		}
+	}
+	return names
+}
+
+// Substitute replaces every {{name}} in s with its value. Unknown names are
+// left as they are so a typo is visible in the sent request.
+func Substitute(s string, values map[string]string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	return placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		name := placeholder.FindStringSubmatch(m)[1]
+		if v, ok := values[name]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package variables
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers []Layer
+		want   []Resolved
+	}{
+		{
+			name: "no layers",
+			want: []Resolved{},
+		},
+		{
+			name: "higher layers win",
+			layers: []Layer{
+				{Source: "request", Values: map[string]string{"id": "7"}},
+				{Source: "environment dev", Values: map[string]string{"host": "dev.local", "id": "1"}},
+				{Source: "global", Values: map[string]string{"host": "example.com", "version": "v1"}},
+			},
+			want: []Resolved{
+				{Name: "host", Value: "dev.local", Source: "environment dev"},
+				{Name: "id", Value: "7", Source: "request"},
+				{Name: "version", Value: "v1", Source: "global"},
+			},
+		},
+		{
+			name: "empty values still shadow",
+			layers: []Layer{
+				{Source: "environment dev", Values: map[string]string{"token": ""}},
+				{Source: "global", Values: map[string]string{"token": "global"}},
+			},
+			want: []Resolved{{Name: "token", Value: "", Source: "environment dev"}},
+		},
+		{
+			name: "secrets follow their layer",
+			layers: []Layer{
+				{Source: "environment dev", Values: map[string]string{"token": "t"}, Secret: []string{"token"}},
+				{Source: "global", Values: map[string]string{"key": "k", "token": "g"}, Secret: []string{"key"}},
+			},
+			want: []Resolved{
+				{Name: "key", Value: "k", Source: "global", Secret: true},
+				{Name: "token", Value: "t", Source: "environment dev", Secret: true},
+			},
+		},
+		{
+			name: "locked layers lock only secrets",
+			layers: []Layer{
+				{Source: "environment dev", Values: map[string]string{"host": "dev.local", "token": ""}, Secret: []string{"token"}, Locked: true},
+				{Source: "global", Values: map[string]string{"token": "g"}},
+			},
+			want: []Resolved{
+				{Name: "host", Value: "dev.local", Source: "environment dev"},
+				{Name: "token", Value: "", Source: "environment dev", Secret: true, Locked: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Resolve(tt.layers...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubstitute(t *testing.T) {
+	resolved := Resolve(
+		Layer{Source: "environment dev", Values: map[string]string{"host": "dev.local", "token": ""}, Secret: []string{"token"}, Locked: true},
+		Layer{Source: "global", Values: map[string]string{"id": "7", "empty": ""}},
+	)
+	values := Values(resolved)
+	tests := []struct {
+		in, want string
+		locked   []string
+	}{
+		{in: "https://{{host}}/items/{{ id }}", want: "https://dev.local/items/7"},
+		{in: "no placeholders", want: "no placeholders"},
+		{in: "{{unknown}} stays", want: "{{unknown}} stays"},
+		{in: "a{{empty}}b", want: "ab"},
+		{in: "{{host}", want: "{{host}"},
+		{in: "Bearer {{token}}", want: "Bearer {{token}}", locked: []string{"token"}},
+		{in: "{{token}}:{{token}}", want: "{{token}}:{{token}}", locked: []string{"token", "token"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Substitute(tt.in, values); got != tt.want {
+				t.Errorf("Substitute() = %q, want %q", got, tt.want)
+			}
+			if got := LockedIn(tt.in, resolved); !reflect.DeepEqual(got, tt.locked) {
+				t.Errorf("LockedIn() = %v, want %v", got, tt.locked)
+			}
+		})
+	}
+}
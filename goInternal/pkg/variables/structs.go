@@ -0,0 +1,17 @@
+package variables
+
+// Layer is one source of variables, e.g. an environment or a collection.
+type Layer struct {
+	Source string
+	Values map[string]string
+	Secret []string
+	Locked bool // the vault is locked, so the secret values are empty
+}
+
+type Resolved struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Secret bool   `json:"secret"`
+	Locked bool   `json:"locked"`
+}
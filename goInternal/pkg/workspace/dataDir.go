@@ -0,0 +1,32 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ResolveDataDir picks the directory holding every workspace: the explicit
+// flag value first, then $COMMANDPOST_DATA_DIR, then the OS user config dir.
+func ResolveDataDir(flagValue string) (string, error) {
+	dir := flagValue
+	if dir == "" {
+		dir = os.Getenv(DataDirEnv)
+	}
+	if dir == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate user config dir: %w", err)
+		}
+		dir = filepath.Join(configDir, appDirName)
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(abs, 0700); err != nil {
+		return "", fmt.Errorf("failed to create data directory: %w", err)
+	}
+	return abs, nil
+}
@@ -0,0 +1,66 @@
+package workspace
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ImportLegacy copies the database kept in legacyDir by versions without
+// workspaces, along with its WAL files and key file, into the default
+// workspace. It only runs while dataDir has no workspaces and returns the
+// database it copied, or "" when there was nothing to do.
+func ImportLegacy(dataDir string, legacyDir string) (string, error) {
+	names, err := List(dataDir)
+	if err != nil || len(names) > 0 {
+		return "", err
+	}
+	legacyDB := filepath.Join(legacyDir, dbFileName)
+	if _, err := os.Stat(legacyDB); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	abs, err := filepath.Abs(legacyDB)
+	if err != nil {
+		return "", err
+	}
+
+	paths, err := Resolve(dataDir, DefaultName)
+	if err != nil {
+		return "", err
+	}
+	copies := map[string]string{
+		legacyDB:                              paths.DB,
+		legacyDB + "-wal":                     paths.DB + "-wal",
+		legacyDB + "-shm":                     paths.DB + "-shm",
+		filepath.Join(legacyDir, keyFileName): paths.KeyFile,
+	}
+	for src, dst := range copies {
+		if err := copyFile(src, dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+			// leave no half-imported workspace behind, so the next run retries
+			os.RemoveAll(paths.Dir)
+			return "", err
+		}
+	}
+	return abs, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
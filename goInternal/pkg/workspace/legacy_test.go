@@ -0,0 +1,55 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportLegacy(t *testing.T) {
+	legacyDir := t.TempDir()
+	dataDir := t.TempDir()
+
+	if got, err := ImportLegacy(dataDir, legacyDir); err != nil || got != "" {
+		t.Fatalf("ImportLegacy() without a legacy database = %q, %v", got, err)
+	}
+
+	for name, data := range map[string]string{dbFileName: "db", dbFileName + "-wal": "wal", keyFileName: "key"} {
+		if err := os.WriteFile(filepath.Join(legacyDir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := ImportLegacy(dataDir, legacyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != filepath.Join(legacyDir, dbFileName) {
+		t.Errorf("ImportLegacy() = %q, want the legacy database", got)
+	}
+	paths, err := Resolve(dataDir, DefaultName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for dst, want := range map[string]string{paths.DB: "db", paths.DB + "-wal": "wal", paths.KeyFile: "key"} {
+		if data, err := os.ReadFile(dst); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v, want %q", dst, data, err, want)
+		}
+	}
+	if _, err := os.Stat(paths.DB + "-shm"); !os.IsNotExist(err) {
+		t.Errorf("missing -shm file was created: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(legacyDir, dbFileName)); err != nil {
+		t.Errorf("legacy database removed: %v", err)
+	}
+
+	// Once a workspace exists the legacy database is left alone.
+	if err := os.WriteFile(paths.DB, []byte("newer"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ImportLegacy(dataDir, legacyDir); err != nil || got != "" {
+		t.Fatalf("second ImportLegacy() = %q, %v", got, err)
+	}
+	if data, _ := os.ReadFile(paths.DB); string(data) != "newer" {
+		t.Errorf("workspace database overwritten with %q", data)
+	}
+}
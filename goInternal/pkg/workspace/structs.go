@@ -0,0 +1,18 @@
+package workspace
+
+const (
+	DefaultName = "default"
+	DataDirEnv  = "COMMANDPOST_DATA_DIR"
+
+	appDirName      = "CommandPost"
+	workspacesDir   = "workspaces"
+	currentFileName = "current-workspace"
+	dbFileName      = "commandpost.db"
+	keyFileName     = "commandpost.key"
+)
+
+type Paths struct {
+	Dir     string
+	DB      string
+	KeyFile string
+}
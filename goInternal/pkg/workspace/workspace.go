@@ -0,0 +1,77 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _.-]*$`)
+
+func ValidateName(name string) error {
+	if !validName.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid workspace name %q: use letters, digits, spaces, '.', '_' or '-'", name)
+	}
+	return nil
+}
+
+func Resolve(dataDir string, name string) (Paths, error) {
+	if err := ValidateName(name); err != nil {
+		return Paths{}, err
+	}
+	dir := filepath.Join(dataDir, workspacesDir, name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return Paths{}, err
+	}
+	return Paths{
+		Dir:     dir,
+		DB:      filepath.Join(dir, dbFileName),
+		KeyFile: filepath.Join(dir, keyFileName),
+	}, nil
+}
+
+func Exists(dataDir string, name string) bool {
+	info, err := os.Stat(filepath.Join(dataDir, workspacesDir, name))
+	return err == nil && info.IsDir()
+}
+
+func List(dataDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dataDir, workspacesDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() && ValidateName(e.Name()) == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadCurrent returns the workspace used last time, falling back to the
+// default one when nothing has been recorded yet.
+func LoadCurrent(dataDir string) string {
+	data, err := os.ReadFile(filepath.Join(dataDir, currentFileName))
+	if err != nil {
+		return DefaultName
+	}
+	name := strings.TrimSpace(string(data))
+	if ValidateName(name) != nil {
+		return DefaultName
+	}
+	return name
+}
+
+func SaveCurrent(dataDir string, name string) error {
+	return os.WriteFile(filepath.Join(dataDir, currentFileName), []byte(name+"\n"), 0600)
+}
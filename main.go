@@ -1,7 +1,10 @@
 package main
 
 import (
+	"CommandPost/goInternal/pkg/workspace"
 	"embed"
+	"flag"
+	"log"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -12,11 +15,26 @@ import (
 var assets embed.FS
 
 func main() {
+	dataDirFlag := flag.String("data-dir", "", "Directory holding CommandPost workspaces (default: OS user config dir, or $"+workspace.DataDirEnv+")")
+	flag.Parse()
+
+	dataDir, err := workspace.ResolveDataDir(*dataDirFlag)
+	if err != nil {
+		println("Error:", err.Error())
+		return
+	}
+	// Versions before workspaces kept their database in the working directory.
+	if legacy, err := workspace.ImportLegacy(dataDir, "."); err != nil {
+		log.Println("Failed to import the existing database:", err)
+	} else if legacy != "" {
+		log.Printf("Copied %s into the %s workspace in %s", legacy, workspace.DefaultName, dataDir)
+	}
+
 	// Create an instance of the app structure
-	app := NewApp()
+	app := NewApp(dataDir)
 
 	// Create application with options
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:  "CommandPost",
 		Width:  1024,
 		Height: 768,
@@ -25,6 +43,7 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
+		OnShutdown:       app.shutdown,
 		Bind: []interface{}{
 			app,
 		},